@@ -2,9 +2,15 @@
 package bokio
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/klowdo/bokio-mcp/bokio/generated/company"
 	"github.com/klowdo/bokio-mcp/bokio/generated/general"
@@ -15,8 +21,17 @@ type AuthClient struct {
 	CompanyClient *company.Client
 	GeneralClient *general.Client
 	token         string
+	tokens        cachingTokenSource
+	oauth         *oauthTokenSource
+	tenants       *TenantRegistry
+	idempotency   IdempotencyStore
+	policy        *Policy
 	baseURL       string
 	readOnly      bool
+	// company restricts tool calls to one company ID, see Restrict
+	company string
+	// defaultCompany is the configured fallback company, see Config.CompanyID
+	defaultCompany string
 }
 
 // Config holds the simple configuration for the auth client
@@ -24,20 +39,119 @@ type Config struct {
 	IntegrationToken string
 	BaseURL          string
 	ReadOnly         bool
+
+	// OAuth2 public integration settings, used when no integration token is set
+	ClientID     string
+	ClientSecret string
+	GrantType    string
+	RedirectURL  string
+	Scope        string
+	TokenFile    string
+
+	// TenantsFile is a JSON registry of companies with per-company tokens and aliases
+	TenantsFile string
+	// CompanyID is the company ID or tenant alias tools use when none is
+	// given or selected and the tenant registry has no default
+	CompanyID string
+	// ToolGroups limits the registered tools to these groups, all when empty
+	ToolGroups []string
+
+	// IdempotencyFile records the resources created per idempotency key,
+	// DefaultIdempotencyFile when empty
+	IdempotencyFile string
+	// PolicyFile holds the tool call policy, see LoadPolicy; everything is
+	// allowed when empty
+	PolicyFile string
+
+	// RequestTimeout bounds each attempt of an API request, 0 means DefaultRequestTimeout
+	RequestTimeout time.Duration
+	// MaxRetries is how often idempotent requests are retried after transport
+	// errors, 429 and 5xx responses; 0 means DefaultMaxRetries, negative disables retries
+	MaxRetries int
+	// RateLimit is the sustained number of API requests per second; 0 means
+	// DefaultRateLimit, negative disables rate limiting
+	RateLimit float64
+	// RateBurst is the number of API requests allowed in a burst, 0 means DefaultRateBurst
+	RateBurst int
+}
+
+// UsesOAuth reports whether the configuration selects an OAuth2 grant
+func (c *Config) UsesOAuth() bool {
+	return c.IntegrationToken == "" && c.ClientID != ""
+}
+
+// UsesClientCredentials reports whether tokens are minted with the client_credentials grant
+func (c *Config) UsesClientCredentials() bool {
+	return c.UsesOAuth() && c.GrantType == string(general.ClientCredentials)
 }
 
 // NewAuthClient creates a new authenticated client using generated clients
 func NewAuthClient(config *Config) (*AuthClient, error) {
-	if config.IntegrationToken == "" {
-		return nil, fmt.Errorf("BOKIO_INTEGRATION_TOKEN is required")
+	if config.IntegrationToken == "" && config.ClientID == "" && config.TenantsFile == "" {
+		return nil, fmt.Errorf("BOKIO_INTEGRATION_TOKEN is required (or BOKIO_CLIENT_ID for OAuth2)")
 	}
 
 	if config.BaseURL == "" {
-		config.BaseURL = "https://api.bokio.se"
+		config.BaseURL = DefaultBaseURL
+	}
+
+	var tenants *TenantRegistry
+	if config.TenantsFile != "" {
+		var err error
+		tenants, err = LoadTenantRegistry(config.TenantsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var policy *Policy
+	if config.PolicyFile != "" {
+		var err error
+		policy, err = LoadPolicy(config.PolicyFile, tenants)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Create authenticated HTTP client
-	httpClient := &authenticatedHTTPClient{token: config.IntegrationToken}
+	httpClient := &authenticatedHTTPClient{token: config.IntegrationToken, tenants: tenants, client: newRetryingClient(config)}
+
+	var tokens cachingTokenSource
+	var oauth *oauthTokenSource
+	switch {
+	case config.UsesClientCredentials():
+		credentials, err := newClientCredentialsTokenSource(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize OAuth2: %w", err)
+		}
+		tokens = credentials
+	case config.UsesOAuth():
+		switch config.GrantType {
+		case "", string(general.AuthorizationCode):
+		default:
+			return nil, fmt.Errorf("unsupported BOKIO_GRANT_TYPE %q (use authorization_code or client_credentials)", config.GrantType)
+		}
+
+		tokenFile := config.TokenFile
+		if tokenFile == "" {
+			tokenFile = DefaultTokenFile()
+		}
+
+		var err error
+		oauth, err = newOAuthTokenSource(config, NewFileTokenStore(tokenFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize OAuth2: %w", err)
+		}
+		tokens = oauth
+	}
+	if tokens != nil {
+		httpClient.source = tokens
+	}
+
+	idempotencyFile := config.IdempotencyFile
+	if idempotencyFile == "" {
+		idempotencyFile = DefaultIdempotencyFile()
+	}
 
 	// Create generated clients with authentication
 	companyClient, err := company.NewClient(config.BaseURL, company.WithHTTPClient(httpClient))
@@ -51,50 +165,218 @@ func NewAuthClient(config *Config) (*AuthClient, error) {
 	}
 
 	return &AuthClient{
-		CompanyClient: companyClient,
-		GeneralClient: generalClient,
-		token:         config.IntegrationToken,
-		baseURL:       config.BaseURL,
-		readOnly:      config.ReadOnly,
+		CompanyClient:  companyClient,
+		GeneralClient:  generalClient,
+		token:          config.IntegrationToken,
+		tokens:         tokens,
+		oauth:          oauth,
+		tenants:        tenants,
+		idempotency:    NewFileIdempotencyStore(idempotencyFile),
+		policy:         policy,
+		baseURL:        config.BaseURL,
+		readOnly:       config.ReadOnly,
+		defaultCompany: config.CompanyID,
 	}, nil
 }
 
-// LoadConfigFromEnv loads configuration from environment variables
+// LoadConfigFromEnv loads configuration from environment variables only,
+// ignoring invalid values. LoadConfig also reads the config file and
+// reports invalid values.
 func LoadConfigFromEnv() *Config {
 	return &Config{
 		IntegrationToken: os.Getenv("BOKIO_INTEGRATION_TOKEN"),
-		BaseURL:          getEnvWithDefault("BOKIO_BASE_URL", "https://api.bokio.se"),
+		BaseURL:          getEnvWithDefault("BOKIO_BASE_URL", DefaultBaseURL),
 		ReadOnly:         os.Getenv("BOKIO_READ_ONLY") == "true",
+		ClientID:         os.Getenv("BOKIO_CLIENT_ID"),
+		ClientSecret:     os.Getenv("BOKIO_CLIENT_SECRET"),
+		GrantType:        os.Getenv("BOKIO_GRANT_TYPE"),
+		RedirectURL:      os.Getenv("BOKIO_REDIRECT_URL"),
+		Scope:            os.Getenv("BOKIO_SCOPE"),
+		TokenFile:        os.Getenv("BOKIO_TOKEN_FILE"),
+		TenantsFile:      os.Getenv("BOKIO_TENANTS_FILE"),
+		CompanyID:        os.Getenv("BOKIO_COMPANY_ID"),
+		ToolGroups:       splitList(os.Getenv("BOKIO_TOOL_GROUPS")),
+		IdempotencyFile:  os.Getenv("BOKIO_IDEMPOTENCY_FILE"),
+		PolicyFile:       os.Getenv("BOKIO_POLICY_FILE"),
+		RequestTimeout:   getEnvDuration("BOKIO_REQUEST_TIMEOUT"),
+		MaxRetries:       getEnvInt("BOKIO_MAX_RETRIES"),
+		RateLimit:        getEnvFloat("BOKIO_RATE_LIMIT"),
+		RateBurst:        getEnvInt("BOKIO_RATE_BURST"),
 	}
 }
 
 // authenticatedHTTPClient adds Bearer token authentication to all requests
 type authenticatedHTTPClient struct {
 	token string
+	// source, when set, supplies rotating tokens instead of the static token
+	source TokenSource
+	// tenants, when set, routes company requests to per-company tokens
+	tenants *TenantRegistry
+	// client sends the requests, http.DefaultClient when nil
+	client httpDoer
+}
+
+// doer returns the client that sends requests
+func (c *authenticatedHTTPClient) doer() httpDoer {
+	if c.client == nil {
+		return http.DefaultClient
+	}
+	return c.client
+}
+
+// staticTokenSource serves a fixed integration token
+type staticTokenSource string
+
+func (s staticTokenSource) Token(context.Context) (string, error) {
+	return string(s), nil
+}
+
+func (s staticTokenSource) Refresh(context.Context) (string, error) {
+	return "", fmt.Errorf("integration tokens cannot be refreshed")
+}
+
+// tokenSourceFor picks the token source for a request: the addressed
+// company's own token if it has one, otherwise the client-wide credentials
+func (c *authenticatedHTTPClient) tokenSourceFor(req *http.Request) TokenSource {
+	if token := c.tenants.tokenForPath(req.URL.Path); token != "" {
+		return staticTokenSource(token)
+	}
+	if c.source != nil {
+		return c.source
+	}
+	return staticTokenSource(c.token)
 }
 
 // Do implements the HttpRequestDoer interface by adding Bearer token authentication
 func (c *authenticatedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	source := c.tokenSourceFor(req)
+
+	token, err := source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Buffer the body so the request can be replayed after a token refresh
+	var body []byte
+	if req.Body != nil && req.GetBody == nil {
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.Body, _ = req.GetBody()
+	}
+
 	// Add Bearer token to all requests
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.doer().Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
 
-	// Use default HTTP client
-	return http.DefaultClient.Do(req)
+	// The token was rejected: refresh once and retry
+	token, refreshErr := source.Refresh(ctx)
+	if refreshErr != nil {
+		return resp, nil
+	}
+
+	retry, err := cloneRequest(ctx, req)
+	if err != nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	retry.Header.Set("Authorization", "Bearer "+token)
+	return c.doer().Do(retry)
+}
+
+// cloneRequest copies a request including a fresh body for replay
+func cloneRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	clone := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
 }
 
 // GetToken returns the current authentication token
 func (ac *AuthClient) GetToken() string {
+	if ac.tokens != nil {
+		if token := ac.tokens.current(); token != nil {
+			return token.AccessToken
+		}
+		return ""
+	}
 	return ac.token
 }
 
+// UsesOAuth reports whether the client authenticates with OAuth2 tokens
+func (ac *AuthClient) UsesOAuth() bool {
+	return ac.tokens != nil
+}
+
+// UsesClientCredentials reports whether tokens are minted with the client_credentials grant
+func (ac *AuthClient) UsesClientCredentials() bool {
+	return ac.tokens != nil && ac.oauth == nil
+}
+
+// CurrentToken returns a copy of the current OAuth2 token, or nil when none is available
+func (ac *AuthClient) CurrentToken() *Token {
+	if ac.tokens == nil {
+		return nil
+	}
+	return ac.tokens.current()
+}
+
+// TokenSource returns the source of rotating OAuth2 tokens, or nil for integration tokens
+func (ac *AuthClient) TokenSource() TokenSource {
+	if ac.tokens == nil {
+		return nil
+	}
+	return ac.tokens
+}
+
+// StartAuthorization begins the OAuth2 authorization code flow. It starts a
+// callback listener on the configured redirect URL and returns the URL the
+// user must visit; the channel reports the outcome of the code exchange.
+func (ac *AuthClient) StartAuthorization(ctx context.Context) (string, <-chan error, error) {
+	if ac.oauth == nil {
+		return "", nil, fmt.Errorf("the authorization code flow is not configured (set BOKIO_CLIENT_ID and BOKIO_CLIENT_SECRET without BOKIO_GRANT_TYPE=client_credentials)")
+	}
+	return ac.oauth.StartAuthorization(ctx, ac.baseURL)
+}
+
 // GetBaseURL returns the base URL for the API
 func (ac *AuthClient) GetBaseURL() string {
 	return ac.baseURL
 }
 
+// Tenants returns the company registry, or nil when no tenants file is configured
+func (ac *AuthClient) Tenants() *TenantRegistry {
+	return ac.tenants
+}
+
+// Idempotency returns the store of idempotency keys used by create operations
+func (ac *AuthClient) Idempotency() IdempotencyStore {
+	return ac.idempotency
+}
+
+// Policy returns the tool call policy, or nil when every call is allowed
+func (ac *AuthClient) Policy() *Policy {
+	return ac.policy
+}
+
 // IsAuthenticated returns true if the client has an authentication token
 func (ac *AuthClient) IsAuthenticated() bool {
-	return ac.token != ""
+	return ac.GetToken() != "" || ac.tenants.hasTokens()
 }
 
 // GetConfig returns the current configuration including read-only mode
@@ -111,6 +393,82 @@ func (ac *AuthClient) IsReadOnly() bool {
 	return ac.readOnly
 }
 
+// Restrict returns a view of the client limited to one company and, when
+// readOnly is set, to reads. company is a company ID or tenant alias; an empty
+// company keeps every company reachable. The view shares credentials, tenants
+// and transport with ac.
+func (ac *AuthClient) Restrict(company string, readOnly bool) (*AuthClient, error) {
+	restricted := *ac
+	restricted.readOnly = ac.readOnly || readOnly
+	if company == "" {
+		return &restricted, nil
+	}
+
+	id, err := resolveCompanyID(company, ac.tenants)
+	if err != nil {
+		return nil, err
+	}
+	restricted.company = id
+	if ac.company != "" && ac.company != restricted.company {
+		return nil, fmt.Errorf("company %s is outside the client's restriction", restricted.company)
+	}
+	return &restricted, nil
+}
+
+// DefaultCompany returns the configured fallback company ID or alias
+func (ac *AuthClient) DefaultCompany() string {
+	return ac.defaultCompany
+}
+
+// RestrictedCompany returns the only company ID the client may act on, or
+// an empty string when it is not restricted
+func (ac *AuthClient) RestrictedCompany() string {
+	return ac.company
+}
+
+// getEnvInt returns an integer environment variable, or 0 when it is unset or invalid
+func getEnvInt(key string) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("Ignoring invalid integer environment variable", "key", key, "value", value)
+		return 0
+	}
+	return parsed
+}
+
+// getEnvFloat returns a numeric environment variable, or 0 when it is unset or invalid
+func getEnvFloat(key string) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		slog.Warn("Ignoring invalid numeric environment variable", "key", key, "value", value)
+		return 0
+	}
+	return parsed
+}
+
+// getEnvDuration returns a duration environment variable such as "30s", or 0
+// when it is unset or invalid
+func getEnvDuration(key string) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("Ignoring invalid duration environment variable", "key", key, "value", value)
+		return 0
+	}
+	return parsed
+}
+
 // getEnvWithDefault returns the value of an environment variable or a default value
 func getEnvWithDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,300 @@
+// Package bas bundles the BAS 2024 chart of accounts (kontoplan) used by
+// Swedish companies, with lookup and keyword based account suggestions
+package bas
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// chartData lists the accounts in common use from BAS 2024 with the normal
+// side, VAT rate and momsdeklaration box of each, plus English and Swedish
+// keywords for suggestions. Columns are separated by semicolons.
+//
+//go:embed bas2024.csv
+var chartData []byte
+
+// Side is the side of an account that its balance normally grows on
+type Side string
+
+// Account sides
+const (
+	Debit  Side = "debit"
+	Credit Side = "credit"
+)
+
+// Account is an account of the chart
+type Account struct {
+	Number int32  `json:"account"`
+	Name   string `json:"name"`
+	// Class is the first digit of the account number, 1–8
+	Class     int    `json:"class"`
+	ClassName string `json:"class_name"`
+	Side      Side   `json:"normal_side"`
+	// VATRate is the VAT percentage booked on or through the account
+	VATRate *int `json:"vat_rate,omitempty"`
+	// VATBox is the momsdeklaration box the account reports to, e.g. "05"
+	VATBox   string   `json:"vat_box,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// classNames are the names of the BAS account classes
+var classNames = map[int]string{
+	1: "Tillgångar",
+	2: "Eget kapital och skulder",
+	3: "Rörelsens inkomster/intäkter",
+	4: "Utgifter/kostnader för varor, material och vissa köpta tjänster",
+	5: "Övriga externa rörelseutgifter/kostnader",
+	6: "Övriga externa rörelseutgifter/kostnader",
+	7: "Utgifter/kostnader för personal, avskrivningar m.m.",
+	8: "Finansiella och andra inkomster/intäkter och utgifter/kostnader",
+}
+
+// ClassName returns the name of an account class, or "" for an unknown class
+func ClassName(class int) string {
+	return classNames[class]
+}
+
+// Chart is a chart of accounts ordered by account number
+type Chart struct {
+	accounts []Account
+	byNumber map[int32]int
+}
+
+// chart parses the embedded chart once
+var chart = sync.OnceValues(func() (*Chart, error) { return Parse(chartData) })
+
+// Load returns the embedded BAS 2024 chart of accounts
+func Load() *Chart {
+	c, err := chart()
+	if err != nil {
+		// The embedded data is covered by tests
+		panic(err)
+	}
+	return c
+}
+
+// Parse reads a chart of accounts in the format of the embedded file
+func Parse(data []byte) (*Chart, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = ';'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("chart of accounts is empty")
+	}
+
+	c := &Chart{byNumber: make(map[int32]int, len(records)-1)}
+	for i, record := range records[1:] {
+		line := i + 2
+		number, err := strconv.ParseInt(record[0], 10, 32)
+		if err != nil || number < 1000 || number > 8999 {
+			return nil, fmt.Errorf("line %d: %q is not a 4-digit BAS account", line, record[0])
+		}
+		if _, ok := c.byNumber[int32(number)]; ok {
+			return nil, fmt.Errorf("line %d: account %d is listed twice", line, number)
+		}
+
+		account := Account{Number: int32(number), Name: record[1], Class: int(number / 1000), Side: Side(record[2]), VATBox: record[4]}
+		account.ClassName = ClassName(account.Class)
+		if account.Side != Debit && account.Side != Credit {
+			return nil, fmt.Errorf("line %d: side must be debit or credit, not %q", line, record[2])
+		}
+		if record[3] != "" {
+			rate, err := strconv.Atoi(record[3])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid VAT rate %q", line, record[3])
+			}
+			account.VATRate = &rate
+		}
+		if record[5] != "" {
+			// Keywords are matched against lowercased queries
+			account.Keywords = strings.Split(strings.ToLower(record[5]), ",")
+		}
+
+		c.byNumber[account.Number] = len(c.accounts)
+		c.accounts = append(c.accounts, account)
+	}
+	sort.Slice(c.accounts, func(i, j int) bool { return c.accounts[i].Number < c.accounts[j].Number })
+	for i, account := range c.accounts {
+		c.byNumber[account.Number] = i
+	}
+	return c, nil
+}
+
+// Accounts returns every account of the chart
+func (c *Chart) Accounts() []Account {
+	return c.accounts
+}
+
+// Account looks up an account by number
+func (c *Chart) Account(number int32) (Account, bool) {
+	i, ok := c.byNumber[number]
+	if !ok {
+		return Account{}, false
+	}
+	return c.accounts[i], true
+}
+
+// Search finds accounts by number or text. A numeric query matches account
+// numbers starting with it, so "19" lists the cash and bank accounts. Other
+// queries match accounts whose name or keywords contain every word of the
+// query; accounts matching on name come first.
+func (c *Chart) Search(query string) []Account {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	if _, err := strconv.Atoi(query); err == nil {
+		var found []Account
+		for _, account := range c.accounts {
+			if strings.HasPrefix(strconv.Itoa(int(account.Number)), query) {
+				found = append(found, account)
+			}
+		}
+		return found
+	}
+
+	words := strings.Fields(strings.ToLower(query))
+	var byName, byKeyword []Account
+	for _, account := range c.accounts {
+		name := strings.ToLower(account.Name)
+		keywords := strings.Join(account.Keywords, " ")
+		nameMatches, allMatch := true, true
+		for _, word := range words {
+			inName := strings.Contains(name, word)
+			nameMatches = nameMatches && inName
+			allMatch = allMatch && (inName || strings.Contains(keywords, word))
+		}
+		switch {
+		case nameMatches:
+			byName = append(byName, account)
+		case allMatch:
+			byKeyword = append(byKeyword, account)
+		}
+	}
+	return append(byName, byKeyword...)
+}
+
+// Suggestion is a candidate account for a transaction description
+type Suggestion struct {
+	Account
+	// Score ranks suggestions; higher is a better match
+	Score int `json:"score"`
+	// Reasons explain why the account matched
+	Reasons []string `json:"reasons"`
+}
+
+// stopWords are left out when matching descriptions
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "av": true, "for": true, "from": true, "för": true,
+	"i": true, "in": true, "med": true, "of": true, "och": true, "on": true, "our": true,
+	"på": true, "the": true, "till": true, "to": true, "with": true,
+}
+
+// Suggest returns up to limit accounts matching a free text description such
+// as "office rent" or "software subscription", best match first. Keywords
+// matching as a phrase weigh most, then words of the account name; an
+// account number in the description is always suggested first.
+func (c *Chart) Suggest(description string, limit int) []Suggestion {
+	words := descriptionWords(description)
+	if len(words) == 0 {
+		return nil
+	}
+	text := " " + strings.Join(words, " ") + " "
+
+	var suggestions []Suggestion
+	for _, account := range c.accounts {
+		s := Suggestion{Account: account}
+		number := strconv.Itoa(int(account.Number))
+		for _, word := range words {
+			if word == number {
+				s.Score += 100
+				s.Reasons = append(s.Reasons, "account number "+number+" is mentioned")
+			}
+		}
+
+		matched := make(map[string]bool)
+		for _, keyword := range account.Keywords {
+			phrase := strings.Join(descriptionWords(keyword), " ")
+			if phrase != "" && strings.Contains(text, " "+phrase+" ") {
+				// Longer phrases are more specific
+				s.Score += 2 + 2*strings.Count(phrase, " ")
+				s.Reasons = append(s.Reasons, fmt.Sprintf("keyword %q", keyword))
+				for _, word := range strings.Fields(phrase) {
+					matched[word] = true
+				}
+			}
+		}
+
+		name := strings.ToLower(account.Name)
+		for _, word := range words {
+			// Swedish compounds such as lokalhyra contain the word
+			if !matched[word] && len([]rune(word)) >= 4 && strings.Contains(name, word) {
+				s.Score++
+				s.Reasons = append(s.Reasons, fmt.Sprintf("name contains %q", word))
+			}
+		}
+
+		if s.Score > 0 {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].Score > suggestions[j].Score })
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// descriptionWords lower cases text and splits it into words, dropping stop
+// words and a plural s so that "subscriptions" matches "subscription"
+func descriptionWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := make([]string, 0, len(fields))
+	for _, word := range fields {
+		if stopWords[word] {
+			continue
+		}
+		if len(word) > 4 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			word = strings.TrimSuffix(word, "s")
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// VATBox returns the momsdeklaration box an account reports to, or "" when it
+// reports to none. Accounts missing from the chart fall back to the box of
+// their range: 2610–2639 output VAT by rate, 2640–2649 input VAT and
+// 3000–3099 domestic sales.
+func (c *Chart) VATBox(number int32) string {
+	if account, ok := c.Account(number); ok {
+		return account.VATBox
+	}
+	switch {
+	case number >= 2610 && number <= 2619:
+		return "10"
+	case number >= 2620 && number <= 2629:
+		return "11"
+	case number >= 2630 && number <= 2639:
+		return "12"
+	case number >= 2640 && number <= 2649:
+		return "48"
+	case number >= 3000 && number <= 3099:
+		return "05"
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package bas
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	chart := Load()
+	require.NotEmpty(t, chart.Accounts())
+
+	account, ok := chart.Account(2611)
+	require.True(t, ok)
+	assert.Equal(t, "Utgående moms på försäljning inom Sverige, 25 %", account.Name)
+	assert.Equal(t, 2, account.Class)
+	assert.Equal(t, "Eget kapital och skulder", account.ClassName)
+	assert.Equal(t, Credit, account.Side)
+	require.NotNil(t, account.VATRate)
+	assert.Equal(t, 25, *account.VATRate)
+	assert.Equal(t, "10", account.VATBox)
+
+	_, ok = chart.Account(1234)
+	assert.False(t, ok)
+
+	for _, account := range chart.Accounts() {
+		assert.NotEmpty(t, account.ClassName, "account %d", account.Number)
+		if account.Class >= 4 && account.Class <= 7 && account.Side == Credit {
+			assert.Contains(t, []int32{4730}, account.Number, "cost accounts are normally debit")
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	const header = "account;name;side;vat_rate;vat_box;keywords\n"
+	tests := map[string]string{
+		"not a BAS account": "999;Kassa;debit;;;\n",
+		"duplicate":         "1910;Kassa;debit;;;\n1910;Kassa;debit;;;\n",
+		"unknown side":      "1910;Kassa;left;;;\n",
+		"invalid VAT rate":  "2611;Utgående moms;credit;high;10;\n",
+		"missing column":    "1910;Kassa;debit\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(header + data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	chart := Load()
+
+	numbers := func(accounts []Account) []int32 {
+		var result []int32
+		for _, account := range accounts {
+			result = append(result, account.Number)
+		}
+		return result
+	}
+
+	assert.Equal(t, []int32{1910, 1920, 1930, 1940}, numbers(chart.Search("19")))
+	assert.Equal(t, []int32{5010}, numbers(chart.Search("5010")))
+	assert.Equal(t, []int32{5010}, numbers(chart.Search("lokalhyra")))
+	assert.Contains(t, numbers(chart.Search("Utgående moms 12")), int32(2621))
+
+	found := numbers(chart.Search("bank"))
+	require.Contains(t, found, int32(1930))
+	require.Contains(t, found, int32(6570))
+	assert.Less(t, slices.Index(found, 6570), slices.Index(found, 1930), "name matches come before keyword matches")
+
+	assert.Equal(t, []int32{3740}, numbers(chart.Search("Öresavrundning")))
+	assert.Empty(t, chart.Search(" "))
+
+	// Keywords match regardless of their case in the chart
+	chart, err := Parse([]byte("account;name;side;vat_rate;vat_box;keywords\n3740;Öres- och kronutjämning;credit;;;Rounding,Öresavrundning\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []int32{3740}, numbers(chart.Search("öresavrundning")))
+	assert.Equal(t, []int32{3740}, numbers(chart.Search("rounding")))
+}
+
+func TestSuggest(t *testing.T) {
+	chart := Load()
+
+	tests := []struct {
+		description string
+		want        int32
+	}{
+		{"office rent", 5010},
+		{"Software subscriptions", 5420},
+		{"Flight to Stockholm", 5810},
+		{"Lunch with a client", 6071},
+		{"Bank fees for March", 6570},
+		{"Internet and hosting", 6230},
+		{"Lokalhyra januari", 5010},
+		{"move to account 1940", 1940},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			suggestions := chart.Suggest(tt.description, 5)
+			require.NotEmpty(t, suggestions)
+			assert.Equal(t, tt.want, suggestions[0].Number, "%+v", suggestions)
+			assert.NotEmpty(t, suggestions[0].Reasons)
+		})
+	}
+
+	assert.Len(t, chart.Suggest("software subscription", 2), 2)
+	assert.Empty(t, chart.Suggest("xyzzy", 5))
+	assert.Empty(t, chart.Suggest("the and of", 5))
+}
+
+func TestVATBox(t *testing.T) {
+	chart := Load()
+
+	tests := map[int32]string{
+		2611: "10",
+		2614: "30",
+		2645: "48",
+		3001: "05",
+		3308: "39",
+		4535: "21",
+		3404: "",   // in the chart without a box
+		2617: "10", // not in the chart, falls back to the range
+		2627: "11",
+		3011: "05",
+		5010: "",
+	}
+	for account, want := range tests {
+		assert.Equal(t, want, chart.VATBox(account), "account %d", account)
+	}
+}
@@ -0,0 +1,352 @@
+package bokio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultBaseURL is the Bokio API used when no base URL is configured
+const DefaultBaseURL = "https://api.bokio.se"
+
+// Setting sources, from lowest to highest precedence
+const (
+	SourceDefault = "default"
+	SourceFile    = "config file"
+	SourceEnv     = "environment"
+	SourceFlag    = "command line"
+)
+
+// ConfigOptions selects the configuration file and profile read by LoadConfig
+type ConfigOptions struct {
+	// File is the config file. When empty, BOKIO_CONFIG or, if it exists,
+	// DefaultConfigFile is used.
+	File string
+	// Profile names the profile to use. When empty, BOKIO_PROFILE or the
+	// file's default_profile is used.
+	Profile string
+	// Flags holds settings given on the command line, keyed by setting name
+	// (e.g. "read_only"). They override the environment and the file.
+	Flags map[string]string
+}
+
+// ConfigSetting reports where the effective value of a setting came from
+type ConfigSetting struct {
+	Name string
+	// Value is the effective value, redacted for secrets
+	Value  string
+	Source string
+	// Origin names the variable, flag or profile the value was read from
+	Origin string
+	// Overrides lists the origins of lower precedence values that were ignored
+	Overrides []string
+}
+
+// ConfigReport describes how LoadConfig assembled the configuration
+type ConfigReport struct {
+	// File is the config file read, empty when none was used
+	File string
+	// Profile is the profile used, empty when none was used
+	Profile string
+	// Settings lists every setting that is not at its default, by name
+	Settings []ConfigSetting
+}
+
+// configFile is the on-disk format of the configuration file
+type configFile struct {
+	DefaultProfile string                    `yaml:"default_profile"`
+	Profiles       map[string]map[string]any `yaml:"profiles"`
+}
+
+// configSetting describes a setting that can be given in a profile, in the
+// environment or on the command line
+type configSetting struct {
+	name string
+	env  string
+	// secret settings are never reported by value
+	secret bool
+	apply  func(c *Config, value string) error
+}
+
+// configSettings lists the settings in the order they are reported
+var configSettings = []configSetting{
+	{name: "base_url", env: "BOKIO_BASE_URL", apply: func(c *Config, v string) error { c.BaseURL = v; return nil }},
+	{name: "integration_token", env: "BOKIO_INTEGRATION_TOKEN", secret: true, apply: func(c *Config, v string) error { c.IntegrationToken = v; return nil }},
+	{name: "client_id", env: "BOKIO_CLIENT_ID", apply: func(c *Config, v string) error { c.ClientID = v; return nil }},
+	{name: "client_secret", env: "BOKIO_CLIENT_SECRET", secret: true, apply: func(c *Config, v string) error { c.ClientSecret = v; return nil }},
+	{name: "grant_type", env: "BOKIO_GRANT_TYPE", apply: func(c *Config, v string) error { c.GrantType = v; return nil }},
+	{name: "redirect_url", env: "BOKIO_REDIRECT_URL", apply: func(c *Config, v string) error { c.RedirectURL = v; return nil }},
+	{name: "scope", env: "BOKIO_SCOPE", apply: func(c *Config, v string) error { c.Scope = v; return nil }},
+	{name: "token_file", env: "BOKIO_TOKEN_FILE", apply: func(c *Config, v string) error { c.TokenFile = v; return nil }},
+	{name: "tenants_file", env: "BOKIO_TENANTS_FILE", apply: func(c *Config, v string) error { c.TenantsFile = v; return nil }},
+	{name: "idempotency_file", env: "BOKIO_IDEMPOTENCY_FILE", apply: func(c *Config, v string) error { c.IdempotencyFile = v; return nil }},
+	{name: "policy_file", env: "BOKIO_POLICY_FILE", apply: func(c *Config, v string) error { c.PolicyFile = v; return nil }},
+	{name: "company", env: "BOKIO_COMPANY_ID", apply: func(c *Config, v string) error { c.CompanyID = v; return nil }},
+	{name: "read_only", env: "BOKIO_READ_ONLY", apply: func(c *Config, v string) (err error) {
+		c.ReadOnly, err = strconv.ParseBool(v)
+		return err
+	}},
+	{name: "tool_groups", env: "BOKIO_TOOL_GROUPS", apply: func(c *Config, v string) error { c.ToolGroups = splitList(v); return nil }},
+	{name: "request_timeout", env: "BOKIO_REQUEST_TIMEOUT", apply: func(c *Config, v string) (err error) {
+		c.RequestTimeout, err = time.ParseDuration(v)
+		return err
+	}},
+	{name: "max_retries", env: "BOKIO_MAX_RETRIES", apply: func(c *Config, v string) (err error) {
+		c.MaxRetries, err = strconv.Atoi(v)
+		return err
+	}},
+	{name: "rate_limit", env: "BOKIO_RATE_LIMIT", apply: func(c *Config, v string) (err error) {
+		c.RateLimit, err = strconv.ParseFloat(v, 64)
+		return err
+	}},
+	{name: "rate_burst", env: "BOKIO_RATE_BURST", apply: func(c *Config, v string) (err error) {
+		c.RateBurst, err = strconv.Atoi(v)
+		return err
+	}},
+}
+
+// secretReferences are profile keys naming an environment variable that
+// holds a secret, so profiles never have to contain the secret itself
+var secretReferences = map[string]string{
+	"integration_token_env": "integration_token",
+	"client_secret_env":     "client_secret",
+}
+
+// DefaultConfigFile returns the default location of the configuration file
+func DefaultConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "bokio-mcp", "config.yaml")
+}
+
+// LoadConfig assembles the configuration from, in increasing precedence,
+// defaults, a profile of the config file, environment variables and
+// command line flags. Unknown profile keys, invalid values and unknown
+// profiles are errors.
+func LoadConfig(opts ConfigOptions) (*Config, *ConfigReport, error) {
+	report := &ConfigReport{}
+
+	path, explicit := opts.File, opts.File != ""
+	if path == "" {
+		path = os.Getenv("BOKIO_CONFIG")
+		explicit = path != ""
+	}
+	if path == "" {
+		path = DefaultConfigFile()
+	}
+	file, err := readConfigFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		file, err = nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if file != nil {
+		report.File = path
+	}
+
+	profileName := opts.Profile
+	if profileName == "" {
+		profileName = os.Getenv("BOKIO_PROFILE")
+	}
+	profile, err := file.profile(profileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if profile != nil {
+		if profileName == "" {
+			profileName = file.defaultProfileName()
+		}
+		report.Profile = profileName
+	}
+
+	fileValues, err := profileValues(profileName, profile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config := &Config{BaseURL: DefaultBaseURL}
+	for _, setting := range configSettings {
+		type layer struct{ source, origin, value string }
+		var layers []layer
+		if value, ok := fileValues[setting.name]; ok {
+			layers = append(layers, layer{SourceFile, fmt.Sprintf("profile %s", profileName), value})
+		}
+		if value := os.Getenv(setting.env); value != "" {
+			layers = append(layers, layer{SourceEnv, setting.env, value})
+		}
+		if value, ok := opts.Flags[setting.name]; ok {
+			layers = append(layers, layer{SourceFlag, "--" + strings.ReplaceAll(setting.name, "_", "-"), value})
+		}
+		if len(layers) == 0 {
+			continue
+		}
+
+		effective := layers[len(layers)-1]
+		if err := setting.apply(config, effective.value); err != nil {
+			return nil, nil, fmt.Errorf("invalid %s %q from %s: %w", setting.name, effective.value, effective.origin, err)
+		}
+
+		reported := ConfigSetting{Name: setting.name, Value: effective.value, Source: effective.source, Origin: effective.origin}
+		if setting.secret {
+			reported.Value = "[redacted]"
+		}
+		for _, ignored := range layers[:len(layers)-1] {
+			reported.Overrides = append(reported.Overrides, ignored.origin)
+		}
+		report.Settings = append(report.Settings, reported)
+	}
+
+	return config, report, nil
+}
+
+// readConfigFile parses a YAML configuration file
+func readConfigFile(path string) (*configFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file configFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if file.DefaultProfile != "" {
+		if _, ok := file.Profiles[file.DefaultProfile]; !ok {
+			return nil, fmt.Errorf("config file %s: default_profile %q is not defined", path, file.DefaultProfile)
+		}
+	}
+	return &file, nil
+}
+
+// profile returns the named profile, or the default one when name is empty.
+// A file with a single profile uses it by default.
+func (f *configFile) profile(name string) (map[string]any, error) {
+	if f == nil {
+		if name != "" {
+			return nil, fmt.Errorf("profile %q selected but no config file was found", name)
+		}
+		return nil, nil
+	}
+	if name == "" {
+		name = f.defaultProfileName()
+		if name == "" && len(f.Profiles) > 1 {
+			return nil, fmt.Errorf("config file has several profiles (%s); select one with --profile or BOKIO_PROFILE, or set default_profile",
+				strings.Join(f.profileNames(), ", "))
+		}
+		if name == "" {
+			return nil, nil
+		}
+	}
+
+	profile, ok := f.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(f.profileNames(), ", "))
+	}
+	if profile == nil {
+		profile = map[string]any{}
+	}
+	return profile, nil
+}
+
+// defaultProfileName returns default_profile, or the only profile's name
+func (f *configFile) defaultProfileName() string {
+	if f.DefaultProfile != "" {
+		return f.DefaultProfile
+	}
+	if len(f.Profiles) == 1 {
+		return f.profileNames()[0]
+	}
+	return ""
+}
+
+// profileNames returns the sorted profile names
+func (f *configFile) profileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileValues converts a profile into setting values, resolving secret
+// references and rejecting unknown keys
+func profileValues(name string, profile map[string]any) (map[string]string, error) {
+	values := make(map[string]string, len(profile))
+	for key, raw := range profile {
+		value, err := settingValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %s: %w", name, key, err)
+		}
+
+		if target, ok := secretReferences[key]; ok {
+			if _, conflict := profile[target]; conflict {
+				return nil, fmt.Errorf("profile %s: set either %s or %s, not both", name, target, key)
+			}
+			secret := os.Getenv(value)
+			if secret == "" {
+				return nil, fmt.Errorf("profile %s: %s names %s, which is not set", name, key, value)
+			}
+			values[target] = secret
+			continue
+		}
+
+		if !slices.ContainsFunc(configSettings, func(s configSetting) bool { return s.name == key }) {
+			return nil, fmt.Errorf("profile %s: unknown setting %q", name, key)
+		}
+		if value != "" {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// settingValue renders a YAML scalar or list as a setting value, lists
+// becoming comma separated like their environment variables
+func settingValue(raw any) (string, error) {
+	switch value := raw.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case bool, int, float64:
+		return fmt.Sprint(value), nil
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			s, ok := item.(string)
+			if !ok {
+				return "", errors.New("list items must be strings")
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", raw)
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,169 @@
+package bokio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFile writes a config file to a temporary directory
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// clearConfigEnv unsets every variable LoadConfig reads for the test
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"BOKIO_CONFIG", "BOKIO_PROFILE"} {
+		t.Setenv(key, "")
+	}
+	for _, setting := range configSettings {
+		t.Setenv(setting.env, "")
+	}
+}
+
+const testConfigFile = `
+default_profile: prod
+profiles:
+  prod:
+    base_url: https://api.bokio.se
+    integration_token_env: PROD_BOKIO_TOKEN
+    company: acme
+    read_only: true
+    tool_groups: [invoices, customers]
+    request_timeout: 10s
+    max_retries: 5
+    rate_limit: 2.5
+  sandbox:
+    base_url: https://sandbox.bokio.se
+    integration_token: sandbox-token
+`
+
+func TestLoadConfig(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("PROD_BOKIO_TOKEN", "prod-token")
+	path := writeConfigFile(t, testConfigFile)
+
+	t.Run("default profile", func(t *testing.T) {
+		config, report, err := LoadConfig(ConfigOptions{File: path})
+		require.NoError(t, err)
+		assert.Equal(t, "prod", report.Profile)
+		assert.Equal(t, path, report.File)
+
+		assert.Equal(t, "prod-token", config.IntegrationToken)
+		assert.Equal(t, "acme", config.CompanyID)
+		assert.True(t, config.ReadOnly)
+		assert.Equal(t, []string{"invoices", "customers"}, config.ToolGroups)
+		assert.Equal(t, 10*time.Second, config.RequestTimeout)
+		assert.Equal(t, 5, config.MaxRetries)
+		assert.InDelta(t, 2.5, config.RateLimit, 0)
+
+		token := findSetting(t, report, "integration_token")
+		assert.Equal(t, "[redacted]", token.Value)
+		assert.Equal(t, SourceFile, token.Source)
+		assert.Equal(t, "profile prod", token.Origin)
+	})
+
+	t.Run("environment and flags override the profile", func(t *testing.T) {
+		t.Setenv("BOKIO_PROFILE", "sandbox")
+		t.Setenv("BOKIO_BASE_URL", "https://env.bokio.se")
+		t.Setenv("BOKIO_COMPANY_ID", "globex")
+
+		config, report, err := LoadConfig(ConfigOptions{File: path, Flags: map[string]string{"company": "initech", "read_only": "true"}})
+		require.NoError(t, err)
+		assert.Equal(t, "sandbox", report.Profile)
+		assert.Equal(t, "sandbox-token", config.IntegrationToken)
+		assert.Equal(t, "https://env.bokio.se", config.BaseURL)
+		assert.Equal(t, "initech", config.CompanyID)
+		assert.True(t, config.ReadOnly)
+
+		baseURL := findSetting(t, report, "base_url")
+		assert.Equal(t, SourceEnv, baseURL.Source)
+		assert.Equal(t, []string{"profile sandbox"}, baseURL.Overrides)
+
+		company := findSetting(t, report, "company")
+		assert.Equal(t, SourceFlag, company.Source)
+		assert.Equal(t, "--company", company.Origin)
+		assert.Equal(t, []string{"BOKIO_COMPANY_ID"}, company.Overrides)
+	})
+
+	t.Run("explicit profile wins over BOKIO_PROFILE", func(t *testing.T) {
+		t.Setenv("BOKIO_PROFILE", "sandbox")
+		_, report, err := LoadConfig(ConfigOptions{File: path, Profile: "prod"})
+		require.NoError(t, err)
+		assert.Equal(t, "prod", report.Profile)
+	})
+
+	t.Run("missing default file", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		t.Setenv("BOKIO_INTEGRATION_TOKEN", "env-token")
+
+		config, report, err := LoadConfig(ConfigOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, report.File)
+		assert.Equal(t, "env-token", config.IntegrationToken)
+		assert.Equal(t, DefaultBaseURL, config.BaseURL)
+	})
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeConfigFile(t, testConfigFile)
+
+	tests := []struct {
+		name     string
+		file     string
+		opts     ConfigOptions
+		env      map[string]string
+		errorMsg string
+	}{
+		{name: "unknown profile", opts: ConfigOptions{Profile: "staging"}, env: map[string]string{"PROD_BOKIO_TOKEN": "x"}, errorMsg: `unknown profile "staging" (available: prod, sandbox)`},
+		{name: "unset token reference", errorMsg: "integration_token_env names PROD_BOKIO_TOKEN, which is not set"},
+		{name: "missing explicit file", opts: ConfigOptions{File: filepath.Join(t.TempDir(), "missing.yaml")}, errorMsg: "failed to read config file"},
+		{name: "invalid value", opts: ConfigOptions{Profile: "sandbox", Flags: map[string]string{"max_retries": "many"}}, errorMsg: `invalid max_retries "many" from --max-retries`},
+		{name: "invalid read_only", opts: ConfigOptions{Profile: "sandbox"}, env: map[string]string{"BOKIO_READ_ONLY": "yes"}, errorMsg: `invalid read_only "yes" from BOKIO_READ_ONLY`},
+		{name: "unknown setting", file: "profiles:\n  a:\n    base_ulr: x\n", errorMsg: `profile a: unknown setting "base_ulr"`},
+		{name: "unknown top-level key", file: "profile:\n  a: {}\n", errorMsg: "failed to parse config file"},
+		{name: "token conflict", file: "profiles:\n  a:\n    integration_token: x\n    integration_token_env: Y\n", errorMsg: "set either integration_token or integration_token_env"},
+		{name: "undefined default", file: "default_profile: b\nprofiles:\n  a: {}\n", errorMsg: `default_profile "b" is not defined`},
+		{name: "ambiguous profile", file: "profiles:\n  a: {}\n  b: {}\n", errorMsg: "select one with --profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			opts := tt.opts
+			if opts.File == "" {
+				opts.File = path
+				if tt.file != "" {
+					opts.File = writeConfigFile(t, tt.file)
+				}
+			}
+
+			_, _, err := LoadConfig(opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func findSetting(t *testing.T, report *ConfigReport, name string) ConfigSetting {
+	t.Helper()
+	for _, setting := range report.Settings {
+		if setting.Name == name {
+			return setting
+		}
+	}
+	t.Fatalf("setting %s not reported", name)
+	return ConfigSetting{}
+}
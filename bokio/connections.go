@@ -0,0 +1,42 @@
+package bokio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/klowdo/bokio-mcp/bokio/generated/general"
+)
+
+// ErrTokenRejected is returned when Bokio refuses the configured credentials
+var ErrTokenRejected = errors.New("access token rejected by Bokio")
+
+// Connections lists the tenants the client-wide credentials can access
+func (ac *AuthClient) Connections(ctx context.Context) ([]general.Connection, error) {
+	resp, err := ac.GeneralClient.GetConnections(ctx, &general.GetConnectionsParams{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+
+	parsed, err := general.ParseGetConnectionsResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connections response: %w", err)
+	}
+
+	switch {
+	case parsed.StatusCode() == http.StatusUnauthorized || parsed.StatusCode() == http.StatusForbidden:
+		return nil, fmt.Errorf("%w (HTTP %d)", ErrTokenRejected, parsed.StatusCode())
+	case parsed.JSON200 == nil:
+		return nil, fmt.Errorf("failed to list connections: %w", NewAPIError(parsed.StatusCode(), parsed.Body))
+	case parsed.JSON200.Items == nil:
+		return nil, nil
+	}
+	return *parsed.JSON200.Items, nil
+}
+
+// HasClientCredentials reports whether client-wide credentials are configured,
+// as opposed to only per-company tokens from the tenant registry
+func (ac *AuthClient) HasClientCredentials() bool {
+	return ac.token != "" || ac.tokens != nil
+}
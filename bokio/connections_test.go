@@ -0,0 +1,67 @@
+package bokio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/connections", r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer valid-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"currentPage": 1,
+			"totalItems": 1,
+			"totalPages": 1,
+			"items": [{"id": "` + testCompanyB + `", "tenantId": "` + testCompanyA + `", "type": "company"}]
+		}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		token   string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "valid token",
+			token: "valid-token",
+			want:  []string{testCompanyA},
+		},
+		{
+			name:    "rejected token",
+			token:   "bad-token",
+			wantErr: ErrTokenRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewAuthClient(&Config{IntegrationToken: tt.token, BaseURL: server.URL})
+			require.NoError(t, err)
+
+			connections, err := client.Connections(context.Background())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			var tenants []string
+			for _, connection := range connections {
+				tenants = append(tenants, connection.TenantId.String())
+			}
+			assert.Equal(t, tt.want, tenants)
+		})
+	}
+}
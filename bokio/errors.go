@@ -0,0 +1,109 @@
+package bokio
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+)
+
+// ErrorCategory is a stable classification of API errors that callers can
+// act on without parsing messages
+type ErrorCategory string
+
+const (
+	// CategoryValidation means the request was rejected; fix the input and retry
+	CategoryValidation ErrorCategory = "validation"
+	// CategoryNotFound means the addressed resource does not exist
+	CategoryNotFound ErrorCategory = "not-found"
+	// CategoryAuth means the credentials are missing, expired or lack access
+	CategoryAuth ErrorCategory = "auth"
+	// CategoryRateLimited means too many requests were made; retry later
+	CategoryRateLimited ErrorCategory = "rate-limited"
+	// CategoryServer means Bokio failed to handle a valid request
+	CategoryServer ErrorCategory = "server"
+)
+
+// FieldError is a validation message for a single request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// APIError is a non-2xx response from the Bokio API with its ApiError body
+// decoded, when the response had one
+type APIError struct {
+	StatusCode int           `json:"status"`
+	Category   ErrorCategory `json:"category"`
+	Code       string        `json:"code,omitempty"`
+	Message    string        `json:"message,omitempty"`
+	// BokioErrorID identifies the failure in Bokio's logs, quote it to Bokio support
+	BokioErrorID string       `json:"bokio_error_id,omitempty"`
+	Fields       []FieldError `json:"fields,omitempty"`
+}
+
+// NewAPIError builds an APIError from a response status and raw body
+func NewAPIError(status int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: status, Category: CategoryForStatus(status)}
+
+	var parsed company.ApiError
+	if json.Unmarshal(body, &parsed) != nil {
+		return apiErr
+	}
+	if parsed.Code != nil {
+		apiErr.Code = *parsed.Code
+	}
+	if parsed.Message != nil {
+		apiErr.Message = *parsed.Message
+	}
+	if parsed.BokioErrorId != nil {
+		apiErr.BokioErrorID = parsed.BokioErrorId.String()
+	}
+	if parsed.Errors != nil {
+		for _, fieldErr := range *parsed.Errors {
+			var field FieldError
+			if fieldErr.Field != nil {
+				field.Field = *fieldErr.Field
+			}
+			if fieldErr.Message != nil {
+				field.Message = *fieldErr.Message
+			}
+			apiErr.Fields = append(apiErr.Fields, field)
+		}
+	}
+	return apiErr
+}
+
+// CategoryForStatus maps an HTTP status to an error category. Client errors
+// without a more specific category count as validation errors.
+func CategoryForStatus(status int) ErrorCategory {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return CategoryAuth
+	case status == http.StatusNotFound || status == http.StatusGone:
+		return CategoryNotFound
+	case status == http.StatusTooManyRequests:
+		return CategoryRateLimited
+	case status >= 500:
+		return CategoryServer
+	default:
+		return CategoryValidation
+	}
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "API returned status %d (%s)", e.StatusCode, e.Category)
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	for _, field := range e.Fields {
+		fmt.Fprintf(&b, "\n- %s: %s", field.Field, field.Message)
+	}
+	if e.BokioErrorID != "" {
+		fmt.Fprintf(&b, "\nBokio error ID: %s (quote this when contacting Bokio support)", e.BokioErrorID)
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package bokio
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   *APIError
+		text   string
+	}{
+		{
+			name:   "validation with fields",
+			status: http.StatusBadRequest,
+			body:   `{"code":"validation-error","message":"Validation failed with 1 errors","bokioErrorId":"55555555-5555-5555-5555-555555555555","errors":[{"field":"#/items/1/account","message":"The account field is required"}]}`,
+			want: &APIError{
+				StatusCode:   http.StatusBadRequest,
+				Category:     CategoryValidation,
+				Code:         "validation-error",
+				Message:      "Validation failed with 1 errors",
+				BokioErrorID: "55555555-5555-5555-5555-555555555555",
+				Fields:       []FieldError{{Field: "#/items/1/account", Message: "The account field is required"}},
+			},
+			text: "API returned status 400 (validation): Validation failed with 1 errors\n" +
+				"- #/items/1/account: The account field is required\n" +
+				"Bokio error ID: 55555555-5555-5555-5555-555555555555 (quote this when contacting Bokio support)",
+		},
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			body:   `{"code":"not-found","message":"Invoice not found"}`,
+			want:   &APIError{StatusCode: http.StatusNotFound, Category: CategoryNotFound, Code: "not-found", Message: "Invoice not found"},
+			text:   "API returned status 404 (not-found): Invoice not found",
+		},
+		{
+			name:   "non-json body",
+			status: http.StatusInternalServerError,
+			body:   "<html>oops</html>",
+			want:   &APIError{StatusCode: http.StatusInternalServerError, Category: CategoryServer},
+			text:   "API returned status 500 (server)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewAPIError(tt.status, []byte(tt.body))
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.text, got.Error())
+		})
+	}
+}
+
+func TestCategoryForStatus(t *testing.T) {
+	tests := map[int]ErrorCategory{
+		http.StatusBadRequest:          CategoryValidation,
+		http.StatusConflict:            CategoryValidation,
+		http.StatusUnprocessableEntity: CategoryValidation,
+		http.StatusUnauthorized:        CategoryAuth,
+		http.StatusForbidden:           CategoryAuth,
+		http.StatusNotFound:            CategoryNotFound,
+		http.StatusTooManyRequests:     CategoryRateLimited,
+		http.StatusInternalServerError: CategoryServer,
+		http.StatusServiceUnavailable:  CategoryServer,
+	}
+	for status, want := range tests {
+		assert.Equal(t, want, CategoryForStatus(status), "status %d", status)
+	}
+}
@@ -0,0 +1,273 @@
+package bokio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the type of a filterable field in the Bokio query language
+type FieldType string
+
+// Field types used in the endpoint field tables
+const (
+	FieldString FieldType = "string"
+	FieldNumber FieldType = "number"
+	FieldDate   FieldType = "date"
+)
+
+// Operator compares a field with a value in the Bokio query language
+type Operator string
+
+// Operators of the query language; ~ and !~ test whether a string contains the value
+const (
+	OpEqual          Operator = "=="
+	OpNotEqual       Operator = "!="
+	OpGreater        Operator = ">"
+	OpGreaterOrEqual Operator = ">="
+	OpLess           Operator = "<"
+	OpLessOrEqual    Operator = "<="
+	OpContains       Operator = "~"
+	OpNotContains    Operator = "!~"
+)
+
+// operatorTypes lists the field types each operator applies to
+var operatorTypes = map[Operator][]FieldType{
+	OpEqual:          {FieldString, FieldNumber, FieldDate},
+	OpNotEqual:       {FieldString, FieldNumber, FieldDate},
+	OpGreater:        {FieldNumber, FieldDate},
+	OpGreaterOrEqual: {FieldNumber, FieldDate},
+	OpLess:           {FieldNumber, FieldDate},
+	OpLessOrEqual:    {FieldNumber, FieldDate},
+	OpContains:       {FieldString},
+	OpNotContains:    {FieldString},
+}
+
+// FilterFields maps the filterable fields of an endpoint to their types. A
+// name ending in ".*", such as "metadata.*", declares the subfields of an
+// object field, e.g. metadata.orderId.
+type FilterFields map[string]FieldType
+
+// Filterable fields of the list endpoints, from the API documentation
+var (
+	CustomerFilterFields = FilterFields{
+		"name":      FieldString,
+		"type":      FieldString,
+		"vatNumber": FieldString,
+		"orgNumber": FieldString,
+	}
+	FiscalYearFilterFields = FilterFields{
+		"startDate":        FieldDate,
+		"endDate":          FieldDate,
+		"accountingMethod": FieldString,
+		"status":           FieldString,
+	}
+	InvoiceFilterFields = FilterFields{
+		"type":                 FieldString,
+		"customerRef":          FieldString,
+		"orderNumberReference": FieldString,
+		"currency":             FieldString,
+		"totalAmount":          FieldNumber,
+		"status":               FieldString,
+		"invoiceDate":          FieldDate,
+		"dueDate":              FieldDate,
+		"metadata":             FieldString,
+		"metadata.*":           FieldString,
+	}
+	InvoiceAttachmentFilterFields = FilterFields{
+		"fileName": FieldString,
+	}
+	ItemFilterFields = FilterFields{
+		"description": FieldString,
+		"itemType":    FieldString,
+		"productType": FieldString,
+		"unitType":    FieldString,
+		"unitPrice":   FieldNumber,
+		"taxRate":     FieldNumber,
+	}
+	JournalEntryFilterFields = FilterFields{
+		"title":                    FieldString,
+		"journalEntryNumber":       FieldString,
+		"date":                     FieldDate,
+		"reversingJournalEntryId":  FieldString,
+		"reversedByJournalEntryId": FieldString,
+	}
+	UploadFilterFields = FilterFields{
+		"description":    FieldString,
+		"journalEntryId": FieldString,
+	}
+)
+
+// Names returns the field names in alphabetical order
+func (f FilterFields) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup returns the type of field, matching subfields of object fields
+// declared with ".*"
+func (f FilterFields) lookup(field string) (FieldType, bool) {
+	if fieldType, ok := f[field]; ok {
+		return fieldType, true
+	}
+	object, subfield, ok := strings.Cut(field, ".")
+	if !ok || subfield == "" || strings.ContainsAny(subfield, "=!<>~&|") {
+		return "", false
+	}
+	fieldType, ok := f[object+".*"]
+	return fieldType, ok
+}
+
+// Condition compares one field with a value. Value is a string, a number or,
+// for date fields, a time.Time or YYYY-MM-DD string.
+type Condition struct {
+	Field string   `json:"field"`
+	Op    Operator `json:"op"`
+	Value any      `json:"value"`
+}
+
+// Where builds a condition
+func Where(field string, op Operator, value any) Condition {
+	return Condition{Field: field, Op: op, Value: value}
+}
+
+// Filter is a query in disjunctive normal form: it matches when all
+// conditions of at least one group match
+type Filter struct {
+	Groups [][]Condition
+}
+
+// And returns a filter matching all conditions
+func And(conditions ...Condition) Filter {
+	return Filter{Groups: [][]Condition{conditions}}
+}
+
+// Or returns a filter matching any of the filters
+func Or(filters ...Filter) Filter {
+	var combined Filter
+	for _, filter := range filters {
+		combined.Groups = append(combined.Groups, filter.Groups...)
+	}
+	return combined
+}
+
+// And narrows every group of f with the conditions
+func (f Filter) And(conditions ...Condition) Filter {
+	if len(f.Groups) == 0 {
+		return And(conditions...)
+	}
+	narrowed := Filter{Groups: make([][]Condition, len(f.Groups))}
+	for i, group := range f.Groups {
+		narrowed.Groups[i] = append(append([]Condition{}, group...), conditions...)
+	}
+	return narrowed
+}
+
+// Query validates f against the filterable fields of an endpoint and
+// renders it in the Bokio query syntax, e.g. status==paid&&dueDate<2024-10-10.
+// An empty filter renders as an empty string.
+func (f Filter) Query(fields FilterFields) (string, error) {
+	var groups []string
+	for _, group := range f.Groups {
+		var clauses []string
+		for _, condition := range group {
+			clause, err := condition.render(fields)
+			if err != nil {
+				return "", err
+			}
+			clauses = append(clauses, clause)
+		}
+		if len(clauses) > 0 {
+			groups = append(groups, strings.Join(clauses, "&&"))
+		}
+	}
+	return strings.Join(groups, "||"), nil
+}
+
+// render validates a condition and renders it as field, operator and value
+func (c Condition) render(fields FilterFields) (string, error) {
+	fieldType, ok := fields.lookup(c.Field)
+	if !ok {
+		return "", fmt.Errorf("unknown filter field %q, supported fields are %s", c.Field, strings.Join(fields.Names(), ", "))
+	}
+
+	types, ok := operatorTypes[c.Op]
+	if !ok {
+		return "", fmt.Errorf("unknown filter operator %q for %s, supported operators are ==, !=, >, >=, <, <=, ~ and !~", c.Op, c.Field)
+	}
+	supported := false
+	for _, t := range types {
+		supported = supported || t == fieldType
+	}
+	if !supported {
+		return "", fmt.Errorf("operator %s cannot be used on %s field %s", c.Op, fieldType, c.Field)
+	}
+
+	value, err := formatFilterValue(fieldType, c.Value)
+	if err != nil {
+		return "", fmt.Errorf("invalid value for %s: %w", c.Field, err)
+	}
+	return c.Field + string(c.Op) + value, nil
+}
+
+// formatFilterValue renders value as a field of the given type
+func formatFilterValue(fieldType FieldType, value any) (string, error) {
+	switch fieldType {
+	case FieldNumber:
+		var number float64
+		switch v := value.(type) {
+		case float64:
+			number = v
+		case int:
+			number = float64(v)
+		case int32:
+			number = float64(v)
+		case int64:
+			number = float64(v)
+		case string:
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return "", fmt.Errorf("%q is not a number", v)
+			}
+			number = parsed
+		default:
+			return "", fmt.Errorf("expected a number, got %T", value)
+		}
+		if math.IsNaN(number) || math.IsInf(number, 0) {
+			return "", errors.New("expected a finite number")
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+
+	case FieldDate:
+		switch v := value.(type) {
+		case time.Time:
+			return v.Format(DateLayout), nil
+		case string:
+			if _, err := time.Parse(DateLayout, v); err != nil {
+				return "", fmt.Errorf("%q is not a date in YYYY-MM-DD format", v)
+			}
+			return v, nil
+		default:
+			return "", fmt.Errorf("expected a date in YYYY-MM-DD format, got %T", value)
+		}
+
+	default:
+		v, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("expected a string, got %T", value)
+		}
+		// The query syntax has no escaping, so logical operators cannot appear in values
+		if strings.Contains(v, "&&") || strings.Contains(v, "||") {
+			return "", fmt.Errorf("%q contains && or ||, which cannot be used in filter values", v)
+		}
+		return v, nil
+	}
+}
@@ -0,0 +1,130 @@
+package bokio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		fields FilterFields
+		want   string
+	}{
+		{
+			name:   "empty",
+			filter: Filter{},
+			fields: InvoiceFilterFields,
+			want:   "",
+		},
+		{
+			name:   "and",
+			filter: And(Where("status", OpEqual, "paid"), Where("dueDate", OpLess, "2024-10-10")),
+			fields: InvoiceFilterFields,
+			want:   "status==paid&&dueDate<2024-10-10",
+		},
+		{
+			name:   "number and time values",
+			filter: And(Where("totalAmount", OpGreaterOrEqual, 1250.5), Where("invoiceDate", OpGreater, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC))),
+			fields: InvoiceFilterFields,
+			want:   "totalAmount>=1250.5&&invoiceDate>2024-01-31",
+		},
+		{
+			name:   "or narrowed by and",
+			filter: Or(And(Where("currency", OpEqual, "SEK")), And(Where("currency", OpEqual, "EUR"))).And(Where("status", OpNotEqual, "draft")),
+			fields: InvoiceFilterFields,
+			want:   "currency==SEK&&status!=draft||currency==EUR&&status!=draft",
+		},
+		{
+			name:   "contains",
+			filter: And(Where("description", OpContains, "Receipt for invoice 1234")),
+			fields: UploadFilterFields,
+			want:   "description~Receipt for invoice 1234",
+		},
+		{
+			name:   "metadata key",
+			filter: And(Where("metadata.idempotencyKey", OpEqual, "order-1234")),
+			fields: InvoiceFilterFields,
+			want:   "metadata.idempotencyKey==order-1234",
+		},
+		{
+			name:   "numeric string",
+			filter: And(Where("unitPrice", OpLess, "100")),
+			fields: ItemFilterFields,
+			want:   "unitPrice<100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.filter.Query(tt.fields)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFilterQueryErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition Condition
+		want      string
+	}{
+		{
+			name:      "unknown field",
+			condition: Where("amount", OpEqual, 10),
+			want:      `unknown filter field "amount", supported fields are currency, customerRef, dueDate, invoiceDate, metadata, metadata.*, orderNumberReference, status, totalAmount, type`,
+		},
+		{
+			name:      "operator in a metadata key",
+			condition: Where("metadata.a==b", OpEqual, "c"),
+			want:      `unknown filter field "metadata.a==b", supported fields are currency, customerRef, dueDate, invoiceDate, metadata, metadata.*, orderNumberReference, status, totalAmount, type`,
+		},
+		{
+			name:      "unknown operator",
+			condition: Where("status", "=", "paid"),
+			want:      `unknown filter operator "=" for status, supported operators are ==, !=, >, >=, <, <=, ~ and !~`,
+		},
+		{
+			name:      "contains on number",
+			condition: Where("totalAmount", OpContains, 10),
+			want:      "operator ~ cannot be used on number field totalAmount",
+		},
+		{
+			name:      "ordering on string",
+			condition: Where("status", OpGreater, "paid"),
+			want:      "operator > cannot be used on string field status",
+		},
+		{
+			name:      "invalid number",
+			condition: Where("totalAmount", OpGreater, "lots"),
+			want:      `invalid value for totalAmount: "lots" is not a number`,
+		},
+		{
+			name:      "invalid date",
+			condition: Where("dueDate", OpLess, "10/10/2024"),
+			want:      `invalid value for dueDate: "10/10/2024" is not a date in YYYY-MM-DD format`,
+		},
+		{
+			name:      "number for string field",
+			condition: Where("currency", OpEqual, 752.0),
+			want:      "invalid value for currency: expected a string, got float64",
+		},
+		{
+			name:      "logical operator in value",
+			condition: Where("customerRef", OpEqual, "a&&status==paid"),
+			want:      `invalid value for customerRef: "a&&status==paid" contains && or ||, which cannot be used in filter values`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := And(tt.condition).Query(InvoiceFilterFields)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
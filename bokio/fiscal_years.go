@@ -0,0 +1,186 @@
+package bokio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+)
+
+// DateLayout is the date format used by the Bokio API
+const DateLayout = "2006-01-02"
+
+// fiscalYearPageSize is the page size used when reading all fiscal years
+const fiscalYearPageSize int32 = 100
+
+// ErrFiscalYearNotFound is returned when no fiscal year matches a lookup
+var ErrFiscalYearNotFound = errors.New("fiscal year not found")
+
+// FiscalYearFilter narrows a fiscal year listing. Empty fields are ignored.
+type FiscalYearFilter struct {
+	// StartDate keeps fiscal years starting on or after this date
+	StartDate string
+	// EndDate keeps fiscal years ending on or before this date
+	EndDate string
+	// Status is "open" or "closed"
+	Status string
+	// AccountingMethod is "accrual" or "cash"
+	AccountingMethod string
+}
+
+// Query validates the filter and renders it in the Bokio query syntax
+func (f FiscalYearFilter) Query() (string, error) {
+	var conditions []Condition
+	if f.StartDate != "" {
+		conditions = append(conditions, Where("startDate", OpGreaterOrEqual, f.StartDate))
+	}
+	if f.EndDate != "" {
+		conditions = append(conditions, Where("endDate", OpLessOrEqual, f.EndDate))
+	}
+	if f.Status != "" {
+		conditions = append(conditions, Where("status", OpEqual, f.Status))
+	}
+	if f.AccountingMethod != "" {
+		conditions = append(conditions, Where("accountingMethod", OpEqual, f.AccountingMethod))
+	}
+	return And(conditions...).Query(FiscalYearFilterFields)
+}
+
+// FiscalYears returns all fiscal years matching the filter, oldest first
+func (ac *AuthClient) FiscalYears(ctx context.Context, companyID uuid.UUID, filter FiscalYearFilter) ([]company.FiscalYear, error) {
+	query, err := filter.Query()
+	if err != nil {
+		return nil, err
+	}
+	params := &company.GetFiscalYearsParams{PageSize: int32Ptr(fiscalYearPageSize)}
+	if query != "" {
+		params.Query = &query
+	}
+
+	// Items are untyped in the schema and decoded as fiscal years
+	years, err := allPages[company.FiscalYear]("fiscal years", func(page int32) (*http.Response, error) {
+		params.Page = int32Ptr(page)
+		return ac.CompanyClient.GetFiscalYears(ctx, companyID, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(years, func(i, j int) bool {
+		return years[i].StartDate.Before(years[j].StartDate.Time)
+	})
+	return years, nil
+}
+
+// FiscalYear returns a single fiscal year by ID
+func (ac *AuthClient) FiscalYear(ctx context.Context, companyID, fiscalYearID uuid.UUID) (*company.FiscalYear, error) {
+	resp, err := ac.CompanyClient.GetFiscalYearWithId(ctx, companyID, fiscalYearID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fiscal year: %w", err)
+	}
+
+	parsed, err := company.ParseGetFiscalYearWithIdResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fiscal year response: %w", err)
+	}
+
+	switch {
+	case parsed.StatusCode() == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrFiscalYearNotFound, fiscalYearID)
+	case parsed.JSON200 == nil:
+		return nil, fmt.Errorf("failed to get fiscal year: %w", NewAPIError(parsed.StatusCode(), parsed.Body))
+	case parsed.JSON200.FiscalYear == nil:
+		return nil, fmt.Errorf("%w: %s", ErrFiscalYearNotFound, fiscalYearID)
+	}
+	return parsed.JSON200.FiscalYear, nil
+}
+
+// FiscalYearContaining returns the fiscal year whose period includes date
+func (ac *AuthClient) FiscalYearContaining(ctx context.Context, companyID uuid.UUID, date time.Time) (*company.FiscalYear, error) {
+	years, err := ac.FiscalYears(ctx, companyID, FiscalYearFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	if year := fiscalYearContaining(years, date); year != nil {
+		return year, nil
+	}
+	return nil, fmt.Errorf("%w: no fiscal year contains %s", ErrFiscalYearNotFound, date.Format(DateLayout))
+}
+
+// CurrentFiscalYear returns the open fiscal year containing now. If the
+// books are behind and no open year covers today, the latest open fiscal
+// year is returned instead.
+func (ac *AuthClient) CurrentFiscalYear(ctx context.Context, companyID uuid.UUID, now time.Time) (*company.FiscalYear, error) {
+	years, err := ac.FiscalYears(ctx, companyID, FiscalYearFilter{Status: string(company.Open)})
+	if err != nil {
+		return nil, err
+	}
+	if len(years) == 0 {
+		return nil, fmt.Errorf("%w: the company has no open fiscal year", ErrFiscalYearNotFound)
+	}
+
+	if year := fiscalYearContaining(years, now); year != nil {
+		return year, nil
+	}
+	return &years[len(years)-1], nil
+}
+
+// PreviousFiscalYear returns the latest fiscal year ending before year starts
+func (ac *AuthClient) PreviousFiscalYear(ctx context.Context, companyID uuid.UUID, year *company.FiscalYear) (*company.FiscalYear, error) {
+	years, err := ac.FiscalYears(ctx, companyID, FiscalYearFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	start := year.StartDate.Format(DateLayout)
+	for i := len(years) - 1; i >= 0; i-- {
+		if years[i].EndDate.Format(DateLayout) < start {
+			return &years[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no fiscal year precedes %s", ErrFiscalYearNotFound, start)
+}
+
+// fiscalYearContaining finds the fiscal year whose start and end dates
+// (both inclusive) surround the given day
+func fiscalYearContaining(years []company.FiscalYear, date time.Time) *company.FiscalYear {
+	day := date.Format(DateLayout)
+	for i := range years {
+		if years[i].StartDate.Format(DateLayout) <= day && day <= years[i].EndDate.Format(DateLayout) {
+			return &years[i]
+		}
+	}
+	return nil
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+// DownloadSIE returns the raw SIE 4 export of a fiscal year
+func (ac *AuthClient) DownloadSIE(ctx context.Context, companyID, fiscalYearID uuid.UUID) ([]byte, error) {
+	resp, err := ac.CompanyClient.DownloadSieFile(ctx, companyID, fiscalYearID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download SIE file: %w", err)
+	}
+
+	parsed, err := company.ParseDownloadSieFileResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SIE file: %w", err)
+	}
+
+	switch parsed.StatusCode() {
+	case http.StatusOK:
+		return parsed.Body, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrFiscalYearNotFound, fiscalYearID)
+	default:
+		return nil, fmt.Errorf("failed to download SIE file: %w", NewAPIError(parsed.StatusCode(), parsed.Body))
+	}
+}
@@ -0,0 +1,115 @@
+package bokio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testFiscalYear2024 = "aaaaaaaa-0000-0000-0000-000000002024"
+	testFiscalYear2025 = "aaaaaaaa-0000-0000-0000-000000002025"
+)
+
+func TestFiscalYearFilterQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter FiscalYearFilter
+		want   string
+	}{
+		{
+			name: "empty filter",
+			want: "",
+		},
+		{
+			name:   "status only",
+			filter: FiscalYearFilter{Status: "open"},
+			want:   "status==open",
+		},
+		{
+			name: "all fields",
+			filter: FiscalYearFilter{
+				StartDate:        "2024-01-01",
+				EndDate:          "2025-12-31",
+				Status:           "closed",
+				AccountingMethod: "accrual",
+			},
+			want: "startDate>=2024-01-01&&endDate<=2025-12-31&&status==closed&&accountingMethod==accrual",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := tt.filter.Query()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, query)
+		})
+	}
+
+	_, err := FiscalYearFilter{StartDate: "2024-13-01"}.Query()
+	assert.ErrorContains(t, err, "is not a date")
+	_, err = FiscalYearFilter{Status: "open||status==closed"}.Query()
+	assert.ErrorContains(t, err, "cannot be used in filter values")
+}
+
+func TestFiscalYearLookup(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("query"))
+		w.Header().Set("Content-Type", "application/json")
+
+		// Serve one fiscal year per page to exercise pagination
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, _ = w.Write([]byte(`{"currentPage": 1, "totalPages": 2, "items": [
+				{"id": "` + testFiscalYear2025 + `", "startDate": "2025-01-01", "endDate": "2025-12-31", "status": "open", "accountingMethod": "accrual", "vatSetting": "quarterly"}
+			]}`))
+		default:
+			_, _ = w.Write([]byte(`{"currentPage": 2, "totalPages": 2, "items": [
+				{"id": "` + testFiscalYear2024 + `", "startDate": "2024-01-01", "endDate": "2024-12-31", "status": "open", "accountingMethod": "accrual", "vatSetting": "quarterly"}
+			]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewAuthClient(&Config{IntegrationToken: "test-token", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	companyID := uuid.MustParse(testCompanyA)
+	ctx := context.Background()
+
+	years, err := client.FiscalYears(ctx, companyID, FiscalYearFilter{})
+	require.NoError(t, err)
+	require.Len(t, years, 2)
+	assert.Equal(t, testFiscalYear2024, years[0].Id.String(), "years are sorted oldest first")
+
+	year, err := client.FiscalYearContaining(ctx, companyID, time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, testFiscalYear2024, year.Id.String())
+
+	_, err = client.FiscalYearContaining(ctx, companyID, time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrFiscalYearNotFound)
+
+	year, err = client.CurrentFiscalYear(ctx, companyID, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, testFiscalYear2025, year.Id.String())
+	assert.Equal(t, "status==open", queries[len(queries)-1])
+
+	// With no open year covering today the latest open year is used
+	year, err = client.CurrentFiscalYear(ctx, companyID, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, testFiscalYear2025, year.Id.String())
+
+	previous, err := client.PreviousFiscalYear(ctx, companyID, year)
+	require.NoError(t, err)
+	assert.Equal(t, testFiscalYear2024, previous.Id.String())
+
+	_, err = client.PreviousFiscalYear(ctx, companyID, previous)
+	assert.ErrorIs(t, err, ErrFiscalYearNotFound, "2024 is the first fiscal year")
+}
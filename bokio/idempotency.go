@@ -0,0 +1,130 @@
+package bokio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// IdempotencyRecord links an idempotency key to the resource created with it
+type IdempotencyRecord struct {
+	// Scope identifies the operation and company, e.g. "create invoice <company>"
+	Scope string `json:"scope"`
+	Key   string `json:"key"`
+	// ResourceID is the created resource, empty while the create is in flight
+	// or its outcome is unknown
+	ResourceID string    `json:"resource_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// IdempotencyStore persists idempotency records between server runs
+type IdempotencyStore interface {
+	// Get returns the unexpired record for scope and key, or nil
+	Get(scope, key string) (*IdempotencyRecord, error)
+	// Put stores a record, replacing any record with the same scope and key
+	Put(record *IdempotencyRecord) error
+	// Delete removes the record for scope and key, if any
+	Delete(scope, key string) error
+}
+
+// FileIdempotencyStore stores idempotency records as JSON in a file
+// readable only by the owner. Expired records are dropped on write.
+type FileIdempotencyStore struct {
+	Path string
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewFileIdempotencyStore creates an idempotency store backed by the given file path
+func NewFileIdempotencyStore(path string) *FileIdempotencyStore {
+	return &FileIdempotencyStore{Path: path, now: time.Now}
+}
+
+// DefaultIdempotencyFile returns the default location of the idempotency file
+func DefaultIdempotencyFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "bokio-mcp", "idempotency.json")
+}
+
+// Get implements IdempotencyStore
+func (s *FileIdempotencyStore) Get(scope, key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if record.Scope == scope && record.Key == key && s.now().Before(record.ExpiresAt) {
+			return record, nil
+		}
+	}
+	return nil, nil
+}
+
+// Put implements IdempotencyStore
+func (s *FileIdempotencyStore) Put(record *IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.update(record.Scope, record.Key, record)
+}
+
+// Delete implements IdempotencyStore
+func (s *FileIdempotencyStore) Delete(scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.update(scope, key, nil)
+}
+
+// update rewrites the file with the record for scope and key replaced by
+// record, or removed when record is nil
+func (s *FileIdempotencyStore) update(scope, key string, record *IdempotencyRecord) error {
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	kept := []*IdempotencyRecord{}
+	if record != nil {
+		kept = append(kept, record)
+	}
+	now := s.now()
+	for _, existing := range records {
+		if (existing.Scope != scope || existing.Key != key) && now.Before(existing.ExpiresAt) {
+			kept = append(kept, existing)
+		}
+	}
+
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency records: %w", err)
+	}
+	return writeFileAtomic(s.Path, data, "idempotency")
+}
+
+// load reads all records, an absent file holding none
+func (s *FileIdempotencyStore) load() ([]*IdempotencyRecord, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read idempotency file: %w", err)
+	}
+
+	var records []*IdempotencyRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse idempotency file %s: %w", s.Path, err)
+	}
+	return records, nil
+}
@@ -0,0 +1,69 @@
+package bokio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileIdempotencyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bokio-mcp", "idempotency.json")
+	now := time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC)
+	store := NewFileIdempotencyStore(path)
+	store.now = func() time.Time { return now }
+
+	record, err := store.Get("create invoice c1", "key-1")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	require.NoError(t, store.Put(&IdempotencyRecord{Scope: "create invoice c1", Key: "key-1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, store.Put(&IdempotencyRecord{Scope: "create invoice c1", Key: "key-2", CreatedAt: now, ExpiresAt: now.Add(time.Minute)}))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Completing a record replaces the pending one
+	require.NoError(t, store.Put(&IdempotencyRecord{Scope: "create invoice c1", Key: "key-1", ResourceID: "r1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	record, err = store.Get("create invoice c1", "key-1")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "r1", record.ResourceID)
+
+	// Scopes keep keys of different operations and companies apart
+	record, err = store.Get("create invoice c2", "key-1")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	// Expired records are ignored and dropped on the next write
+	now = now.Add(10 * time.Minute)
+	record, err = store.Get("create invoice c1", "key-2")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+	require.NoError(t, store.Put(&IdempotencyRecord{Scope: "create customer c1", Key: "key-3", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	records, err := store.load()
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	// Deleting a record leaves the others
+	require.NoError(t, store.Delete("create customer c1", "key-3"))
+	record, err = store.Get("create customer c1", "key-3")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+	require.NoError(t, store.Delete("create customer c1", "missing"))
+	records, err = store.load()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	// A new store reads the records written by an earlier run
+	reopened := NewFileIdempotencyStore(path)
+	reopened.now = store.now
+	record, err = reopened.Get("create invoice c1", "key-1")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "r1", record.ResourceID)
+}
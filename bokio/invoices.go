@@ -0,0 +1,30 @@
+package bokio
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+)
+
+// invoicePageSize is the page size used when reading all invoices
+const invoicePageSize int32 = 100
+
+// Invoices returns every invoice matching filter; an empty filter returns
+// all invoices of the company
+func (ac *AuthClient) Invoices(ctx context.Context, companyID uuid.UUID, filter Filter) ([]company.Invoice, error) {
+	query, err := filter.Query(InvoiceFilterFields)
+	if err != nil {
+		return nil, err
+	}
+	params := &company.GetInvoiceParams{PageSize: int32Ptr(invoicePageSize)}
+	if query != "" {
+		params.Query = &query
+	}
+
+	return allPages[company.Invoice]("invoices", func(page int32) (*http.Response, error) {
+		params.Page = int32Ptr(page)
+		return ac.CompanyClient.GetInvoice(ctx, companyID, params)
+	})
+}
@@ -0,0 +1,47 @@
+package bokio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoices(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("query"))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, _ = w.Write([]byte(`{"currentPage": 1, "totalPages": 2, "items": [
+				{"invoiceNumber": "1001", "invoiceDate": "2024-02-01", "dueDate": "2024-03-02", "lineItems": []}
+			]}`))
+		default:
+			_, _ = w.Write([]byte(`{"currentPage": 2, "totalPages": 2, "items": [
+				{"invoiceNumber": "1002", "invoiceDate": "2024-02-15", "dueDate": "2024-03-16", "lineItems": []}
+			]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewAuthClient(&Config{IntegrationToken: "test-token", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	invoices, err := client.Invoices(context.Background(), uuid.MustParse(testCompanyA),
+		And(Where("invoiceDate", OpGreaterOrEqual, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))))
+	require.NoError(t, err)
+	require.Len(t, invoices, 2)
+	assert.Equal(t, "1002", *invoices[1].InvoiceNumber)
+	assert.Equal(t, []string{"invoiceDate>=2024-02-01", "invoiceDate>=2024-02-01"}, queries)
+
+	_, err = client.Invoices(context.Background(), uuid.MustParse(testCompanyA), Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, "", queries[len(queries)-1], "an empty filter sends no query")
+}
@@ -0,0 +1,63 @@
+package bokio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+)
+
+// journalEntryPageSize is the page size used when reading all journal entries
+const journalEntryPageSize int32 = 100
+
+// JournalEntries returns every journal entry dated from from to to, both
+// inclusive, ordered by date and journal entry number
+func (ac *AuthClient) JournalEntries(ctx context.Context, companyID uuid.UUID, from, to time.Time) ([]company.JournalEntry, error) {
+	query, err := And(
+		Where("date", OpGreaterOrEqual, from),
+		Where("date", OpLessOrEqual, to),
+	).Query(JournalEntryFilterFields)
+	if err != nil {
+		return nil, err
+	}
+	params := &company.GetJournalentryParams{PageSize: int32Ptr(journalEntryPageSize), Query: &query}
+
+	entries, err := allPages[company.JournalEntry]("journal entries", func(page int32) (*http.Response, error) {
+		params.Page = int32Ptr(page)
+		return ac.CompanyClient.GetJournalentry(ctx, companyID, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := journalEntrySortKey(&entries[i]), journalEntrySortKey(&entries[j])
+		if a.date != b.date {
+			return a.date < b.date
+		}
+		return a.number < b.number
+	})
+	return entries, nil
+}
+
+// journalEntryKey orders journal entries, numbers compared by length first
+// so that V10 follows V9
+type journalEntryKey struct {
+	date   string
+	number string
+}
+
+func journalEntrySortKey(entry *company.JournalEntry) journalEntryKey {
+	var key journalEntryKey
+	if entry.Date != nil {
+		key.date = entry.Date.Format(DateLayout)
+	}
+	if entry.JournalEntryNumber != nil {
+		key.number = fmt.Sprintf("%08d%s", len(*entry.JournalEntryNumber), *entry.JournalEntryNumber)
+	}
+	return key
+}
@@ -0,0 +1,49 @@
+package bokio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalEntries(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("query"))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, _ = w.Write([]byte(`{"currentPage": 1, "totalPages": 2, "items": [
+				{"journalEntryNumber": "V10", "date": "2024-02-01", "items": []},
+				{"journalEntryNumber": "V9", "date": "2024-02-01", "items": []}
+			]}`))
+		default:
+			_, _ = w.Write([]byte(`{"currentPage": 2, "totalPages": 2, "items": [
+				{"journalEntryNumber": "V1", "date": "2024-01-05", "items": []}
+			]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewAuthClient(&Config{IntegrationToken: "test-token", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	entries, err := client.JournalEntries(context.Background(), uuid.MustParse(testCompanyA),
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	var numbers []string
+	for _, entry := range entries {
+		numbers = append(numbers, *entry.JournalEntryNumber)
+	}
+	assert.Equal(t, []string{"V1", "V9", "V10"}, numbers, "entries are ordered by date and number")
+	assert.Equal(t, []string{"date>=2024-01-01&&date<=2024-03-31", "date>=2024-01-01&&date<=2024-03-31"}, queries)
+}
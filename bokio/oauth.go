@@ -0,0 +1,452 @@
+package bokio
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio/generated/general"
+)
+
+const (
+	// DefaultRedirectURL is the callback address used for the authorization code flow
+	DefaultRedirectURL = "http://localhost:8080/callback"
+
+	// refreshSkew is how long before expiry an access token is proactively refreshed
+	refreshSkew = 60 * time.Second
+
+	// authorizationTimeout bounds how long the callback listener waits for the user
+	authorizationTimeout = 5 * time.Minute
+)
+
+// ErrNotAuthorized is returned when no OAuth2 token is available yet
+var ErrNotAuthorized = errors.New("not authorized: run the bokio_authenticate tool or `bokio-mcp login` first")
+
+// TokenSource supplies access tokens for outgoing API requests
+type TokenSource interface {
+	// Token returns a valid access token, refreshing it first if it is about to expire
+	Token(ctx context.Context) (string, error)
+	// Refresh discards the current access token and obtains a new one
+	Refresh(ctx context.Context) (string, error)
+}
+
+// cachingTokenSource is a TokenSource that keeps the last issued token
+type cachingTokenSource interface {
+	TokenSource
+	// current returns a copy of the cached token, or nil
+	current() *Token
+}
+
+// tokenEndpoint calls the OAuth2 /token endpoint with client authentication
+type tokenEndpoint struct {
+	clientID     string
+	clientSecret string
+	client       *general.Client
+	now          func() time.Time
+}
+
+// newTokenEndpoint creates a token endpoint client for the configured integration
+func newTokenEndpoint(config *Config) (*tokenEndpoint, error) {
+	// The token endpoint uses basic client authentication, so it must not go
+	// through the bearer-authenticated HTTP client.
+	client, err := general.NewClient(config.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token client: %w", err)
+	}
+
+	return &tokenEndpoint{
+		clientID:     config.ClientID,
+		clientSecret: config.ClientSecret,
+		client:       client,
+		now:          time.Now,
+	}, nil
+}
+
+// oauthTokenSource implements the OAuth2 authorization code flow with refresh
+type oauthTokenSource struct {
+	*tokenEndpoint
+	redirectURL string
+	scope       string
+	store       TokenStore
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// newOAuthTokenSource creates a token source and loads any previously stored token
+func newOAuthTokenSource(config *Config, store TokenStore) (*oauthTokenSource, error) {
+	endpoint, err := newTokenEndpoint(config)
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURL := config.RedirectURL
+	if redirectURL == "" {
+		redirectURL = DefaultRedirectURL
+	}
+
+	source := &oauthTokenSource{
+		tokenEndpoint: endpoint,
+		redirectURL:   redirectURL,
+		scope:         config.Scope,
+		store:         store,
+	}
+
+	token, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	source.token = token
+
+	return source, nil
+}
+
+// Token implements TokenSource
+func (s *oauthTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil || s.token.AccessToken == "" {
+		return "", ErrNotAuthorized
+	}
+
+	if s.token.expiresWithin(refreshSkew, s.now()) {
+		if err := s.refreshLocked(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	return s.token.AccessToken, nil
+}
+
+// Refresh implements TokenSource
+func (s *oauthTokenSource) Refresh(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		return "", ErrNotAuthorized
+	}
+	if err := s.refreshLocked(ctx); err != nil {
+		return "", err
+	}
+	return s.token.AccessToken, nil
+}
+
+// current returns a copy of the current token, or nil
+func (s *oauthTokenSource) current() *Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		return nil
+	}
+	token := *s.token
+	return &token
+}
+
+// refreshLocked exchanges the refresh token for a new token. Callers must hold s.mu.
+func (s *oauthTokenSource) refreshLocked(ctx context.Context) error {
+	if s.token.RefreshToken == "" {
+		return fmt.Errorf("access token expired and no refresh token is available: %w", ErrNotAuthorized)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", string(general.RefreshToken))
+	form.Set("refresh_token", s.token.RefreshToken)
+
+	token, err := s.requestToken(ctx, form)
+	if err != nil {
+		return fmt.Errorf("failed to refresh access token: %w", err)
+	}
+
+	// Bokio may omit the refresh token when it has not been rotated
+	if token.RefreshToken == "" {
+		token.RefreshToken = s.token.RefreshToken
+	}
+
+	return s.setLocked(token)
+}
+
+// Exchange trades an authorization code for a token and stores it
+func (s *oauthTokenSource) Exchange(ctx context.Context, code string) (*Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", string(general.AuthorizationCode))
+	form.Set("code", code)
+	form.Set("redirect_uri", s.redirectURL)
+
+	token, err := s.requestToken(ctx, form)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.setLocked(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// setLocked replaces the current token and persists it. Callers must hold s.mu.
+func (s *oauthTokenSource) setLocked(token *Token) error {
+	s.token = token
+	if err := s.store.Save(token); err != nil {
+		return fmt.Errorf("failed to persist token: %w", err)
+	}
+	return nil
+}
+
+// requestToken calls the /token endpoint with the given form values.
+// RequestTokenWithFormdataBody cannot encode the grant-specific fields of the
+// generated union type, so the form is encoded here instead.
+func (s *tokenEndpoint) requestToken(ctx context.Context, form url.Values) (*Token, error) {
+	resp, err := s.client.RequestTokenWithBody(ctx,
+		"application/x-www-form-urlencoded",
+		strings.NewReader(form.Encode()),
+		func(ctx context.Context, req *http.Request) error {
+			req.SetBasicAuth(s.clientID, s.clientSecret)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			return nil, fmt.Errorf("token endpoint returned %s: %s", oauthErr.Error, oauthErr.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var tokenResp general.Token
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	return newToken(&tokenResp, s.now())
+}
+
+// clientCredentialsTokenSource mints tokens with the client_credentials grant.
+// Such tokens carry no refresh token, so a new one is requested on expiry.
+type clientCredentialsTokenSource struct {
+	*tokenEndpoint
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// newClientCredentialsTokenSource creates a token source for machine-to-machine access
+func newClientCredentialsTokenSource(config *Config) (*clientCredentialsTokenSource, error) {
+	if config.ClientSecret == "" {
+		return nil, fmt.Errorf("BOKIO_CLIENT_SECRET is required for the client_credentials grant")
+	}
+
+	endpoint, err := newTokenEndpoint(config)
+	if err != nil {
+		return nil, err
+	}
+	return &clientCredentialsTokenSource{tokenEndpoint: endpoint}, nil
+}
+
+// Token implements TokenSource
+func (s *clientCredentialsTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil || s.token.expiresWithin(refreshSkew, s.now()) {
+		if err := s.mintLocked(ctx); err != nil {
+			return "", err
+		}
+	}
+	return s.token.AccessToken, nil
+}
+
+// Refresh implements TokenSource
+func (s *clientCredentialsTokenSource) Refresh(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.mintLocked(ctx); err != nil {
+		return "", err
+	}
+	return s.token.AccessToken, nil
+}
+
+// current returns a copy of the cached token, or nil
+func (s *clientCredentialsTokenSource) current() *Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		return nil
+	}
+	token := *s.token
+	return &token
+}
+
+// mintLocked requests a new token. Callers must hold s.mu.
+func (s *clientCredentialsTokenSource) mintLocked(ctx context.Context) error {
+	form := url.Values{}
+	form.Set("grant_type", string(general.ClientCredentials))
+
+	token, err := s.requestToken(ctx, form)
+	if err != nil {
+		return fmt.Errorf("failed to obtain client credentials token: %w", err)
+	}
+	s.token = token
+	return nil
+}
+
+// AuthorizationURL builds the URL the user visits to grant access
+func (s *oauthTokenSource) AuthorizationURL(server, state string) (string, error) {
+	clientID, err := uuid.Parse(s.clientID)
+	if err != nil {
+		return "", fmt.Errorf("BOKIO_CLIENT_ID must be a UUID: %w", err)
+	}
+
+	params := &general.AuthorizeParams{
+		ResponseType: general.Code,
+		ClientId:     clientID,
+		RedirectUri:  s.redirectURL,
+		State:        state,
+	}
+	if s.scope != "" {
+		params.Scope = &s.scope
+	}
+
+	req, err := general.NewAuthorizeRequest(server, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to build authorization URL: %w", err)
+	}
+	return req.URL.String(), nil
+}
+
+// StartAuthorization starts a local callback listener on the redirect URL and
+// returns the URL the user must open. The returned channel receives the
+// outcome once the callback has been handled or the listener times out.
+func (s *oauthTokenSource) StartAuthorization(ctx context.Context, server string) (string, <-chan error, error) {
+	redirect, err := url.Parse(s.redirectURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid redirect URL: %w", err)
+	}
+	if redirect.Scheme != "http" || redirect.Host == "" {
+		return "", nil, fmt.Errorf("redirect URL must be a local http:// address to receive the callback, got %q", s.redirectURL)
+	}
+
+	state, err := randomState()
+	if err != nil {
+		return "", nil, err
+	}
+
+	authURL, err := s.AuthorizationURL(server, state)
+	if err != nil {
+		return "", nil, err
+	}
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start callback listener on %s: %w", redirect.Host, err)
+	}
+
+	done := make(chan error, 1)
+	var once sync.Once
+	finish := func(err error) {
+		once.Do(func() { done <- err })
+	}
+
+	path := redirect.Path
+	if path == "" {
+		path = "/"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != state {
+			http.Error(w, "Invalid state parameter", http.StatusBadRequest)
+			return
+		}
+
+		if errCode := query.Get("error"); errCode != "" {
+			http.Error(w, "Authorization failed: "+errCode, http.StatusBadRequest)
+			finish(fmt.Errorf("authorization denied: %s %s", errCode, query.Get("error_description")))
+			return
+		}
+
+		code := query.Get("code")
+		if code == "" {
+			http.Error(w, "Missing authorization code", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := s.Exchange(r.Context(), code); err != nil {
+			http.Error(w, "Token exchange failed", http.StatusBadGateway)
+			finish(err)
+			return
+		}
+
+		_, _ = io.WriteString(w, "Bokio authorization complete. You can close this window.")
+		finish(nil)
+	})
+
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			finish(fmt.Errorf("callback listener failed: %w", err))
+		}
+	}()
+
+	result := make(chan error, 1)
+	go func() {
+		var err error
+		select {
+		case err = <-done:
+		case <-time.After(authorizationTimeout):
+			err = errors.New("timed out waiting for authorization callback")
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil {
+			slog.Warn("Failed to stop OAuth2 callback listener", "error", shutdownErr)
+		}
+		result <- err
+	}()
+
+	return authURL, result, nil
+}
+
+// randomState returns an unguessable value for the OAuth2 state parameter
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,190 @@
+package bokio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testClientID = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+
+// newTestTokenServer returns a server whose /token endpoint issues numbered access tokens
+func newTestTokenServer(t *testing.T, issued *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			user, pass, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, testClientID, user)
+			assert.Equal(t, "secret", pass)
+			require.NoError(t, r.ParseForm())
+
+			n := atomic.AddInt32(issued, 1)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  fmt.Sprintf("access-%d", n),
+				"refresh_token": fmt.Sprintf("refresh-%d", n),
+				"token_type":    "bearer",
+				"expires_in":    3600,
+			})
+		default:
+			if r.Header.Get("Authorization") != fmt.Sprintf("Bearer access-%d", atomic.LoadInt32(issued)) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+}
+
+func newTestOAuthConfig(t *testing.T, baseURL string) *Config {
+	return &Config{
+		BaseURL:      baseURL,
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		TokenFile:    filepath.Join(t.TempDir(), "token.json"),
+	}
+}
+
+func TestFileTokenStore(t *testing.T) {
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "nested", "token.json"))
+
+	token, err := store.Load()
+	require.NoError(t, err)
+	assert.Nil(t, token)
+
+	want := &Token{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		TokenType:    "bearer",
+		Expiry:       time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.Save(want))
+
+	got, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestOAuthExchangeAndRefresh(t *testing.T) {
+	var issued int32
+	server := newTestTokenServer(t, &issued)
+	defer server.Close()
+
+	config := newTestOAuthConfig(t, server.URL)
+	client, err := NewAuthClient(config)
+	require.NoError(t, err)
+	assert.True(t, client.UsesOAuth())
+	assert.False(t, client.IsAuthenticated())
+
+	_, err = client.oauth.Token(context.Background())
+	assert.ErrorIs(t, err, ErrNotAuthorized)
+
+	token, err := client.oauth.Exchange(context.Background(), "auth-code")
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+	assert.True(t, client.IsAuthenticated())
+
+	// The token is persisted and picked up by a new client
+	reloadedConfig := *config
+	reloaded, err := NewAuthClient(&reloadedConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", reloaded.GetToken())
+
+	// Tokens close to expiry are refreshed before use
+	client.oauth.now = func() time.Time { return time.Now().Add(time.Hour) }
+	access, err := client.oauth.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", access)
+}
+
+func TestOAuthRetriesOnceOnUnauthorized(t *testing.T) {
+	var issued int32
+	server := newTestTokenServer(t, &issued)
+	defer server.Close()
+
+	client, err := NewAuthClient(newTestOAuthConfig(t, server.URL))
+	require.NoError(t, err)
+
+	_, err = client.oauth.Exchange(context.Background(), "auth-code")
+	require.NoError(t, err)
+
+	// Simulate the server revoking the current access token
+	client.oauth.token.AccessToken = "revoked"
+
+	httpClient := &authenticatedHTTPClient{source: client.oauth}
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/connections", nil)
+	require.NoError(t, err)
+
+	resp, err := httpClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "access-2", client.GetToken())
+}
+
+func TestClientCredentialsTokenSource(t *testing.T) {
+	var issued int32
+	server := newTestTokenServer(t, &issued)
+	defer server.Close()
+
+	config := newTestOAuthConfig(t, server.URL)
+	config.GrantType = "client_credentials"
+
+	client, err := NewAuthClient(config)
+	require.NoError(t, err)
+	assert.True(t, client.UsesClientCredentials())
+	assert.Nil(t, client.CurrentToken())
+
+	source := client.tokens.(*clientCredentialsTokenSource)
+
+	// The first request mints a token, later requests reuse the cached one
+	access, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", access)
+
+	access, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", access)
+
+	// Once the token is about to expire a new one is minted
+	source.now = func() time.Time { return time.Now().Add(time.Hour) }
+	access, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", access)
+
+	_, _, err = client.StartAuthorization(context.Background())
+	assert.Error(t, err)
+}
+
+func TestClientCredentialsRequiresSecret(t *testing.T) {
+	config := newTestOAuthConfig(t, "https://api.bokio.se")
+	config.GrantType = "client_credentials"
+	config.ClientSecret = ""
+
+	_, err := NewAuthClient(config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "BOKIO_CLIENT_SECRET is required")
+}
+
+func TestOAuthAuthorizationURL(t *testing.T) {
+	client, err := NewAuthClient(newTestOAuthConfig(t, "https://api.bokio.se"))
+	require.NoError(t, err)
+
+	authURL, err := client.oauth.AuthorizationURL("https://api.bokio.se", "state-123")
+	require.NoError(t, err)
+	assert.Contains(t, authURL, "https://api.bokio.se/authorize?")
+	assert.Contains(t, authURL, "client_id="+testClientID)
+	assert.Contains(t, authURL, "state=state-123")
+	assert.Contains(t, authURL, "response_type=code")
+}
@@ -0,0 +1,48 @@
+package bokio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// listPage is the envelope of a page of a list endpoint
+type listPage[T any] struct {
+	Items      []T    `json:"items"`
+	TotalPages *int32 `json:"totalPages"`
+}
+
+// pageRequest requests one page of a list endpoint
+type pageRequest func(page int32) (*http.Response, error)
+
+// allPages reads every page of a list endpoint, from the first until the
+// page count reported by the API, and decodes the items as T. what names
+// the listed resources in errors, e.g. "invoices".
+func allPages[T any](what string, fetch pageRequest) ([]T, error) {
+	var items []T
+	for page := int32(1); ; page++ {
+		resp, err := fetch(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", what, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s response: %w", what, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to list %s: %w", what, NewAPIError(resp.StatusCode, body))
+		}
+
+		var list listPage[T]
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", what, err)
+		}
+		items = append(items, list.Items...)
+
+		if list.TotalPages == nil || page >= *list.TotalPages {
+			return items, nil
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package bokio
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllPages(t *testing.T) {
+	respond := func(status int, body string) *http.Response {
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	var requested []int32
+	items, err := allPages[string]("things", func(page int32) (*http.Response, error) {
+		requested = append(requested, page)
+		if page == 1 {
+			return respond(http.StatusOK, `{"totalPages": 2, "items": ["a", "b"]}`), nil
+		}
+		return respond(http.StatusOK, `{"totalPages": 2, "items": ["c"]}`), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, items)
+	assert.Equal(t, []int32{1, 2}, requested)
+
+	// Without a page count only the first page is read
+	items, err = allPages[string]("things", func(page int32) (*http.Response, error) {
+		return respond(http.StatusOK, `{"items": ["a"]}`), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, items)
+
+	_, err = allPages[string]("things", func(page int32) (*http.Response, error) {
+		return respond(http.StatusNotFound, `{"code": "not-found", "message": "Company not found"}`), nil
+	})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.ErrorContains(t, err, "failed to list things")
+
+	_, err = allPages[string]("things", func(page int32) (*http.Response, error) {
+		return nil, errors.New("connection reset")
+	})
+	assert.EqualError(t, err, "failed to list things: connection reset")
+
+	_, err = allPages[string]("things", func(page int32) (*http.Response, error) {
+		return respond(http.StatusOK, `{"items": [1]}`), nil
+	})
+	assert.ErrorContains(t, err, "failed to decode things")
+}
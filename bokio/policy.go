@@ -0,0 +1,288 @@
+package bokio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyCurrency is the currency of policy amount caps
+const PolicyCurrency = "SEK"
+
+// ErrPolicyDenied is returned for tool calls the policy does not allow
+var ErrPolicyDenied = errors.New("denied by policy")
+
+// PolicyRule restricts tool calls. A call must pass every rule that applies
+// to it: the policy's top-level rule and the rule of the call's company.
+type PolicyRule struct {
+	// Allow lists tool name patterns (e.g. "bokio_invoices_*"); when set,
+	// only matching tools may be called
+	Allow []string `yaml:"allow,omitempty"`
+	// Deny lists tool name patterns that may not be called
+	Deny []string `yaml:"deny,omitempty"`
+	// AmountCaps maps tool name patterns to the largest amount in SEK,
+	// including VAT, a matching call may book or invoice
+	AmountCaps map[string]float64 `yaml:"amount_caps,omitempty"`
+	// ClosedPeriods are date ranges calls may not book into
+	ClosedPeriods []ClosedPeriod `yaml:"closed_periods,omitempty"`
+}
+
+// ClosedPeriod is an inclusive date range, open ended when From or To is empty
+type ClosedPeriod struct {
+	From   string `yaml:"from,omitempty"`
+	To     string `yaml:"to,omitempty"`
+	Reason string `yaml:"reason,omitempty"`
+
+	from, to time.Time
+}
+
+// policyFile is the on-disk format of the policy
+type policyFile struct {
+	PolicyRule `yaml:",inline"`
+	// Companies holds additional rules by company ID or tenant alias
+	Companies map[string]PolicyRule `yaml:"companies,omitempty"`
+}
+
+// PolicyCall describes a tool call for Policy.Check
+type PolicyCall struct {
+	Tool string
+	// CompanyID is the company the call acts on, empty for tools without one
+	CompanyID string
+	// Amount is the total the call books or invoices, nil when it has none
+	Amount *float64
+	// Currency is the currency of Amount; empty means SEK
+	Currency string
+	// Dates are the booking dates of the call
+	Dates []time.Time
+}
+
+// Policy decides which tools may be called, per company, and bounds the
+// amounts and dates they may book. A nil Policy allows everything.
+type Policy struct {
+	defaults  PolicyRule
+	companies map[string]PolicyRule
+}
+
+// NewPolicy validates rules and builds a policy. Company rules are keyed by
+// company ID or by an alias resolved through tenants.
+func NewPolicy(defaults PolicyRule, companies map[string]PolicyRule, tenants *TenantRegistry) (*Policy, error) {
+	policy := &Policy{companies: make(map[string]PolicyRule, len(companies))}
+
+	if err := defaults.validate(); err != nil {
+		return nil, err
+	}
+	policy.defaults = defaults
+
+	for ref, rule := range companies {
+		id, err := resolveCompanyID(ref, tenants)
+		if err != nil {
+			return nil, fmt.Errorf("policy companies: %w", err)
+		}
+		if _, exists := policy.companies[id]; exists {
+			return nil, fmt.Errorf("policy companies: company %s is listed twice", id)
+		}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("policy company %s: %w", ref, err)
+		}
+		policy.companies[id] = rule
+	}
+	return policy, nil
+}
+
+// LoadPolicy reads a YAML (or JSON) policy file
+func LoadPolicy(path string, tenants *TenantRegistry) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var file policyFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", path, err)
+	}
+
+	policy, err := NewPolicy(file.PolicyRule, file.Companies, tenants)
+	if err != nil {
+		return nil, fmt.Errorf("policy file %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Visible reports whether tool may be called at all for company, which is
+// empty for clients that are not restricted to one company. Tools that are
+// not visible are left out of tools/list.
+func (p *Policy) Visible(tool, company string) bool {
+	if p == nil {
+		return true
+	}
+	for _, rule := range p.rules(company) {
+		if rule.permits(tool) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns an error wrapping ErrPolicyDenied when call is not allowed
+func (p *Policy) Check(call PolicyCall) error {
+	if p == nil {
+		return nil
+	}
+	for _, rule := range p.rules(call.CompanyID) {
+		if err := rule.check(call); err != nil {
+			if call.CompanyID != "" {
+				return fmt.Errorf("%w: %s for company %s: %w", ErrPolicyDenied, call.Tool, call.CompanyID, err)
+			}
+			return fmt.Errorf("%w: %s: %w", ErrPolicyDenied, call.Tool, err)
+		}
+	}
+	return nil
+}
+
+// rules returns the rules applying to company
+func (p *Policy) rules(company string) []PolicyRule {
+	rules := []PolicyRule{p.defaults}
+	if rule, ok := p.companies[company]; ok {
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// validate checks the patterns and parses the closed periods
+func (r *PolicyRule) validate() error {
+	patterns := append(append([]string{}, r.Allow...), r.Deny...)
+	for pattern, limit := range r.AmountCaps {
+		if limit < 0 {
+			return fmt.Errorf("amount cap for %q must not be negative", pattern)
+		}
+		patterns = append(patterns, pattern)
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+
+	for i := range r.ClosedPeriods {
+		period := &r.ClosedPeriods[i]
+		var err error
+		if period.From == "" && period.To == "" {
+			return fmt.Errorf("closed period %d: from or to is required", i+1)
+		}
+		if period.From != "" {
+			if period.from, err = time.Parse(DateLayout, period.From); err != nil {
+				return fmt.Errorf("closed period %d: invalid from date %q (use YYYY-MM-DD)", i+1, period.From)
+			}
+		}
+		if period.To != "" {
+			if period.to, err = time.Parse(DateLayout, period.To); err != nil {
+				return fmt.Errorf("closed period %d: invalid to date %q (use YYYY-MM-DD)", i+1, period.To)
+			}
+		}
+		if period.From != "" && period.To != "" && period.to.Before(period.from) {
+			return fmt.Errorf("closed period %d: to %s is before from %s", i+1, period.To, period.From)
+		}
+	}
+	return nil
+}
+
+// permits checks the allow and deny lists
+func (r *PolicyRule) permits(tool string) error {
+	if len(r.Allow) > 0 && matchTool(r.Allow, tool) == "" {
+		return errors.New("tool is not in the allow list")
+	}
+	if pattern := matchTool(r.Deny, tool); pattern != "" {
+		return fmt.Errorf("tool matches deny pattern %q", pattern)
+	}
+	return nil
+}
+
+// check applies the rule to call
+func (r *PolicyRule) check(call PolicyCall) error {
+	if err := r.permits(call.Tool); err != nil {
+		return err
+	}
+
+	if call.Amount != nil {
+		patterns := make([]string, 0, len(r.AmountCaps))
+		for pattern := range r.AmountCaps {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, call.Tool); !matched {
+				continue
+			}
+			limit := r.AmountCaps[pattern]
+			if call.Currency != "" && call.Currency != PolicyCurrency {
+				return fmt.Errorf("amount %.2f %s cannot be checked against the %s cap of %q",
+					*call.Amount, call.Currency, PolicyCurrency, pattern)
+			}
+			if *call.Amount > limit {
+				return fmt.Errorf("amount %.2f %s exceeds the cap of %.2f %s for %q",
+					*call.Amount, PolicyCurrency, limit, PolicyCurrency, pattern)
+			}
+		}
+	}
+
+	for _, date := range call.Dates {
+		for _, period := range r.ClosedPeriods {
+			if period.contains(date) {
+				err := fmt.Errorf("date %s is in the closed period %s", date.Format(DateLayout), period)
+				if period.Reason != "" {
+					err = fmt.Errorf("%w (%s)", err, period.Reason)
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// contains reports whether date falls within the period
+func (p ClosedPeriod) contains(date time.Time) bool {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if p.From != "" && day.Before(p.from) {
+		return false
+	}
+	if p.To != "" && day.After(p.to) {
+		return false
+	}
+	return true
+}
+
+// String renders the period as from..to
+func (p ClosedPeriod) String() string {
+	return p.From + ".." + p.To
+}
+
+// matchTool returns the first pattern matching tool, or an empty string
+func matchTool(patterns []string, tool string) string {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, tool); matched {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// resolveCompanyID returns the company ID for a company ID or tenant alias
+func resolveCompanyID(ref string, tenants *TenantRegistry) (string, error) {
+	if tenant, ok := tenants.Resolve(ref); ok {
+		return tenant.ID, nil
+	}
+	if id, err := uuid.Parse(ref); err == nil {
+		return id.String(), nil
+	}
+	return "", fmt.Errorf("unknown company %q (use a company ID or an alias from the tenants file)", ref)
+}
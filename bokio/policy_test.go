@@ -0,0 +1,111 @@
+package bokio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicyFile = `
+deny: [bokio_*_delete]
+amount_caps:
+  bokio_invoices_*: 50000
+closed_periods:
+  - to: 2023-12-31
+    reason: FY 2023 is closed
+companies:
+  acme:
+    allow: [bokio_invoices_*, bokio_customers_*]
+  ` + testCompanyB + `:
+    deny: [bokio_journal_entries_create]
+    amount_caps:
+      bokio_invoices_create: 10000
+`
+
+func testPolicy(t *testing.T) *Policy {
+	t.Helper()
+	tenants, err := NewTenantRegistry([]Tenant{{ID: testCompanyA, Alias: "acme"}}, "")
+	require.NoError(t, err)
+	policy, err := LoadPolicy(writeConfigFile(t, testPolicyFile), tenants)
+	require.NoError(t, err)
+	return policy
+}
+
+func TestPolicyVisible(t *testing.T) {
+	policy := testPolicy(t)
+
+	assert.True(t, policy.Visible("bokio_journal_entries_create", ""))
+	assert.False(t, policy.Visible("bokio_customers_delete", ""))
+
+	// Company rules only hide tools from clients restricted to the company
+	assert.False(t, policy.Visible("bokio_journal_entries_create", testCompanyA))
+	assert.True(t, policy.Visible("bokio_invoices_create", testCompanyA))
+	assert.False(t, policy.Visible("bokio_journal_entries_create", testCompanyB))
+	assert.True(t, policy.Visible("bokio_journal_entries_list", testCompanyB))
+
+	var none *Policy
+	assert.True(t, none.Visible("bokio_customers_delete", testCompanyA))
+	assert.NoError(t, none.Check(PolicyCall{Tool: "bokio_customers_delete"}))
+}
+
+func TestPolicyCheck(t *testing.T) {
+	policy := testPolicy(t)
+	amount := func(v float64) *float64 { return &v }
+	date := func(s string) []time.Time {
+		d, err := time.Parse(DateLayout, s)
+		require.NoError(t, err)
+		return []time.Time{d}
+	}
+
+	tests := []struct {
+		name     string
+		call     PolicyCall
+		errorMsg string
+	}{
+		{"allowed", PolicyCall{Tool: "bokio_invoices_create", CompanyID: testCompanyA, Amount: amount(50000), Dates: date("2024-01-01")}, ""},
+		{"denied tool", PolicyCall{Tool: "bokio_items_delete"}, `deny pattern "bokio_*_delete"`},
+		{"outside company allow list", PolicyCall{Tool: "bokio_journal_entries_list", CompanyID: testCompanyA}, "not in the allow list"},
+		{"company deny", PolicyCall{Tool: "bokio_journal_entries_create", CompanyID: testCompanyB}, "for company " + testCompanyB},
+		{"amount cap", PolicyCall{Tool: "bokio_invoices_update", Amount: amount(50000.01)}, "exceeds the cap of 50000.00 SEK"},
+		{"stricter company cap", PolicyCall{Tool: "bokio_invoices_create", CompanyID: testCompanyB, Amount: amount(12000)}, "cap of 10000.00 SEK"},
+		{"cap does not apply", PolicyCall{Tool: "bokio_journal_entries_create", Amount: amount(1e6)}, ""},
+		{"foreign currency", PolicyCall{Tool: "bokio_invoices_create", Amount: amount(100), Currency: "EUR"}, "cannot be checked against the SEK cap"},
+		{"closed period", PolicyCall{Tool: "bokio_journal_entries_create", Dates: date("2023-12-31")}, "closed period ..2023-12-31 (FY 2023 is closed)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.call)
+			if tt.errorMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrPolicyDenied)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		errorMsg string
+	}{
+		{"unknown key", "denied: [x]", "field denied not found"},
+		{"bad pattern", "deny: ['bokio_[']", "invalid tool pattern"},
+		{"negative cap", "amount_caps: {bokio_invoices_create: -1}", "must not be negative"},
+		{"bad date", "closed_periods: [{from: 2024-13-01}]", "invalid from date"},
+		{"empty period", "closed_periods: [{reason: x}]", "from or to is required"},
+		{"reversed period", "closed_periods: [{from: 2024-02-01, to: 2024-01-01}]", "is before from"},
+		{"unknown company", "companies: {globex: {deny: ['*']}}", `unknown company "globex"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPolicy(writeConfigFile(t, tt.content), nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
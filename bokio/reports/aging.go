@@ -0,0 +1,251 @@
+package reports
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+)
+
+// defaultCurrency is the currency of invoices that name none
+const defaultCurrency = "SEK"
+
+// ReceivablesAging buckets the outstanding balance of unpaid invoices by days
+// past due, per customer and currency
+type ReceivablesAging struct {
+	// Date is the day the days past due are counted to
+	Date      string            `json:"date"`
+	Customers []AgingCustomer   `json:"customers"`
+	Totals    []AgingTotal      `json:"totals"`
+	Digest    []ChaseSuggestion `json:"digest"`
+}
+
+// AgingBuckets splits an outstanding balance by days past due. Invoices due
+// today count as not due.
+type AgingBuckets struct {
+	NotDue     float64 `json:"not_due"`
+	Days0To30  float64 `json:"days_0_30"`
+	Days31To60 float64 `json:"days_31_60"`
+	Days61To90 float64 `json:"days_61_90"`
+	Over90     float64 `json:"over_90"`
+	Total      float64 `json:"total"`
+}
+
+// AgingCustomer is the outstanding balance of a customer in one currency
+type AgingCustomer struct {
+	CustomerID string         `json:"customer_id,omitempty"`
+	Customer   string         `json:"customer"`
+	Currency   string         `json:"currency"`
+	Buckets    AgingBuckets   `json:"buckets"`
+	Invoices   []AgingInvoice `json:"invoices"`
+}
+
+// AgingTotal is the outstanding balance of all customers in one currency
+type AgingTotal struct {
+	Currency string       `json:"currency"`
+	Buckets  AgingBuckets `json:"buckets"`
+}
+
+// AgingInvoice is an unpaid invoice counted in the aging
+type AgingInvoice struct {
+	ID          string  `json:"id,omitempty"`
+	Number      string  `json:"invoice_number,omitempty"`
+	InvoiceDate string  `json:"invoice_date"`
+	DueDate     string  `json:"due_date"`
+	Status      string  `json:"status,omitempty"`
+	Total       float64 `json:"total"`
+	Paid        float64 `json:"paid"`
+	Outstanding float64 `json:"outstanding"`
+	// DaysPastDue is zero or negative for invoices that are not due yet
+	DaysPastDue int `json:"days_past_due"`
+	// rate converts the invoice currency to SEK
+	rate float64
+}
+
+// ChaseSuggestion is a customer with overdue invoices in the digest, in
+// the order they should be chased
+type ChaseSuggestion struct {
+	Priority   int    `json:"priority"`
+	CustomerID string `json:"customer_id,omitempty"`
+	Customer   string `json:"customer"`
+	Currency   string `json:"currency"`
+	// Overdue is the outstanding balance past its due date
+	Overdue float64 `json:"overdue"`
+	// OverdueSEK is Overdue converted with the invoices' currency rates
+	OverdueSEK float64 `json:"overdue_sek"`
+	// DaysPastDue is the age of the oldest overdue invoice
+	DaysPastDue int      `json:"days_past_due"`
+	Invoices    []string `json:"invoices"`
+	// PartlyPaid is set when an overdue invoice is underpaid
+	PartlyPaid bool `json:"partly_paid,omitempty"`
+}
+
+// add counts an amount in the bucket of daysPastDue
+func (b *AgingBuckets) add(amount float64, daysPastDue int) {
+	switch {
+	case daysPastDue <= 0:
+		b.NotDue = round(b.NotDue + amount)
+	case daysPastDue <= 30:
+		b.Days0To30 = round(b.Days0To30 + amount)
+	case daysPastDue <= 60:
+		b.Days31To60 = round(b.Days31To60 + amount)
+	case daysPastDue <= 90:
+		b.Days61To90 = round(b.Days61To90 + amount)
+	default:
+		b.Over90 = round(b.Over90 + amount)
+	}
+	b.Total = round(b.Total + amount)
+}
+
+// Overdue returns the part of the balance past its due date
+func (b AgingBuckets) Overdue() float64 {
+	return round(b.Total - b.NotDue)
+}
+
+// OutstandingStatuses are the invoice statuses with money left to collect;
+// drafts are not sent and credit notes are settled by crediting
+var OutstandingStatuses = []company.InvoiceStatus{company.Published, company.Overdue, company.Underpaid}
+
+// agingBand groups days past due into the buckets for ranking the digest
+func agingBand(daysPastDue int) int {
+	switch {
+	case daysPastDue > 90:
+		return 3
+	case daysPastDue > 60:
+		return 2
+	case daysPastDue > 30:
+		return 1
+	}
+	return 0
+}
+
+// Aging computes the receivables aging of invoices at date. Invoices that are
+// published, overdue or underpaid with a balance left are bucketed by days
+// past due: not due yet, 0–30, 31–60, 61–90 and over 90 days. Amounts stay
+// in the invoice currency, so customers and totals are split by currency.
+//
+// The digest lists customers with overdue balances, those with the oldest
+// debt first and, within the same bucket, the largest balance in SEK first.
+func Aging(invoices []company.Invoice, date time.Time) *ReceivablesAging {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	report := &ReceivablesAging{
+		Date:      day.Format(dateLayout),
+		Customers: []AgingCustomer{},
+		Totals:    []AgingTotal{},
+		Digest:    []ChaseSuggestion{},
+	}
+
+	type customerKey struct{ customer, currency string }
+	customers := make(map[customerKey]*AgingCustomer)
+	totals := make(map[string]*AgingTotal)
+	for _, invoice := range invoices {
+		if invoice.Status == nil || !slices.Contains(OutstandingStatuses, *invoice.Status) || invoice.TotalAmount == nil {
+			continue
+		}
+		row := AgingInvoice{
+			InvoiceDate: invoice.InvoiceDate.Format(dateLayout),
+			DueDate:     invoice.DueDate.Format(dateLayout),
+			Status:      string(*invoice.Status),
+			Total:       *invoice.TotalAmount,
+			rate:        1,
+		}
+		if invoice.PaidAmount != nil {
+			row.Paid = *invoice.PaidAmount
+		}
+		row.Outstanding = round(row.Total - row.Paid)
+		if row.Outstanding <= 0 {
+			continue
+		}
+		due := time.Date(invoice.DueDate.Year(), invoice.DueDate.Month(), invoice.DueDate.Day(), 0, 0, 0, 0, time.UTC)
+		row.DaysPastDue = int(day.Sub(due).Hours() / 24)
+		if invoice.Id != nil {
+			row.ID = invoice.Id.String()
+		}
+		if invoice.InvoiceNumber != nil {
+			row.Number = *invoice.InvoiceNumber
+		}
+		if invoice.CurrencyRate != nil && *invoice.CurrencyRate > 0 {
+			row.rate = *invoice.CurrencyRate
+		}
+
+		currency := defaultCurrency
+		if invoice.Currency != nil && *invoice.Currency != "" {
+			currency = *invoice.Currency
+		}
+		var id, name string
+		if ref := invoice.CustomerRef; ref != nil {
+			if ref.Id != nil {
+				id = ref.Id.String()
+			}
+			if ref.Name != nil {
+				name = *ref.Name
+			}
+		}
+		key := customerKey{id, currency}
+		if id == "" {
+			key.customer = name
+		}
+
+		customer, ok := customers[key]
+		if !ok {
+			customer = &AgingCustomer{CustomerID: id, Customer: name, Currency: currency}
+			customers[key] = customer
+		}
+		customer.Buckets.add(row.Outstanding, row.DaysPastDue)
+		customer.Invoices = append(customer.Invoices, row)
+
+		total, ok := totals[currency]
+		if !ok {
+			total = &AgingTotal{Currency: currency}
+			totals[currency] = total
+		}
+		total.Buckets.add(row.Outstanding, row.DaysPastDue)
+	}
+
+	for _, customer := range customers {
+		// Oldest invoices first
+		slices.SortFunc(customer.Invoices, func(a, b AgingInvoice) int {
+			return cmp.Or(cmp.Compare(b.DaysPastDue, a.DaysPastDue), cmp.Compare(a.Number, b.Number))
+		})
+		report.Customers = append(report.Customers, *customer)
+	}
+	slices.SortFunc(report.Customers, func(a, b AgingCustomer) int {
+		return cmp.Or(cmp.Compare(a.Customer, b.Customer), cmp.Compare(a.Currency, b.Currency), cmp.Compare(a.CustomerID, b.CustomerID))
+	})
+	for _, total := range totals {
+		report.Totals = append(report.Totals, *total)
+	}
+	slices.SortFunc(report.Totals, func(a, b AgingTotal) int { return cmp.Compare(a.Currency, b.Currency) })
+
+	for _, customer := range report.Customers {
+		if customer.Buckets.Overdue() <= 0 {
+			continue
+		}
+		chase := ChaseSuggestion{
+			CustomerID: customer.CustomerID,
+			Customer:   customer.Customer,
+			Currency:   customer.Currency,
+			Overdue:    customer.Buckets.Overdue(),
+			Invoices:   []string{},
+		}
+		for _, invoice := range customer.Invoices {
+			if invoice.DaysPastDue <= 0 {
+				continue
+			}
+			chase.OverdueSEK += invoice.Outstanding * invoice.rate
+			chase.DaysPastDue = max(chase.DaysPastDue, invoice.DaysPastDue)
+			chase.Invoices = append(chase.Invoices, cmp.Or(invoice.Number, invoice.ID))
+			chase.PartlyPaid = chase.PartlyPaid || invoice.Paid > 0
+		}
+		chase.OverdueSEK = round(chase.OverdueSEK)
+		report.Digest = append(report.Digest, chase)
+	}
+	slices.SortStableFunc(report.Digest, func(a, b ChaseSuggestion) int {
+		return cmp.Or(cmp.Compare(agingBand(b.DaysPastDue), agingBand(a.DaysPastDue)), cmp.Compare(b.OverdueSEK, a.OverdueSEK))
+	})
+	for i := range report.Digest {
+		report.Digest[i].Priority = i + 1
+	}
+	return report
+}
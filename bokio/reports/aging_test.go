@@ -0,0 +1,70 @@
+package reports
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAging(t *testing.T) {
+	var invoices []company.Invoice
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"id": "44444444-0000-0000-0000-000000000001", "invoiceNumber": "1001", "invoiceDate": "2024-01-02", "dueDate": "2024-02-01", "status": "overdue", "totalAmount": 12500, "lineItems": [],
+		 "customerRef": {"id": "55555555-0000-0000-0000-000000000001", "name": "Acme AB"}},
+		{"id": "44444444-0000-0000-0000-000000000002", "invoiceNumber": "1002", "invoiceDate": "2024-04-01", "dueDate": "2024-05-01", "status": "underpaid", "totalAmount": 5000, "paidAmount": 2000, "lineItems": [],
+		 "customerRef": {"id": "55555555-0000-0000-0000-000000000001", "name": "Acme AB"}},
+		{"invoiceNumber": "1003", "invoiceDate": "2024-05-01", "dueDate": "2024-05-31", "status": "published", "totalAmount": 800, "lineItems": [],
+		 "customerRef": {"id": "55555555-0000-0000-0000-000000000001", "name": "Acme AB"}},
+		{"invoiceNumber": "1004", "invoiceDate": "2024-03-15", "dueDate": "2024-04-14", "status": "overdue", "currency": "EUR", "currencyRate": 11.5, "totalAmount": 3000, "lineItems": [],
+		 "customerRef": {"id": "55555555-0000-0000-0000-000000000002", "name": "Berlin GmbH"}},
+		{"invoiceNumber": "1005", "invoiceDate": "2024-04-20", "dueDate": "2024-05-20", "status": "overdue", "totalAmount": 20000, "lineItems": [],
+		 "customerRef": {"id": "55555555-0000-0000-0000-000000000003", "name": "Cirkus HB"}},
+		{"invoiceNumber": "1006", "invoiceDate": "2024-04-20", "dueDate": "2024-05-20", "status": "paid", "totalAmount": 900, "paidAmount": 900, "lineItems": []},
+		{"invoiceDate": "2024-05-25", "dueDate": "2024-06-24", "status": "draft", "totalAmount": 400, "lineItems": []},
+		{"invoiceNumber": "1007", "invoiceDate": "2024-04-20", "dueDate": "2024-05-20", "status": "credited", "totalAmount": 700, "lineItems": []},
+		{"invoiceNumber": "1008", "invoiceDate": "2024-04-20", "dueDate": "2024-05-20", "status": "overdue", "totalAmount": 600, "paidAmount": 600, "lineItems": []}
+	]`), &invoices))
+
+	report := Aging(invoices, date(2024, 5, 31))
+	assert.Equal(t, "2024-05-31", report.Date)
+
+	require.Len(t, report.Customers, 3, "paid, draft and credited invoices are left out")
+	acme := report.Customers[0]
+	assert.Equal(t, "Acme AB", acme.Customer)
+	assert.Equal(t, "55555555-0000-0000-0000-000000000001", acme.CustomerID)
+	assert.Equal(t, AgingBuckets{NotDue: 800, Days0To30: 3000, Over90: 12500, Total: 16300}, acme.Buckets, "1003 is due today")
+	require.Len(t, acme.Invoices, 3)
+	assert.Equal(t, []string{"1001", "1002", "1003"}, []string{acme.Invoices[0].Number, acme.Invoices[1].Number, acme.Invoices[2].Number}, "oldest first")
+	assert.Equal(t, 120, acme.Invoices[0].DaysPastDue)
+	assert.Equal(t, 3000.0, acme.Invoices[1].Outstanding)
+	assert.Equal(t, 0, acme.Invoices[2].DaysPastDue)
+
+	assert.Equal(t, []AgingTotal{
+		{Currency: "EUR", Buckets: AgingBuckets{Days31To60: 3000, Total: 3000}},
+		{Currency: "SEK", Buckets: AgingBuckets{NotDue: 800, Days0To30: 23000, Over90: 12500, Total: 36300}},
+	}, report.Totals)
+
+	require.Len(t, report.Digest, 3)
+	assert.Equal(t, ChaseSuggestion{
+		Priority:    1,
+		CustomerID:  "55555555-0000-0000-0000-000000000001",
+		Customer:    "Acme AB",
+		Currency:    "SEK",
+		Overdue:     15500,
+		OverdueSEK:  15500,
+		DaysPastDue: 120,
+		Invoices:    []string{"1001", "1002"},
+		PartlyPaid:  true,
+	}, report.Digest[0])
+	assert.Equal(t, "Berlin GmbH", report.Digest[1].Customer, "47 days overdue ranks before a larger balance 11 days overdue")
+	assert.Equal(t, 34500.0, report.Digest[1].OverdueSEK)
+	assert.Equal(t, "Cirkus HB", report.Digest[2].Customer)
+	assert.Equal(t, 3, report.Digest[2].Priority)
+
+	empty := Aging(nil, date(2024, 5, 31))
+	assert.Empty(t, empty.Customers)
+	assert.Empty(t, empty.Digest)
+}
@@ -0,0 +1,95 @@
+package reports
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// WriteCSV writes the trial balance as CSV with a header row and a closing
+// totals row. Amounts use a decimal point and two decimals.
+func (r *TrialBalance) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"account", "name", "opening_balance", "debit", "credit", "closing_balance"})
+	for _, a := range r.Accounts {
+		out.Write([]string{
+			strconv.Itoa(int(a.Account)), a.Name,
+			formatAmount(a.Opening), formatAmount(a.Debit), formatAmount(a.Credit), formatAmount(a.Closing),
+		})
+	}
+	out.Write([]string{
+		"", "Total",
+		formatAmount(r.Totals.Opening), formatAmount(r.Totals.Debit), formatAmount(r.Totals.Credit), formatAmount(r.Totals.Closing),
+	})
+	out.Flush()
+	return out.Error()
+}
+
+// WriteCSV writes the general ledger as CSV, one row per transaction with
+// the running balance of its account. Each account starts with an opening
+// balance row.
+func (r *GeneralLedger) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"account", "name", "date", "journal_entry_number", "title", "debit", "credit", "balance", "journal_entry_id"})
+	for _, a := range r.Accounts {
+		account := strconv.Itoa(int(a.Account))
+		out.Write([]string{account, a.Name, r.From, "", "Opening balance", "", "", formatAmount(a.Opening), ""})
+		for _, l := range a.Lines {
+			out.Write([]string{
+				account, a.Name, l.Date, l.Number, l.Title,
+				formatAmount(l.Debit), formatAmount(l.Credit), formatAmount(l.Balance), l.JournalEntryID,
+			})
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteCSV writes the statement as CSV in reading order: every line followed
+// by its accounts. The previous column is empty without a comparison period.
+func (s *Statement) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"key", "label", "kind", "account", "name", "amount", "previous"})
+	for _, l := range s.Lines {
+		amount, previous := "", ""
+		if l.Kind != LineHeading {
+			amount, previous = formatAmount(l.Amount), formatOptionalAmount(l.Previous)
+		}
+		out.Write([]string{l.Key, l.Label, l.Kind, "", "", amount, previous})
+		for _, a := range l.Accounts {
+			out.Write([]string{
+				l.Key, l.Label, "account", strconv.Itoa(int(a.Account)), a.Name,
+				formatAmount(a.Amount), formatOptionalAmount(a.Previous),
+			})
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteCSV writes the VAT return as CSV, one row per source row followed by
+// the box total, and box 49 last
+func (r *VATReturn) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"box", "label", "date", "journal_entry_number", "title", "account", "amount", "journal_entry_id"})
+	for _, box := range r.Boxes {
+		for _, s := range box.Sources {
+			out.Write([]string{
+				box.Box, box.Label, s.Date, s.Number, s.Title,
+				strconv.Itoa(int(s.Account)), formatAmount(s.Amount), s.JournalEntryID,
+			})
+		}
+		out.Write([]string{box.Box, box.Label, "", "", "Total", "", strconv.FormatInt(box.Kronor, 10), ""})
+	}
+	out.Write([]string{"49", VATPayableLabel, "", "", "Total", "", strconv.FormatInt(r.Payable, 10), ""})
+	out.Flush()
+	return out.Error()
+}
+
+// formatOptionalAmount renders an amount with two decimals, or nothing
+func formatOptionalAmount(amount *float64) string {
+	if amount == nil {
+		return ""
+	}
+	return formatAmount(*amount)
+}
@@ -0,0 +1,308 @@
+// Package reports builds accounting reports from Bokio journal entries
+package reports
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+)
+
+// dateLayout is the date format of report periods and lines
+const dateLayout = "2006-01-02"
+
+// Period is a date range, both ends inclusive
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+// String renders the period as from – to
+func (p Period) String() string {
+	return p.From.Format(dateLayout) + " – " + p.To.Format(dateLayout)
+}
+
+// Contains reports whether date falls within the period
+func (p Period) Contains(date time.Time) bool {
+	day := date.Format(dateLayout)
+	return p.From.Format(dateLayout) <= day && day <= p.To.Format(dateLayout)
+}
+
+// Ledger holds the input of the reports: the opening balances of the fiscal
+// year and its journal entries up to the end of the report period
+type Ledger struct {
+	// Opening maps accounts to their balance at the start of the fiscal year,
+	// debit positive
+	Opening map[int32]float64
+	// Names maps accounts to their names, where known
+	Names map[int32]string
+	// Entries are the journal entries from the start of the fiscal year.
+	// Entries dated before the report period count towards the opening
+	// balance, entries after it are ignored.
+	Entries []company.JournalEntry
+}
+
+// TrialBalance lists per account the opening balance, the period's debits
+// and credits and the closing balance. Balances are debit positive.
+type TrialBalance struct {
+	From     string             `json:"from"`
+	To       string             `json:"to"`
+	Accounts []TrialBalanceRow  `json:"accounts"`
+	Totals   TrialBalanceTotals `json:"totals"`
+}
+
+// TrialBalanceRow is one account of a trial balance
+type TrialBalanceRow struct {
+	Account int32   `json:"account"`
+	Name    string  `json:"name,omitempty"`
+	Opening float64 `json:"opening_balance"`
+	Debit   float64 `json:"debit"`
+	Credit  float64 `json:"credit"`
+	Closing float64 `json:"closing_balance"`
+}
+
+// TrialBalanceTotals sums the trial balance. Opening and Closing are zero
+// when the books balance.
+type TrialBalanceTotals struct {
+	Opening float64 `json:"opening_balance"`
+	Debit   float64 `json:"debit"`
+	Credit  float64 `json:"credit"`
+	Closing float64 `json:"closing_balance"`
+}
+
+// GeneralLedger lists the period's transactions per account with a running
+// balance
+type GeneralLedger struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Accounts []LedgerAccount `json:"accounts"`
+}
+
+// LedgerAccount is one account of a general ledger
+type LedgerAccount struct {
+	Account int32        `json:"account"`
+	Name    string       `json:"name,omitempty"`
+	Opening float64      `json:"opening_balance"`
+	Lines   []LedgerLine `json:"lines"`
+	Debit   float64      `json:"debit"`
+	Credit  float64      `json:"credit"`
+	Closing float64      `json:"closing_balance"`
+}
+
+// LedgerLine is one journal entry row booked on an account
+type LedgerLine struct {
+	Date           string  `json:"date"`
+	Number         string  `json:"journal_entry_number,omitempty"`
+	Title          string  `json:"title,omitempty"`
+	JournalEntryID string  `json:"journal_entry_id,omitempty"`
+	Debit          float64 `json:"debit"`
+	Credit         float64 `json:"credit"`
+	Balance        float64 `json:"balance"`
+}
+
+// AccountFilter selects the accounts of a report; a zero bound is open
+type AccountFilter struct {
+	From int32
+	To   int32
+}
+
+// Includes reports whether account is within the filter
+func (f AccountFilter) Includes(account int32) bool {
+	return (f.From == 0 || account >= f.From) && (f.To == 0 || account <= f.To)
+}
+
+// line is a journal entry row with the fields of its entry
+type line struct {
+	date    time.Time
+	number  string
+	title   string
+	entryID string
+	account int32
+	debit   float64
+	credit  float64
+}
+
+// lines flattens journal entries into rows, skipping rows without an account
+func lines(entries []company.JournalEntry) []line {
+	var rows []line
+	for _, entry := range entries {
+		if entry.Date == nil || entry.Items == nil {
+			continue
+		}
+		base := line{date: entry.Date.Time}
+		if entry.JournalEntryNumber != nil {
+			base.number = *entry.JournalEntryNumber
+		}
+		if entry.Title != nil {
+			base.title = *entry.Title
+		}
+		if entry.Id != nil {
+			base.entryID = entry.Id.String()
+		}
+		for _, item := range *entry.Items {
+			if item.Account == nil {
+				continue
+			}
+			row := base
+			row.account = *item.Account
+			if item.Debit != nil {
+				row.debit = *item.Debit
+			}
+			if item.Credit != nil {
+				row.credit = *item.Credit
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// openingBalances returns the balances at the start of the period: the
+// fiscal year's opening balances plus the rows booked before the period
+func (l *Ledger) openingBalances(period Period, rows []line) map[int32]float64 {
+	opening := make(map[int32]float64, len(l.Opening))
+	for account, balance := range l.Opening {
+		opening[account] = balance
+	}
+	for _, row := range rows {
+		if row.date.Format(dateLayout) < period.From.Format(dateLayout) {
+			opening[row.account] += row.debit - row.credit
+		}
+	}
+	return opening
+}
+
+// Balances returns the balance of every account at the end of date, debit
+// positive
+func (l *Ledger) Balances(date time.Time) map[int32]float64 {
+	return l.openingBalances(Period{From: date.AddDate(0, 0, 1)}, lines(l.Entries))
+}
+
+// Movements returns the net movement of every account within period, debit
+// positive
+func (l *Ledger) Movements(period Period) map[int32]float64 {
+	movements := make(map[int32]float64)
+	for _, row := range lines(l.Entries) {
+		if period.Contains(row.date) {
+			movements[row.account] += row.debit - row.credit
+		}
+	}
+	return movements
+}
+
+// TrialBalance computes the trial balance of the period for the accounts
+// within filter. Accounts without balance or movement are omitted.
+func (l *Ledger) TrialBalance(period Period, filter AccountFilter) *TrialBalance {
+	rows := lines(l.Entries)
+	opening := l.openingBalances(period, rows)
+
+	accounts := make(map[int32]*TrialBalanceRow)
+	account := func(number int32) *TrialBalanceRow {
+		if accounts[number] == nil {
+			accounts[number] = &TrialBalanceRow{Account: number, Name: l.Names[number], Opening: opening[number]}
+		}
+		return accounts[number]
+	}
+	for number, balance := range opening {
+		if round(balance) != 0 {
+			account(number)
+		}
+	}
+	for _, row := range rows {
+		if period.Contains(row.date) {
+			a := account(row.account)
+			a.Debit += row.debit
+			a.Credit += row.credit
+		}
+	}
+
+	report := &TrialBalance{From: period.From.Format(dateLayout), To: period.To.Format(dateLayout), Accounts: []TrialBalanceRow{}}
+	for _, number := range sortedAccounts(accounts) {
+		if !filter.Includes(number) {
+			continue
+		}
+		a := accounts[number]
+		a.Closing = round(a.Opening + a.Debit - a.Credit)
+		a.Opening, a.Debit, a.Credit = round(a.Opening), round(a.Debit), round(a.Credit)
+		report.Accounts = append(report.Accounts, *a)
+
+		report.Totals.Opening += a.Opening
+		report.Totals.Debit += a.Debit
+		report.Totals.Credit += a.Credit
+		report.Totals.Closing += a.Closing
+	}
+	report.Totals.Opening = round(report.Totals.Opening)
+	report.Totals.Debit = round(report.Totals.Debit)
+	report.Totals.Credit = round(report.Totals.Credit)
+	report.Totals.Closing = round(report.Totals.Closing)
+	return report
+}
+
+// GeneralLedger computes the general ledger of the period for the accounts
+// within filter. Accounts without balance or movement are omitted.
+func (l *Ledger) GeneralLedger(period Period, filter AccountFilter) *GeneralLedger {
+	rows := lines(l.Entries)
+	opening := l.openingBalances(period, rows)
+
+	accounts := make(map[int32]*LedgerAccount)
+	account := func(number int32) *LedgerAccount {
+		if accounts[number] == nil {
+			accounts[number] = &LedgerAccount{Account: number, Name: l.Names[number], Opening: round(opening[number]), Lines: []LedgerLine{}}
+		}
+		return accounts[number]
+	}
+	for number, balance := range opening {
+		if round(balance) != 0 && filter.Includes(number) {
+			account(number)
+		}
+	}
+
+	for _, row := range rows {
+		if !period.Contains(row.date) || !filter.Includes(row.account) {
+			continue
+		}
+		a := account(row.account)
+		a.Debit += row.debit
+		a.Credit += row.credit
+		a.Lines = append(a.Lines, LedgerLine{
+			Date:           row.date.Format(dateLayout),
+			Number:         row.number,
+			Title:          row.title,
+			JournalEntryID: row.entryID,
+			Debit:          round(row.debit),
+			Credit:         round(row.credit),
+			Balance:        round(a.Opening + a.Debit - a.Credit),
+		})
+	}
+
+	report := &GeneralLedger{From: period.From.Format(dateLayout), To: period.To.Format(dateLayout), Accounts: []LedgerAccount{}}
+	for _, number := range sortedAccounts(accounts) {
+		a := accounts[number]
+		a.Closing = round(a.Opening + a.Debit - a.Credit)
+		a.Debit, a.Credit = round(a.Debit), round(a.Credit)
+		report.Accounts = append(report.Accounts, *a)
+	}
+	return report
+}
+
+// sortedAccounts returns the keys of accounts in ascending order
+func sortedAccounts[T any](accounts map[int32]T) []int32 {
+	numbers := make([]int32, 0, len(accounts))
+	for number := range accounts {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers
+}
+
+// formatAmount renders an amount with two decimals
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+// round rounds an amount to whole öre; adding 0 turns -0 into 0
+func round(amount float64) float64 {
+	return math.Round(amount*100)/100 + 0
+}
@@ -0,0 +1,100 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEntries decodes journal entries as returned by the API
+func testEntries(t *testing.T) []company.JournalEntry {
+	t.Helper()
+	var entries []company.JournalEntry
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"id": "11111111-0000-0000-0000-000000000001", "journalEntryNumber": "V1", "title": "Rent January", "date": "2024-01-15",
+		 "items": [{"account": 5010, "debit": 8000}, {"account": 1930, "credit": 8000}]},
+		{"id": "11111111-0000-0000-0000-000000000002", "journalEntryNumber": "V2", "title": "Invoice 1001", "date": "2024-02-03",
+		 "items": [{"account": 1510, "debit": 12500}, {"account": 3011, "credit": 10000}, {"account": 2611, "credit": 2500}]},
+		{"id": "11111111-0000-0000-0000-000000000003", "journalEntryNumber": "V3", "title": "Payment 1001", "date": "2024-02-20",
+		 "items": [{"account": 1930, "debit": 12500}, {"account": 1510, "credit": 12500}]},
+		{"id": "11111111-0000-0000-0000-000000000004", "journalEntryNumber": "V4", "title": "Rent March", "date": "2024-03-15",
+		 "items": [{"account": 5010, "debit": 8000}, {"account": 1930, "credit": 8000}]}
+	]`), &entries))
+	return entries
+}
+
+func testLedger(t *testing.T) *Ledger {
+	return &Ledger{
+		Opening: map[int32]float64{1930: 50000, 2081: -25000, 2099: -25000},
+		Names:   map[int32]string{1930: "Företagskonto", 5010: "Lokalhyra"},
+		Entries: testEntries(t),
+	}
+}
+
+var february = Period{
+	From: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	To:   time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+}
+
+func TestTrialBalance(t *testing.T) {
+	report := testLedger(t).TrialBalance(february, AccountFilter{})
+
+	assert.Equal(t, "2024-02-01", report.From)
+	assert.Equal(t, "2024-02-29", report.To)
+	assert.Equal(t, []TrialBalanceRow{
+		{Account: 1510, Debit: 12500, Credit: 12500},
+		{Account: 1930, Name: "Företagskonto", Opening: 42000, Debit: 12500, Closing: 54500},
+		{Account: 2081, Opening: -25000, Closing: -25000},
+		{Account: 2099, Opening: -25000, Closing: -25000},
+		{Account: 2611, Credit: 2500, Closing: -2500},
+		{Account: 3011, Credit: 10000, Closing: -10000},
+		{Account: 5010, Name: "Lokalhyra", Opening: 8000, Closing: 8000},
+	}, report.Accounts, "V1 counts towards the opening balance and V4 is after the period")
+	assert.Equal(t, TrialBalanceTotals{Debit: 25000, Credit: 25000}, report.Totals, "the books balance")
+
+	filtered := testLedger(t).TrialBalance(february, AccountFilter{From: 3000, To: 3999})
+	require.Len(t, filtered.Accounts, 1)
+	assert.Equal(t, int32(3011), filtered.Accounts[0].Account)
+	assert.Equal(t, -10000.0, filtered.Totals.Closing)
+}
+
+func TestGeneralLedger(t *testing.T) {
+	report := testLedger(t).GeneralLedger(february, AccountFilter{From: 1000, To: 1999})
+
+	require.Len(t, report.Accounts, 2)
+	receivables, bank := report.Accounts[0], report.Accounts[1]
+	assert.Equal(t, int32(1510), receivables.Account)
+	assert.Equal(t, []LedgerLine{
+		{Date: "2024-02-03", Number: "V2", Title: "Invoice 1001", JournalEntryID: "11111111-0000-0000-0000-000000000002", Debit: 12500, Balance: 12500},
+		{Date: "2024-02-20", Number: "V3", Title: "Payment 1001", JournalEntryID: "11111111-0000-0000-0000-000000000003", Credit: 12500, Balance: 0},
+	}, receivables.Lines)
+	assert.Equal(t, 0.0, receivables.Closing)
+
+	assert.Equal(t, "Företagskonto", bank.Name)
+	assert.Equal(t, 42000.0, bank.Opening)
+	require.Len(t, bank.Lines, 1)
+	assert.Equal(t, 54500.0, bank.Lines[0].Balance)
+	assert.Equal(t, 54500.0, bank.Closing)
+}
+
+func TestReportCSV(t *testing.T) {
+	ledger := testLedger(t)
+	filter := AccountFilter{From: 1930, To: 1930}
+
+	var buf bytes.Buffer
+	require.NoError(t, ledger.TrialBalance(february, filter).WriteCSV(&buf))
+	assert.Equal(t, "account,name,opening_balance,debit,credit,closing_balance\n"+
+		"1930,Företagskonto,42000.00,12500.00,0.00,54500.00\n"+
+		",Total,42000.00,12500.00,0.00,54500.00\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, ledger.GeneralLedger(february, filter).WriteCSV(&buf))
+	assert.Equal(t, "account,name,date,journal_entry_number,title,debit,credit,balance,journal_entry_id\n"+
+		"1930,Företagskonto,2024-02-01,,Opening balance,,,42000.00,\n"+
+		"1930,Företagskonto,2024-02-20,V3,Payment 1001,12500.00,0.00,54500.00,11111111-0000-0000-0000-000000000003\n", buf.String())
+}
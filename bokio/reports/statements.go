@@ -0,0 +1,269 @@
+package reports
+
+import (
+	"sort"
+	"time"
+)
+
+// Statement line kinds
+const (
+	// LineHeading introduces a group of lines and has no amount
+	LineHeading = "heading"
+	// LineAccounts sums a range of BAS accounts
+	LineAccounts = "accounts"
+	// LineTotal sums other lines
+	LineTotal = "total"
+)
+
+// Statement is an income statement or balance sheet in the K2/K3 layout
+// (kostnadsslagsindelad resultaträkning and balansräkning per ÅRL)
+type Statement struct {
+	Title string `json:"title"`
+	// From is empty for a balance sheet, which is drawn up at To
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	// PreviousFrom and PreviousTo are the comparison period, empty without one
+	PreviousFrom string          `json:"previous_from,omitempty"`
+	PreviousTo   string          `json:"previous_to,omitempty"`
+	Lines        []StatementLine `json:"lines"`
+}
+
+// StatementLine is a row of a statement. Amounts are shown the way the
+// statement reads: income, equity and liabilities positive, costs negative.
+type StatementLine struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Kind  string `json:"kind"`
+	// Amount is the current period's amount, zero for headings
+	Amount float64 `json:"amount"`
+	// Previous is the comparison period's amount
+	Previous *float64 `json:"previous,omitempty"`
+	// Accounts are the accounts summed by an accounts line
+	Accounts []StatementAccount `json:"accounts,omitempty"`
+}
+
+// StatementAccount is an account within a statement line
+type StatementAccount struct {
+	Account  int32    `json:"account"`
+	Name     string   `json:"name,omitempty"`
+	Amount   float64  `json:"amount"`
+	Previous *float64 `json:"previous,omitempty"`
+}
+
+// Line returns the line with key, or nil
+func (s *Statement) Line(key string) *StatementLine {
+	for i := range s.Lines {
+		if s.Lines[i].Key == key {
+			return &s.Lines[i]
+		}
+	}
+	return nil
+}
+
+// PreviousPeriod maps a period of year onto previousYear: both ends are
+// shifted by the months between the ends of the years, a period ending on a
+// month end still ends on one, and the result is clamped to previousYear,
+// which may be a shorter or longer first fiscal year
+func PreviousPeriod(period, year, previousYear Period) Period {
+	months := (previousYear.To.Year()-year.To.Year())*12 + int(previousYear.To.Month()-year.To.Month())
+	previous := Period{From: shiftMonths(period.From, months), To: shiftMonths(period.To, months)}
+	if previous.From.Before(previousYear.From) {
+		previous.From = previousYear.From
+	}
+	if previous.To.After(previousYear.To) {
+		previous.To = previousYear.To
+	}
+	return previous
+}
+
+// shiftMonths moves date by months, keeping month ends on month ends and
+// clamping the day to the length of the target month
+func shiftMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location()).AddDate(0, months, 0)
+	last := first.AddDate(0, 1, -1).Day()
+	day := date.Day()
+	if day > last || date.AddDate(0, 0, 1).Day() == 1 {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// accountRange is an inclusive range of BAS accounts
+type accountRange struct {
+	from, to int32
+}
+
+// layoutLine describes a line of a statement layout
+type layoutLine struct {
+	key   string
+	label string
+	kind  string
+	// ranges are the accounts of an accounts line
+	ranges []accountRange
+	// credit lines show credit balances as positive amounts
+	credit bool
+	// sums are the keys of the lines a total adds up
+	sums []string
+}
+
+// heading, accounts and total build layout lines
+func heading(key, label string) layoutLine {
+	return layoutLine{key: key, label: label, kind: LineHeading}
+}
+
+func accounts(key, label string, credit bool, ranges ...accountRange) layoutLine {
+	return layoutLine{key: key, label: label, kind: LineAccounts, ranges: ranges, credit: credit}
+}
+
+func total(key, label string, sums ...string) layoutLine {
+	return layoutLine{key: key, label: label, kind: LineTotal, sums: sums}
+}
+
+// incomeStatementLayout follows the kostnadsslagsindelad resultaträkning of
+// K2 and K3. Accounts 8990–8999 hold the booked result and are left out.
+var incomeStatementLayout = []layoutLine{
+	heading("operating_income_heading", "Rörelseintäkter"),
+	accounts("net_sales", "Nettoomsättning", true, accountRange{3000, 3799}),
+	accounts("capitalised_work", "Aktiverat arbete för egen räkning", true, accountRange{3800, 3899}),
+	accounts("other_operating_income", "Övriga rörelseintäkter", true, accountRange{3900, 3999}),
+	total("operating_income", "Summa rörelseintäkter", "net_sales", "capitalised_work", "other_operating_income"),
+	heading("operating_expenses_heading", "Rörelsekostnader"),
+	accounts("goods", "Råvaror, förnödenheter och handelsvaror", true, accountRange{4000, 4999}),
+	accounts("other_external_expenses", "Övriga externa kostnader", true, accountRange{5000, 6999}),
+	accounts("personnel_expenses", "Personalkostnader", true, accountRange{7000, 7699}),
+	accounts("depreciation", "Av- och nedskrivningar", true, accountRange{7700, 7899}),
+	accounts("other_operating_expenses", "Övriga rörelsekostnader", true, accountRange{7900, 7999}),
+	total("operating_expenses", "Summa rörelsekostnader", "goods", "other_external_expenses", "personnel_expenses", "depreciation", "other_operating_expenses"),
+	total("operating_result", "Rörelseresultat", "operating_income", "operating_expenses"),
+	heading("financial_items_heading", "Finansiella poster"),
+	accounts("financial_investments", "Resultat från finansiella investeringar", true, accountRange{8000, 8299}),
+	accounts("interest_income", "Ränteintäkter och liknande resultatposter", true, accountRange{8300, 8399}),
+	accounts("interest_expenses", "Räntekostnader och liknande resultatposter", true, accountRange{8400, 8799}),
+	total("financial_items", "Summa finansiella poster", "financial_investments", "interest_income", "interest_expenses"),
+	total("result_after_financial_items", "Resultat efter finansiella poster", "operating_result", "financial_items"),
+	accounts("appropriations", "Bokslutsdispositioner", true, accountRange{8800, 8899}),
+	total("result_before_tax", "Resultat före skatt", "result_after_financial_items", "appropriations"),
+	accounts("tax", "Skatt på årets resultat", true, accountRange{8900, 8989}),
+	total("net_result", "Årets resultat", "result_before_tax", "tax"),
+}
+
+// balanceSheetLayout follows the balansräkning of K2 and K3. The result of
+// the year is computed from the result accounts until it is booked to
+// equity at year end, after which those accounts sum to zero.
+var balanceSheetLayout = []layoutLine{
+	heading("assets_heading", "Tillgångar"),
+	heading("fixed_assets_heading", "Anläggningstillgångar"),
+	accounts("intangible_assets", "Immateriella anläggningstillgångar", false, accountRange{1000, 1099}),
+	accounts("tangible_assets", "Materiella anläggningstillgångar", false, accountRange{1100, 1299}),
+	accounts("financial_assets", "Finansiella anläggningstillgångar", false, accountRange{1300, 1399}),
+	total("fixed_assets", "Summa anläggningstillgångar", "intangible_assets", "tangible_assets", "financial_assets"),
+	heading("current_assets_heading", "Omsättningstillgångar"),
+	accounts("inventory", "Varulager m.m.", false, accountRange{1400, 1499}),
+	accounts("receivables", "Kortfristiga fordringar", false, accountRange{1500, 1799}),
+	accounts("short_term_investments", "Kortfristiga placeringar", false, accountRange{1800, 1899}),
+	accounts("cash", "Kassa och bank", false, accountRange{1900, 1999}),
+	total("current_assets", "Summa omsättningstillgångar", "inventory", "receivables", "short_term_investments", "cash"),
+	total("total_assets", "Summa tillgångar", "fixed_assets", "current_assets"),
+	heading("equity_and_liabilities_heading", "Eget kapital och skulder"),
+	accounts("equity", "Eget kapital", true, accountRange{2000, 2099}),
+	accounts("net_result", "Årets resultat", true, accountRange{3000, 8999}),
+	total("total_equity", "Summa eget kapital", "equity", "net_result"),
+	accounts("untaxed_reserves", "Obeskattade reserver", true, accountRange{2100, 2199}),
+	accounts("provisions", "Avsättningar", true, accountRange{2200, 2299}),
+	accounts("long_term_liabilities", "Långfristiga skulder", true, accountRange{2300, 2399}),
+	accounts("current_liabilities", "Kortfristiga skulder", true, accountRange{2400, 2999}),
+	total("total_equity_and_liabilities", "Summa eget kapital och skulder", "total_equity", "untaxed_reserves", "provisions", "long_term_liabilities", "current_liabilities"),
+}
+
+// IncomeStatement builds the income statement from the movements of the
+// result accounts. previous, when not nil, holds the comparison period's
+// movements.
+func IncomeStatement(current, previous map[int32]float64, names map[int32]string) *Statement {
+	statement := buildStatement(incomeStatementLayout, current, previous, names)
+	statement.Title = "Resultaträkning"
+	return statement
+}
+
+// BalanceSheet builds the balance sheet from account balances at a date.
+// previous, when not nil, holds the balances at the comparison date.
+func BalanceSheet(current, previous map[int32]float64, names map[int32]string) *Statement {
+	statement := buildStatement(balanceSheetLayout, current, previous, names)
+	statement.Title = "Balansräkning"
+	return statement
+}
+
+// Difference returns total assets less total equity and liabilities of a
+// balance sheet, zero when the books balance
+func (s *Statement) Difference() float64 {
+	assets, liabilities := s.Line("total_assets"), s.Line("total_equity_and_liabilities")
+	if assets == nil || liabilities == nil {
+		return 0
+	}
+	return round(assets.Amount - liabilities.Amount)
+}
+
+// buildStatement evaluates a layout against debit positive balances
+func buildStatement(layout []layoutLine, current, previous map[int32]float64, names map[int32]string) *Statement {
+	statement := &Statement{Lines: make([]StatementLine, 0, len(layout))}
+	amounts := make(map[string]float64, len(layout))
+	previousAmounts := make(map[string]float64, len(layout))
+
+	for _, item := range layout {
+		line := StatementLine{Key: item.key, Label: item.label, Kind: item.kind}
+		switch item.kind {
+		case LineAccounts:
+			sign := 1.0
+			if item.credit {
+				sign = -1
+			}
+			for _, number := range accountsIn(item.ranges, current, previous) {
+				account := StatementAccount{Account: number, Name: names[number], Amount: round(sign * current[number])}
+				line.Amount += account.Amount
+				if previous != nil {
+					amount := round(sign * previous[number])
+					account.Previous = &amount
+					previousAmounts[item.key] += amount
+				}
+				line.Accounts = append(line.Accounts, account)
+			}
+		case LineTotal:
+			for _, key := range item.sums {
+				line.Amount += amounts[key]
+				previousAmounts[item.key] += previousAmounts[key]
+			}
+		}
+
+		line.Amount = round(line.Amount)
+		amounts[item.key] = line.Amount
+		if previous != nil && item.kind != LineHeading {
+			amount := round(previousAmounts[item.key])
+			line.Previous = &amount
+		}
+		statement.Lines = append(statement.Lines, line)
+	}
+	return statement
+}
+
+// accountsIn returns the accounts within ranges that have a balance in
+// current or previous, in ascending order
+func accountsIn(ranges []accountRange, current, previous map[int32]float64) []int32 {
+	seen := make(map[int32]bool)
+	for _, balances := range []map[int32]float64{current, previous} {
+		for number, balance := range balances {
+			if round(balance) == 0 {
+				continue
+			}
+			for _, r := range ranges {
+				if number >= r.from && number <= r.to {
+					seen[number] = true
+				}
+			}
+		}
+	}
+	numbers := make([]int32, 0, len(seen))
+	for number := range seen {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers
+}
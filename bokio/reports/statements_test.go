@@ -0,0 +1,126 @@
+package reports
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func amounts(t *testing.T, statement *Statement, keys ...string) []float64 {
+	t.Helper()
+	var result []float64
+	for _, key := range keys {
+		line := statement.Line(key)
+		require.NotNil(t, line, key)
+		result = append(result, line.Amount)
+	}
+	return result
+}
+
+func TestIncomeStatement(t *testing.T) {
+	ledger := testLedger(t)
+	firstQuarter := Period{From: date(2024, 1, 1), To: date(2024, 3, 31)}
+	previous := map[int32]float64{3011: -30000, 5010: 24000, 8410: 500, 8999: 5500}
+
+	statement := IncomeStatement(ledger.Movements(firstQuarter), previous, ledger.Names)
+
+	assert.Equal(t, "Resultaträkning", statement.Title)
+	assert.Equal(t, []float64{10000, -16000, -6000, -6000, -6000},
+		amounts(t, statement, "net_sales", "other_external_expenses", "operating_result", "result_after_financial_items", "net_result"))
+
+	rent := statement.Line("other_external_expenses")
+	require.Len(t, rent.Accounts, 1)
+	assert.Equal(t, StatementAccount{Account: 5010, Name: "Lokalhyra", Amount: -16000, Previous: ptr(-24000)}, rent.Accounts[0])
+
+	interest := statement.Line("interest_expenses")
+	assert.Equal(t, 0.0, interest.Amount, "accounts only booked in the comparison period are listed")
+	assert.Equal(t, ptr(-500), interest.Previous)
+	assert.Equal(t, ptr(5500), statement.Line("result_after_financial_items").Previous)
+	assert.Equal(t, ptr(5500), statement.Line("net_result").Previous, "8999 holds the booked result and is left out")
+	assert.Nil(t, statement.Line("operating_income_heading").Previous)
+
+	withoutComparison := IncomeStatement(ledger.Movements(firstQuarter), nil, ledger.Names)
+	assert.Nil(t, withoutComparison.Line("net_result").Previous)
+}
+
+func TestBalanceSheet(t *testing.T) {
+	ledger := testLedger(t)
+
+	statement := BalanceSheet(ledger.Balances(date(2024, 3, 31)), ledger.Opening, ledger.Names)
+
+	assert.Equal(t, []float64{0, 46500, 46500, 50000, -6000, 44000, 2500, 46500},
+		amounts(t, statement, "receivables", "cash", "total_assets", "equity", "net_result", "total_equity", "current_liabilities", "total_equity_and_liabilities"))
+	assert.Equal(t, 0.0, statement.Difference(), "the books balance")
+	assert.Equal(t, ptr(50000), statement.Line("total_assets").Previous)
+	assert.Equal(t, ptr(50000), statement.Line("total_equity_and_liabilities").Previous)
+
+	var buf bytes.Buffer
+	require.NoError(t, statement.WriteCSV(&buf))
+	assert.Contains(t, buf.String(), "key,label,kind,account,name,amount,previous\n"+
+		"assets_heading,Tillgångar,heading,,,,\n")
+	assert.Contains(t, buf.String(), "cash,Kassa och bank,accounts,,,46500.00,50000.00\n"+
+		"cash,Kassa och bank,account,1930,Företagskonto,46500.00,50000.00\n")
+}
+
+func TestPreviousPeriod(t *testing.T) {
+	calendar2024 := Period{From: date(2024, 1, 1), To: date(2024, 12, 31)}
+	calendar2023 := Period{From: date(2023, 1, 1), To: date(2023, 12, 31)}
+	broken2024 := Period{From: date(2024, 7, 1), To: date(2025, 6, 30)}
+	// A first fiscal year may be shorter or longer than twelve months
+	short2023 := Period{From: date(2023, 3, 1), To: date(2023, 12, 31)}
+
+	tests := []struct {
+		name     string
+		period   Period
+		year     Period
+		previous Period
+		want     Period
+	}{
+		{
+			name:     "full year",
+			period:   calendar2024,
+			year:     calendar2024,
+			previous: calendar2023,
+			want:     calendar2023,
+		},
+		{
+			name:     "month end is kept",
+			period:   Period{From: date(2024, 2, 1), To: date(2024, 2, 29)},
+			year:     calendar2024,
+			previous: calendar2023,
+			want:     Period{From: date(2023, 2, 1), To: date(2023, 2, 28)},
+		},
+		{
+			name:     "broken fiscal year",
+			period:   Period{From: date(2024, 7, 1), To: date(2024, 9, 30)},
+			year:     broken2024,
+			previous: Period{From: date(2023, 7, 1), To: date(2024, 6, 30)},
+			want:     Period{From: date(2023, 7, 1), To: date(2023, 9, 30)},
+		},
+		{
+			name:     "clamped to a short previous year",
+			period:   Period{From: date(2024, 1, 1), To: date(2024, 6, 15)},
+			year:     calendar2024,
+			previous: short2023,
+			want:     Period{From: date(2023, 3, 1), To: date(2023, 6, 15)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PreviousPeriod(tt.period, tt.year, tt.previous)
+			assert.Equal(t, tt.want.String(), got.String())
+		})
+	}
+}
+
+func ptr(amount float64) *float64 {
+	return &amount
+}
@@ -0,0 +1,247 @@
+package reports
+
+import (
+	"math"
+	"slices"
+
+	"github.com/klowdo/bokio-mcp/bokio/bas"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+)
+
+// VATSettlementAccount is the account VAT is moved to when a period is
+// settled (momsavräkning); entries booking on it are left out of the return
+const VATSettlementAccount = 2650
+
+// VATPayableLabel is the label of box 49
+const VATPayableLabel = "Moms att betala eller få tillbaka"
+
+// VATReturn is the Skatteverket momsdeklaration of a period computed from the
+// journal entries booked in it
+type VATReturn struct {
+	From  string      `json:"from"`
+	To    string      `json:"to"`
+	Boxes []VATBoxRow `json:"boxes"`
+	// Payable is box 49: output VAT less input VAT in whole kronor, negative
+	// when VAT is refunded
+	Payable int64 `json:"payable"`
+	// Settlements are the VAT settlement entries left out of the return
+	Settlements []VATSource `json:"settlements,omitempty"`
+	// Unreported are rows on VAT accounts that report to no box, such as
+	// vilande moms, which should be reviewed before filing
+	Unreported []VATSource `json:"unreported,omitempty"`
+	// InvoiceCheck is set by CompareInvoices
+	InvoiceCheck *VATInvoiceCheck `json:"invoice_check,omitempty"`
+}
+
+// VATBoxRow is a box of the momsdeklaration with the rows behind it
+type VATBoxRow struct {
+	Box   string `json:"box"`
+	Label string `json:"label"`
+	// Amount is the exact sum of the sources
+	Amount float64 `json:"amount"`
+	// Kronor is the amount to file; öre are dropped
+	Kronor  int64       `json:"kronor"`
+	Sources []VATSource `json:"sources"`
+}
+
+// VATSource is a journal entry row counted in a box, with its amount signed
+// the way the box reads
+type VATSource struct {
+	Date           string  `json:"date"`
+	Number         string  `json:"journal_entry_number,omitempty"`
+	Title          string  `json:"title,omitempty"`
+	JournalEntryID string  `json:"journal_entry_id,omitempty"`
+	Account        int32   `json:"account"`
+	Amount         float64 `json:"amount"`
+}
+
+// vatBox describes a box of the momsdeklaration
+type vatBox struct {
+	box   string
+	label string
+	// debit boxes report debit amounts as positive: purchases and input VAT
+	debit bool
+	// output boxes add to the VAT payable, input boxes subtract from it
+	output, input bool
+}
+
+// vatBoxes lists the boxes of the momsdeklaration in form order; box 49 is
+// computed from the output and input boxes
+var vatBoxes = []vatBox{
+	{box: "05", label: "Momspliktig försäljning som inte ingår i ruta 06, 07 eller 08"},
+	{box: "06", label: "Momspliktiga uttag"},
+	{box: "07", label: "Beskattningsunderlag vid vinstmarginalbeskattning"},
+	{box: "08", label: "Hyresinkomster vid frivillig skattskyldighet"},
+	{box: "10", label: "Utgående moms 25 %", output: true},
+	{box: "11", label: "Utgående moms 12 %", output: true},
+	{box: "12", label: "Utgående moms 6 %", output: true},
+	{box: "20", label: "Inköp av varor från ett annat EU-land", debit: true},
+	{box: "21", label: "Inköp av tjänster från ett annat EU-land enligt huvudregeln", debit: true},
+	{box: "22", label: "Inköp av tjänster från ett land utanför EU", debit: true},
+	{box: "23", label: "Inköp av varor i Sverige som köparen är skattskyldig för", debit: true},
+	{box: "24", label: "Övriga inköp av tjänster", debit: true},
+	{box: "30", label: "Utgående moms 25 % på inköp i ruta 20–24", output: true},
+	{box: "31", label: "Utgående moms 12 % på inköp i ruta 20–24", output: true},
+	{box: "32", label: "Utgående moms 6 % på inköp i ruta 20–24", output: true},
+	{box: "35", label: "Försäljning av varor till ett annat EU-land"},
+	{box: "36", label: "Försäljning av varor utanför EU"},
+	{box: "37", label: "Mellanmans inköp av varor vid trepartshandel", debit: true},
+	{box: "38", label: "Mellanmans försäljning av varor vid trepartshandel"},
+	{box: "39", label: "Försäljning av tjänster till näringsidkare i ett annat EU-land enligt huvudregeln"},
+	{box: "40", label: "Övrig försäljning av tjänster omsatta utanför Sverige"},
+	{box: "41", label: "Försäljning när köparen är skattskyldig i Sverige"},
+	{box: "42", label: "Övrig försäljning m.m."},
+	{box: "48", label: "Ingående moms att dra av", debit: true, input: true},
+	{box: "50", label: "Beskattningsunderlag vid import", debit: true},
+	{box: "60", label: "Utgående moms 25 % på import i ruta 50", output: true},
+	{box: "61", label: "Utgående moms 12 % på import i ruta 50", output: true},
+	{box: "62", label: "Utgående moms 6 % på import i ruta 50", output: true},
+}
+
+// VATReturn computes the momsdeklaration of period from the ledger's journal
+// entries, mapping accounts to boxes with the BAS chart. Entries booking on
+// the VAT settlement account are left out, since they move the period's VAT
+// to 2650 and would cancel it. Boxes without amounts are omitted.
+func (l *Ledger) VATReturn(period Period) *VATReturn {
+	chart := bas.Load()
+	report := &VATReturn{From: period.From.Format(dateLayout), To: period.To.Format(dateLayout), Boxes: []VATBoxRow{}}
+
+	sources := make(map[string][]VATSource)
+	for i, entry := range l.Entries {
+		settlement := entry.Items != nil && slices.ContainsFunc(*entry.Items, func(item company.JournalEntryItem) bool {
+			return item.Account != nil && *item.Account == VATSettlementAccount
+		})
+		for _, row := range lines(l.Entries[i : i+1]) {
+			if !period.Contains(row.date) {
+				continue
+			}
+			source := VATSource{
+				Date:           row.date.Format(dateLayout),
+				Number:         row.number,
+				Title:          row.title,
+				JournalEntryID: row.entryID,
+				Account:        row.account,
+				Amount:         round(row.debit - row.credit),
+			}
+			if settlement {
+				if row.account == VATSettlementAccount {
+					report.Settlements = append(report.Settlements, source)
+				}
+				continue
+			}
+
+			box := chart.VATBox(row.account)
+			if box == "" {
+				if row.account >= 2610 && row.account <= 2649 {
+					report.Unreported = append(report.Unreported, source)
+				}
+				continue
+			}
+			sources[box] = append(sources[box], source)
+		}
+	}
+
+	var payable int64
+	for _, box := range vatBoxes {
+		rows := sources[box.box]
+		if len(rows) == 0 {
+			continue
+		}
+		row := VATBoxRow{Box: box.box, Label: box.label, Sources: make([]VATSource, 0, len(rows))}
+		for _, source := range rows {
+			if !box.debit {
+				source.Amount = round(-source.Amount)
+			}
+			row.Amount += source.Amount
+			row.Sources = append(row.Sources, source)
+		}
+		row.Amount = round(row.Amount)
+		row.Kronor = int64(math.Trunc(row.Amount))
+		report.Boxes = append(report.Boxes, row)
+
+		switch {
+		case box.output:
+			payable += row.Kronor
+		case box.input:
+			payable -= row.Kronor
+		}
+	}
+	report.Payable = payable
+	return report
+}
+
+// Box returns the row of a box, or nil when the box has no amount
+func (r *VATReturn) Box(box string) *VATBoxRow {
+	i := slices.IndexFunc(r.Boxes, func(row VATBoxRow) bool { return row.Box == box })
+	if i < 0 {
+		return nil
+	}
+	return &r.Boxes[i]
+}
+
+// VATInvoiceCheck compares the VAT of the invoices dated in a period with
+// the output VAT booked in boxes 10–12
+type VATInvoiceCheck struct {
+	Invoices []VATInvoice `json:"invoices"`
+	// InvoicedVAT is the VAT of the invoices in SEK
+	InvoicedVAT float64 `json:"invoiced_vat"`
+	// BookedVAT is the sum of boxes 10, 11 and 12
+	BookedVAT float64 `json:"booked_vat"`
+	// Difference is BookedVAT less InvoicedVAT; sales booked without an
+	// invoice, such as cash sales, also show up here
+	Difference float64 `json:"difference"`
+}
+
+// VATInvoice is an invoice counted in a VATInvoiceCheck
+type VATInvoice struct {
+	ID     string  `json:"id,omitempty"`
+	Number string  `json:"invoice_number,omitempty"`
+	Date   string  `json:"invoice_date"`
+	Status string  `json:"status,omitempty"`
+	VAT    float64 `json:"vat"`
+}
+
+// CompareInvoices checks the output VAT of the return against the VAT of the
+// invoices dated in its period and stores the result in InvoiceCheck. Drafts
+// are skipped and foreign currency invoices are converted with their
+// currency rate.
+func (r *VATReturn) CompareInvoices(invoices []company.Invoice) *VATInvoiceCheck {
+	check := &VATInvoiceCheck{Invoices: []VATInvoice{}}
+	for _, invoice := range invoices {
+		date := invoice.InvoiceDate.Format(dateLayout)
+		if date < r.From || date > r.To || invoice.TotalTax == nil {
+			continue
+		}
+		if invoice.Status != nil && *invoice.Status == company.Draft {
+			continue
+		}
+
+		vat := *invoice.TotalTax
+		if invoice.CurrencyRate != nil && *invoice.CurrencyRate > 0 {
+			vat *= *invoice.CurrencyRate
+		}
+		row := VATInvoice{Date: date, VAT: round(vat)}
+		if invoice.Id != nil {
+			row.ID = invoice.Id.String()
+		}
+		if invoice.InvoiceNumber != nil {
+			row.Number = *invoice.InvoiceNumber
+		}
+		if invoice.Status != nil {
+			row.Status = string(*invoice.Status)
+		}
+		check.Invoices = append(check.Invoices, row)
+		check.InvoicedVAT += row.VAT
+	}
+
+	for _, box := range []string{"10", "11", "12"} {
+		if row := r.Box(box); row != nil {
+			check.BookedVAT += row.Amount
+		}
+	}
+	check.InvoicedVAT = round(check.InvoicedVAT)
+	check.BookedVAT = round(check.BookedVAT)
+	check.Difference = round(check.BookedVAT - check.InvoicedVAT)
+	r.InvoiceCheck = check
+	return check
+}
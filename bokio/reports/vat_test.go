@@ -0,0 +1,113 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testVATLedger(t *testing.T) *Ledger {
+	t.Helper()
+	var entries []company.JournalEntry
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"id": "22222222-0000-0000-0000-000000000001", "journalEntryNumber": "V1", "title": "Invoice 1001", "date": "2024-01-10",
+		 "items": [{"account": 1510, "debit": 12500}, {"account": 3001, "credit": 10000}, {"account": 2611, "credit": 2500}]},
+		{"id": "22222222-0000-0000-0000-000000000002", "journalEntryNumber": "V2", "title": "Rent", "date": "2024-01-15",
+		 "items": [{"account": 5010, "debit": 8000}, {"account": 2641, "debit": 2000}, {"account": 2440, "credit": 10000}]},
+		{"id": "22222222-0000-0000-0000-000000000003", "journalEntryNumber": "V3", "title": "Hosting from Ireland", "date": "2024-02-01",
+		 "items": [{"account": 4535, "debit": 1000}, {"account": 2645, "debit": 250}, {"account": 2614, "credit": 250}, {"account": 2440, "credit": 1000}]},
+		{"id": "22222222-0000-0000-0000-000000000004", "journalEntryNumber": "V4", "title": "Consulting for a German client", "date": "2024-02-10",
+		 "items": [{"account": 1510, "debit": 5000}, {"account": 3308, "credit": 5000}]},
+		{"id": "22222222-0000-0000-0000-000000000005", "journalEntryNumber": "V5", "title": "Catering", "date": "2024-03-05",
+		 "items": [{"account": 1930, "debit": 112.56}, {"account": 3002, "credit": 100.50}, {"account": 2621, "credit": 12.06}]},
+		{"id": "22222222-0000-0000-0000-000000000006", "journalEntryNumber": "V6", "title": "Momsavräkning Q4", "date": "2024-03-12",
+		 "items": [{"account": 2611, "debit": 999}, {"account": 2650, "credit": 999}]},
+		{"id": "22222222-0000-0000-0000-000000000007", "journalEntryNumber": "V7", "title": "Prepayment", "date": "2024-03-20",
+		 "items": [{"account": 1510, "debit": 100}, {"account": 2618, "credit": 100}]},
+		{"id": "22222222-0000-0000-0000-000000000008", "journalEntryNumber": "V8", "title": "Invoice 1004", "date": "2024-04-02",
+		 "items": [{"account": 1510, "debit": 1250}, {"account": 3001, "credit": 1000}, {"account": 2611, "credit": 250}]}
+	]`), &entries))
+	return &Ledger{Entries: entries}
+}
+
+var firstQuarter = Period{From: date(2024, 1, 1), To: date(2024, 3, 31)}
+
+func TestVATReturn(t *testing.T) {
+	report := testVATLedger(t).VATReturn(firstQuarter)
+
+	boxes := make(map[string]float64)
+	for _, row := range report.Boxes {
+		boxes[row.Box] = row.Amount
+	}
+	assert.Equal(t, map[string]float64{
+		"05": 10100.50,
+		"10": 2500,
+		"11": 12.06,
+		"21": 1000,
+		"30": 250,
+		"39": 5000,
+		"48": 2250,
+	}, boxes, "the settlement in V6 and V8 after the period are left out")
+
+	sales := report.Box("05")
+	require.NotNil(t, sales)
+	assert.Equal(t, int64(10100), sales.Kronor, "öre are dropped")
+	assert.Equal(t, []VATSource{
+		{Date: "2024-01-10", Number: "V1", Title: "Invoice 1001", JournalEntryID: "22222222-0000-0000-0000-000000000001", Account: 3001, Amount: 10000},
+		{Date: "2024-03-05", Number: "V5", Title: "Catering", JournalEntryID: "22222222-0000-0000-0000-000000000005", Account: 3002, Amount: 100.50},
+	}, sales.Sources)
+	assert.Equal(t, "Ingående moms att dra av", report.Box("48").Label)
+	assert.Equal(t, int64(2500+12+250-2250), report.Payable)
+
+	require.Len(t, report.Settlements, 1)
+	assert.Equal(t, "V6", report.Settlements[0].Number)
+	require.Len(t, report.Unreported, 1)
+	assert.Equal(t, int32(2618), report.Unreported[0].Account)
+
+	empty := testVATLedger(t).VATReturn(Period{From: date(2024, 6, 1), To: date(2024, 6, 30)})
+	assert.Empty(t, empty.Boxes)
+	assert.Zero(t, empty.Payable)
+}
+
+func TestVATReturnCompareInvoices(t *testing.T) {
+	var invoices []company.Invoice
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"id": "33333333-0000-0000-0000-000000000001", "invoiceNumber": "1001", "invoiceDate": "2024-01-10", "dueDate": "2024-02-09", "status": "paid", "totalTax": 2500, "lineItems": []},
+		{"invoiceNumber": "1002", "invoiceDate": "2024-02-20", "dueDate": "2024-03-21", "status": "published", "currency": "EUR", "currencyRate": 11.5, "totalTax": 10, "lineItems": []},
+		{"invoiceDate": "2024-03-01", "dueDate": "2024-03-31", "status": "draft", "totalTax": 400, "lineItems": []},
+		{"invoiceNumber": "1004", "invoiceDate": "2024-04-02", "dueDate": "2024-05-02", "status": "published", "totalTax": 250, "lineItems": []}
+	]`), &invoices))
+
+	report := testVATLedger(t).VATReturn(firstQuarter)
+	check := report.CompareInvoices(invoices)
+	assert.Same(t, check, report.InvoiceCheck)
+
+	assert.Equal(t, []VATInvoice{
+		{ID: "33333333-0000-0000-0000-000000000001", Number: "1001", Date: "2024-01-10", Status: "paid", VAT: 2500},
+		{Number: "1002", Date: "2024-02-20", Status: "published", VAT: 115},
+	}, check.Invoices, "drafts and invoices outside the period are skipped")
+	assert.Equal(t, 2615.0, check.InvoicedVAT)
+	assert.Equal(t, 2512.06, check.BookedVAT)
+	assert.Equal(t, -102.94, check.Difference)
+}
+
+func TestVATReturnCSV(t *testing.T) {
+	ledger := testVATLedger(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, ledger.VATReturn(Period{From: date(2024, 2, 1), To: date(2024, 2, 29)}).WriteCSV(&buf))
+	assert.Equal(t, "box,label,date,journal_entry_number,title,account,amount,journal_entry_id\n"+
+		"21,Inköp av tjänster från ett annat EU-land enligt huvudregeln,2024-02-01,V3,Hosting from Ireland,4535,1000.00,22222222-0000-0000-0000-000000000003\n"+
+		"21,Inköp av tjänster från ett annat EU-land enligt huvudregeln,,,Total,,1000,\n"+
+		"30,Utgående moms 25 % på inköp i ruta 20–24,2024-02-01,V3,Hosting from Ireland,2614,250.00,22222222-0000-0000-0000-000000000003\n"+
+		"30,Utgående moms 25 % på inköp i ruta 20–24,,,Total,,250,\n"+
+		"39,Försäljning av tjänster till näringsidkare i ett annat EU-land enligt huvudregeln,2024-02-10,V4,Consulting for a German client,3308,5000.00,22222222-0000-0000-0000-000000000004\n"+
+		"39,Försäljning av tjänster till näringsidkare i ett annat EU-land enligt huvudregeln,,,Total,,5000,\n"+
+		"48,Ingående moms att dra av,2024-02-01,V3,Hosting from Ireland,2645,250.00,22222222-0000-0000-0000-000000000003\n"+
+		"48,Ingående moms att dra av,,,Total,,250,\n"+
+		"49,Moms att betala eller få tillbaka,,,Total,,0,\n", buf.String())
+}
@@ -0,0 +1,342 @@
+// Package sie parses SIE 4 accounting export files as produced by Bokio
+package sie
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// File is the parsed content of a SIE 4 file
+type File struct {
+	Program     string
+	Format      string
+	Type        int
+	CompanyName string
+	OrgNumber   string
+	// FiscalYears are the #RAR periods keyed by year index (0 = current, -1 = previous)
+	FiscalYears map[int]Period
+	// Accounts maps account numbers to names
+	Accounts map[string]string
+	// OpeningBalances, ClosingBalances and Results are keyed by year index, then account
+	OpeningBalances map[int]map[string]float64
+	ClosingBalances map[int]map[string]float64
+	Results         map[int]map[string]float64
+	Vouchers        []Voucher
+}
+
+// Period is a fiscal year period from a #RAR record
+type Period struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Voucher is a #VER record with its transactions
+type Voucher struct {
+	Series       string        `json:"series"`
+	Number       string        `json:"number"`
+	Date         string        `json:"date"`
+	Text         string        `json:"text,omitempty"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// Transaction is a #TRANS row of a voucher
+type Transaction struct {
+	Account string  `json:"account"`
+	Amount  float64 `json:"amount"`
+	Date    string  `json:"date,omitempty"`
+	Text    string  `json:"text,omitempty"`
+}
+
+// Parse reads a SIE 4 file. SIE files are CP437 encoded by specification
+// (#FORMAT PC8); input that is already valid UTF-8 is used as is.
+func Parse(data []byte) (*File, error) {
+	text := decode(data)
+
+	file := &File{
+		FiscalYears:     make(map[int]Period),
+		Accounts:        make(map[string]string),
+		OpeningBalances: make(map[int]map[string]float64),
+		ClosingBalances: make(map[int]map[string]float64),
+		Results:         make(map[int]map[string]float64),
+	}
+
+	var current *Voucher
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// Voucher bodies are enclosed by lines holding a single brace
+		var fields []string
+		if line == "{" || line == "}" {
+			fields = []string{line}
+		} else {
+			fields = tokenize(line)
+		}
+
+		if err := file.parseRecord(fields, &current); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read SIE file: %w", err)
+	}
+	if current != nil {
+		return nil, fmt.Errorf("unterminated voucher %s %s", current.Series, current.Number)
+	}
+
+	return file, nil
+}
+
+// parseRecord applies a single tokenized line to the file
+func (f *File) parseRecord(fields []string, current **Voucher) error {
+	switch fields[0] {
+	case "{":
+		if *current == nil {
+			return fmt.Errorf("unexpected '{' outside a voucher")
+		}
+	case "}":
+		if *current == nil {
+			return fmt.Errorf("unexpected '}' outside a voucher")
+		}
+		f.Vouchers = append(f.Vouchers, **current)
+		*current = nil
+	case "#PROGRAM":
+		f.Program = field(fields, 1)
+	case "#FORMAT":
+		f.Format = field(fields, 1)
+	case "#SIETYP":
+		f.Type, _ = strconv.Atoi(field(fields, 1))
+	case "#FNAMN":
+		f.CompanyName = field(fields, 1)
+	case "#ORGNR":
+		f.OrgNumber = field(fields, 1)
+	case "#RAR":
+		year, err := strconv.Atoi(field(fields, 1))
+		if err != nil {
+			return fmt.Errorf("invalid #RAR year index %q", field(fields, 1))
+		}
+		f.FiscalYears[year] = Period{Start: formatDate(field(fields, 2)), End: formatDate(field(fields, 3))}
+	case "#KONTO":
+		f.Accounts[field(fields, 1)] = field(fields, 2)
+	case "#IB", "#UB", "#RES":
+		year, err := strconv.Atoi(field(fields, 1))
+		if err != nil {
+			return fmt.Errorf("invalid %s year index %q", fields[0], field(fields, 1))
+		}
+		amount, err := parseAmount(field(fields, 3))
+		if err != nil {
+			return fmt.Errorf("invalid %s amount: %w", fields[0], err)
+		}
+		target := f.OpeningBalances
+		switch fields[0] {
+		case "#UB":
+			target = f.ClosingBalances
+		case "#RES":
+			target = f.Results
+		}
+		if target[year] == nil {
+			target[year] = make(map[string]float64)
+		}
+		target[year][field(fields, 2)] += amount
+	case "#VER":
+		if *current != nil {
+			return fmt.Errorf("nested #VER inside voucher %s %s", (*current).Series, (*current).Number)
+		}
+		*current = &Voucher{
+			Series: field(fields, 1),
+			Number: field(fields, 2),
+			Date:   formatDate(field(fields, 3)),
+			Text:   field(fields, 4),
+		}
+	case "#TRANS":
+		if *current == nil {
+			return fmt.Errorf("#TRANS outside a voucher")
+		}
+		amount, err := parseAmount(field(fields, 3))
+		if err != nil {
+			return fmt.Errorf("invalid #TRANS amount: %w", err)
+		}
+		(*current).Transactions = append((*current).Transactions, Transaction{
+			Account: field(fields, 1),
+			Amount:  amount,
+			Date:    formatDate(field(fields, 4)),
+			Text:    field(fields, 5),
+		})
+	}
+	// Other records (#FLAGGA, #DIM, #RTRANS, #BTRANS, ...) do not affect the summary
+	return nil
+}
+
+// tokenize splits a SIE line into fields. Quoted strings may contain spaces
+// and escaped quotes; object lists in braces are kept as one field.
+func tokenize(line string) []string {
+	var fields []string
+	var b strings.Builder
+	inQuotes, inBraces, hasField := false, false, false
+
+	flush := func() {
+		if hasField {
+			fields = append(fields, b.String())
+		}
+		b.Reset()
+		hasField = false
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inQuotes && c == '\\' && i+1 < len(line) && line[i+1] == '"':
+			b.WriteByte('"')
+			i++
+		case inQuotes && c == '"':
+			inQuotes = false
+		case inQuotes:
+			b.WriteByte(c)
+		case inBraces:
+			b.WriteByte(c)
+			if c == '}' {
+				inBraces = false
+			}
+		case c == '"':
+			inQuotes, hasField = true, true
+		case c == '{':
+			// An object list such as {} or {1 "100"}
+			b.WriteByte(c)
+			inBraces, hasField = true, true
+		case c == ' ' || c == '\t' || c == '\r':
+			flush()
+		default:
+			b.WriteByte(c)
+			hasField = true
+		}
+	}
+	flush()
+	return fields
+}
+
+// field returns the i:th field or an empty string
+func field(fields []string, i int) string {
+	if i < len(fields) {
+		return fields[i]
+	}
+	return ""
+}
+
+// parseAmount parses a SIE amount, which uses a decimal point
+func parseAmount(s string) (float64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("missing amount")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// formatDate converts a SIE date (YYYYMMDD) to YYYY-MM-DD
+func formatDate(s string) string {
+	if len(s) != 8 {
+		return s
+	}
+	return s[:4] + "-" + s[4:6] + "-" + s[6:]
+}
+
+// decode converts CP437 input to UTF-8
+func decode(data []byte) string {
+	if utf8.Valid(data) {
+		return string(data)
+	}
+
+	var b strings.Builder
+	b.Grow(len(data) + len(data)/8)
+	for _, c := range data {
+		if c < 0x80 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteRune(cp437[c-0x80])
+	}
+	return b.String()
+}
+
+// cp437 maps the upper half of code page 437 to Unicode
+var cp437 = [128]rune{
+	'Ç', 'ü', 'é', 'â', 'ä', 'à', 'å', 'ç', 'ê', 'ë', 'è', 'ï', 'î', 'ì', 'Ä', 'Å',
+	'É', 'æ', 'Æ', 'ô', 'ö', 'ò', 'û', 'ù', 'ÿ', 'Ö', 'Ü', '¢', '£', '¥', '₧', 'ƒ',
+	'á', 'í', 'ó', 'ú', 'ñ', 'Ñ', 'ª', 'º', '¿', '⌐', '¬', '½', '¼', '¡', '«', '»',
+	'░', '▒', '▓', '│', '┤', '╡', '╢', '╖', '╕', '╣', '║', '╗', '╝', '╜', '╛', '┐',
+	'└', '┴', '┬', '├', '─', '┼', '╞', '╟', '╚', '╔', '╩', '╦', '╠', '═', '╬', '╧',
+	'╨', '╤', '╥', '╙', '╘', '╒', '╓', '╫', '╪', '┘', '┌', '█', '▄', '▌', '▐', '▀',
+	'α', 'ß', 'Γ', 'π', 'Σ', 'σ', 'µ', 'τ', 'Φ', 'Θ', 'Ω', 'δ', '∞', 'φ', 'ε', '∩',
+	'≡', '±', '≥', '≤', '⌠', '⌡', '÷', '≈', '°', '∙', '·', '√', 'ⁿ', '²', '■', '\u00a0',
+}
+
+// Summary is a compact overview of a SIE file for the current fiscal year
+type Summary struct {
+	CompanyName      string           `json:"company_name,omitempty"`
+	OrgNumber        string           `json:"org_number,omitempty"`
+	FiscalYear       Period           `json:"fiscal_year"`
+	AccountCount     int              `json:"account_count"`
+	VoucherCount     int              `json:"voucher_count"`
+	TransactionCount int              `json:"transaction_count"`
+	Accounts         []AccountSummary `json:"accounts"`
+}
+
+// AccountSummary holds the current year's figures for one account
+type AccountSummary struct {
+	Number  string  `json:"number"`
+	Name    string  `json:"name"`
+	Opening float64 `json:"opening_balance"`
+	Closing float64 `json:"closing_balance"`
+	Result  float64 `json:"result"`
+}
+
+// Summarize builds a summary for the current fiscal year (index 0).
+// Accounts without any balance or result are omitted.
+func (f *File) Summarize() *Summary {
+	summary := &Summary{
+		CompanyName:  f.CompanyName,
+		OrgNumber:    f.OrgNumber,
+		FiscalYear:   f.FiscalYears[0],
+		AccountCount: len(f.Accounts),
+		VoucherCount: len(f.Vouchers),
+	}
+	for _, voucher := range f.Vouchers {
+		summary.TransactionCount += len(voucher.Transactions)
+	}
+
+	numbers := make(map[string]bool)
+	for _, amounts := range []map[string]float64{f.OpeningBalances[0], f.ClosingBalances[0], f.Results[0]} {
+		for account, amount := range amounts {
+			if round(amount) != 0 {
+				numbers[account] = true
+			}
+		}
+	}
+
+	for number := range numbers {
+		summary.Accounts = append(summary.Accounts, AccountSummary{
+			Number:  number,
+			Name:    f.Accounts[number],
+			Opening: round(f.OpeningBalances[0][number]),
+			Closing: round(f.ClosingBalances[0][number]),
+			Result:  round(f.Results[0][number]),
+		})
+	}
+	sort.Slice(summary.Accounts, func(i, j int) bool {
+		return summary.Accounts[i].Number < summary.Accounts[j].Number
+	})
+
+	return summary
+}
+
+// round rounds an amount to whole öre
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,125 @@
+package sie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSIE = `#FLAGGA 0
+#PROGRAM "Bokio" 1.0
+#FORMAT PC8
+#GEN 20250110
+#SIETYP 4
+#FNAMN "Testbolaget AB"
+#ORGNR 5561234567
+#RAR 0 20240101 20241231
+#RAR -1 20230101 20231231
+#KONTO 1930 "Företagskonto"
+#KONTO 2081 "Aktiekapital"
+#KONTO 3001 "Försäljning varor 25% moms"
+#KONTO 2611 "Utgående moms 25%"
+#IB 0 1930 25000.00
+#IB 0 2081 -25000.00
+#UB 0 1930 37500.00
+#UB 0 2081 -25000.00
+#UB 0 2611 -2500.00
+#RES 0 3001 -10000.00
+#IB -1 1930 0.00
+#VER A 1 20240115 "Försäljning \"kontant\"" 20240116
+{
+   #TRANS 1930 {} 12500.00
+   #TRANS 3001 {} -10000.00 20240115 "Varor"
+   #TRANS 2611 {1 "100"} -2500.00
+}
+`
+
+// encodeCP437 encodes the Swedish letters used in the tests as CP437
+var encodeCP437 = strings.NewReplacer("å", "\x86", "ä", "\x84", "ö", "\x94", "Å", "\x8f", "Ä", "\x8e", "Ö", "\x99")
+
+func TestParse(t *testing.T) {
+	file, err := Parse([]byte(encodeCP437.Replace(testSIE)))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bokio", file.Program)
+	assert.Equal(t, "PC8", file.Format)
+	assert.Equal(t, 4, file.Type)
+	assert.Equal(t, "Testbolaget AB", file.CompanyName)
+	assert.Equal(t, Period{Start: "2024-01-01", End: "2024-12-31"}, file.FiscalYears[0])
+	assert.Equal(t, Period{Start: "2023-01-01", End: "2023-12-31"}, file.FiscalYears[-1])
+
+	// CP437 bytes are decoded to UTF-8
+	assert.Equal(t, "Företagskonto", file.Accounts["1930"])
+	assert.Equal(t, "Försäljning varor 25% moms", file.Accounts["3001"])
+	assert.Equal(t, "Utgående moms 25%", file.Accounts["2611"])
+
+	require.Len(t, file.Vouchers, 1)
+	voucher := file.Vouchers[0]
+	assert.Equal(t, "A", voucher.Series)
+	assert.Equal(t, "1", voucher.Number)
+	assert.Equal(t, "2024-01-15", voucher.Date)
+	assert.Equal(t, `Försäljning "kontant"`, voucher.Text)
+	assert.Equal(t, []Transaction{
+		{Account: "1930", Amount: 12500},
+		{Account: "3001", Amount: -10000, Date: "2024-01-15", Text: "Varor"},
+		{Account: "2611", Amount: -2500},
+	}, voucher.Transactions)
+}
+
+func TestParseUTF8(t *testing.T) {
+	file, err := Parse([]byte("#KONTO 1930 \"Företagskonto\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "Företagskonto", file.Accounts["1930"])
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		errMsg string
+	}{
+		{
+			name:   "transaction outside voucher",
+			input:  "#TRANS 1930 {} 100.00\n",
+			errMsg: "line 1: #TRANS outside a voucher",
+		},
+		{
+			name:   "unterminated voucher",
+			input:  "#VER A 1 20240101 \"x\"\n{\n#TRANS 1930 {} 100.00\n",
+			errMsg: "unterminated voucher A 1",
+		},
+		{
+			name:   "invalid amount",
+			input:  "#UB 0 1930 abc\n",
+			errMsg: "invalid #UB amount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	file, err := Parse([]byte(testSIE))
+	require.NoError(t, err)
+
+	summary := file.Summarize()
+	assert.Equal(t, "Testbolaget AB", summary.CompanyName)
+	assert.Equal(t, "5561234567", summary.OrgNumber)
+	assert.Equal(t, 4, summary.AccountCount)
+	assert.Equal(t, 1, summary.VoucherCount)
+	assert.Equal(t, 3, summary.TransactionCount)
+	assert.Equal(t, []AccountSummary{
+		{Number: "1930", Name: "Företagskonto", Opening: 25000, Closing: 37500},
+		{Number: "2081", Name: "Aktiekapital", Opening: -25000, Closing: -25000},
+		{Number: "2611", Name: "Utgående moms 25%", Closing: -2500},
+		{Number: "3001", Name: "Försäljning varor 25% moms", Result: -10000},
+	}, summary.Accounts)
+}
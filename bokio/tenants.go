@@ -0,0 +1,215 @@
+package bokio
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Tenant is a Bokio company the server can act on
+type Tenant struct {
+	// ID is the Bokio company UUID
+	ID string `json:"id"`
+	// Alias is a short friendly name that can be used instead of the ID
+	Alias string `json:"alias,omitempty"`
+	// Name is the display name of the company
+	Name string `json:"name,omitempty"`
+	// IntegrationToken is the private integration token for this company
+	IntegrationToken string `json:"integration_token,omitempty"`
+	// IntegrationTokenEnv names an environment variable holding the token,
+	// so secrets do not have to be written to the tenants file
+	IntegrationTokenEnv string `json:"integration_token_env,omitempty"`
+}
+
+// token returns the tenant's own integration token, if any
+func (t *Tenant) token() string {
+	if t.IntegrationToken != "" {
+		return t.IntegrationToken
+	}
+	if t.IntegrationTokenEnv != "" {
+		return os.Getenv(t.IntegrationTokenEnv)
+	}
+	return ""
+}
+
+// HasOwnToken reports whether requests for this tenant use a dedicated token
+func (t *Tenant) HasOwnToken() bool {
+	return t.token() != ""
+}
+
+// DisplayName returns the most descriptive name available for the tenant
+func (t *Tenant) DisplayName() string {
+	switch {
+	case t.Name != "":
+		return t.Name
+	case t.Alias != "":
+		return t.Alias
+	default:
+		return t.ID
+	}
+}
+
+// tenantsFile is the on-disk format of the tenant registry
+type tenantsFile struct {
+	Default   string   `json:"default,omitempty"`
+	Companies []Tenant `json:"companies"`
+}
+
+// TenantRegistry maps Bokio company IDs and aliases to credentials
+type TenantRegistry struct {
+	tenants   []Tenant
+	byID      map[string]*Tenant
+	byAlias   map[string]*Tenant
+	defaultID string
+}
+
+// NewTenantRegistry validates the tenants and builds a registry. defaultRef
+// may be an ID or alias naming the company used when none is selected.
+func NewTenantRegistry(tenants []Tenant, defaultRef string) (*TenantRegistry, error) {
+	registry := &TenantRegistry{
+		byID:    make(map[string]*Tenant, len(tenants)),
+		byAlias: make(map[string]*Tenant, len(tenants)),
+	}
+
+	registry.tenants = make([]Tenant, len(tenants))
+	copy(registry.tenants, tenants)
+
+	for i := range registry.tenants {
+		tenant := &registry.tenants[i]
+
+		id, err := uuid.Parse(tenant.ID)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %d: invalid company ID %q: %w", i+1, tenant.ID, err)
+		}
+		tenant.ID = id.String()
+
+		if _, exists := registry.byID[tenant.ID]; exists {
+			return nil, fmt.Errorf("tenant %d: duplicate company ID %s", i+1, tenant.ID)
+		}
+		registry.byID[tenant.ID] = tenant
+
+		if tenant.Alias == "" {
+			continue
+		}
+		alias := strings.ToLower(tenant.Alias)
+		if _, err := uuid.Parse(alias); err == nil {
+			return nil, fmt.Errorf("tenant %d: alias %q must not look like a company ID", i+1, tenant.Alias)
+		}
+		if _, exists := registry.byAlias[alias]; exists {
+			return nil, fmt.Errorf("tenant %d: duplicate alias %q", i+1, tenant.Alias)
+		}
+		registry.byAlias[alias] = tenant
+	}
+
+	if defaultRef != "" {
+		tenant, ok := registry.Resolve(defaultRef)
+		if !ok {
+			return nil, fmt.Errorf("default company %q is not in the tenant registry", defaultRef)
+		}
+		registry.defaultID = tenant.ID
+	}
+
+	return registry, nil
+}
+
+// LoadTenantRegistry reads a JSON tenants file
+func LoadTenantRegistry(path string) (*TenantRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+
+	var file tenantsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants file %s: %w", path, err)
+	}
+
+	return NewTenantRegistry(file.Companies, file.Default)
+}
+
+// Resolve looks up a tenant by company ID or alias (case-insensitive)
+func (r *TenantRegistry) Resolve(ref string) (*Tenant, bool) {
+	if r == nil || ref == "" {
+		return nil, false
+	}
+
+	if id, err := uuid.Parse(ref); err == nil {
+		tenant, ok := r.byID[id.String()]
+		return tenant, ok
+	}
+
+	tenant, ok := r.byAlias[strings.ToLower(ref)]
+	return tenant, ok
+}
+
+// Default returns the registry's default tenant, or nil
+func (r *TenantRegistry) Default() *Tenant {
+	if r == nil || r.defaultID == "" {
+		return nil
+	}
+	return r.byID[r.defaultID]
+}
+
+// List returns all tenants ordered by alias, then ID
+func (r *TenantRegistry) List() []Tenant {
+	if r == nil {
+		return nil
+	}
+
+	tenants := make([]Tenant, len(r.tenants))
+	copy(tenants, r.tenants)
+	sort.Slice(tenants, func(i, j int) bool {
+		if tenants[i].Alias != tenants[j].Alias {
+			return tenants[i].Alias < tenants[j].Alias
+		}
+		return tenants[i].ID < tenants[j].ID
+	})
+	return tenants
+}
+
+// Len returns the number of registered tenants
+func (r *TenantRegistry) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.tenants)
+}
+
+// hasTokens reports whether any tenant has a dedicated token
+func (r *TenantRegistry) hasTokens() bool {
+	if r == nil {
+		return false
+	}
+	for i := range r.tenants {
+		if r.tenants[i].HasOwnToken() {
+			return true
+		}
+	}
+	return false
+}
+
+// companyPathPattern extracts the company ID from company API request paths
+var companyPathPattern = regexp.MustCompile(`/v1/companies/([0-9a-fA-F-]{36})(?:/|$)`)
+
+// tokenForPath returns the dedicated token of the tenant addressed by a request path
+func (r *TenantRegistry) tokenForPath(path string) string {
+	if r == nil {
+		return ""
+	}
+
+	match := companyPathPattern.FindStringSubmatch(path)
+	if match == nil {
+		return ""
+	}
+
+	tenant, ok := r.Resolve(match[1])
+	if !ok {
+		return ""
+	}
+	return tenant.token()
+}
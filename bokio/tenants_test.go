@@ -0,0 +1,146 @@
+package bokio
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCompanyA = "11111111-1111-1111-1111-111111111111"
+	testCompanyB = "22222222-2222-2222-2222-222222222222"
+)
+
+func TestNewTenantRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		tenants []Tenant
+		def     string
+		errMsg  string
+	}{
+		{
+			name: "valid registry with default alias",
+			tenants: []Tenant{
+				{ID: testCompanyA, Alias: "acme"},
+				{ID: testCompanyB, Alias: "globex"},
+			},
+			def: "ACME",
+		},
+		{
+			name:    "invalid company ID",
+			tenants: []Tenant{{ID: "not-a-uuid"}},
+			errMsg:  "invalid company ID",
+		},
+		{
+			name: "duplicate alias",
+			tenants: []Tenant{
+				{ID: testCompanyA, Alias: "acme"},
+				{ID: testCompanyB, Alias: "Acme"},
+			},
+			errMsg: "duplicate alias",
+		},
+		{
+			name:    "unknown default",
+			tenants: []Tenant{{ID: testCompanyA, Alias: "acme"}},
+			def:     "globex",
+			errMsg:  "not in the tenant registry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := NewTenantRegistry(tt.tenants, tt.def)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.tenants), registry.Len())
+			assert.Equal(t, testCompanyA, registry.Default().ID)
+		})
+	}
+}
+
+func TestTenantRegistryResolve(t *testing.T) {
+	registry, err := NewTenantRegistry([]Tenant{
+		{ID: testCompanyA, Alias: "acme", Name: "Acme AB"},
+		{ID: testCompanyB},
+	}, "")
+	require.NoError(t, err)
+
+	tenant, ok := registry.Resolve("Acme")
+	require.True(t, ok)
+	assert.Equal(t, testCompanyA, tenant.ID)
+	assert.Equal(t, "Acme AB", tenant.DisplayName())
+
+	tenant, ok = registry.Resolve(testCompanyB)
+	require.True(t, ok)
+	assert.Equal(t, testCompanyB, tenant.DisplayName())
+
+	_, ok = registry.Resolve("unknown")
+	assert.False(t, ok)
+	assert.Nil(t, registry.Default())
+
+	var empty *TenantRegistry
+	_, ok = empty.Resolve("acme")
+	assert.False(t, ok)
+}
+
+func TestTenantTokenRouting(t *testing.T) {
+	os.Setenv("TEST_BOKIO_TOKEN_B", "token-b")
+	defer os.Unsetenv("TEST_BOKIO_TOKEN_B")
+
+	tenantsFile := filepath.Join(t.TempDir(), "tenants.json")
+	require.NoError(t, os.WriteFile(tenantsFile, []byte(`{
+		"default": "acme",
+		"companies": [
+			{"id": "`+testCompanyA+`", "alias": "acme", "integration_token": "token-a"},
+			{"id": "`+testCompanyB+`", "alias": "globex", "integration_token_env": "TEST_BOKIO_TOKEN_B"}
+		]
+	}`), 0o600))
+
+	var lastAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewAuthClient(&Config{
+		IntegrationToken: "global-token",
+		BaseURL:          server.URL,
+		TenantsFile:      tenantsFile,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, client.Tenants().Len())
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/v1/companies/" + testCompanyA + "/invoices", "Bearer token-a"},
+		{"/v1/companies/" + testCompanyB + "/customers", "Bearer token-b"},
+		{"/v1/companies/33333333-3333-3333-3333-333333333333/items", "Bearer global-token"},
+		{"/v1/connections", "Bearer global-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+			require.NoError(t, err)
+
+			resp, err := client.CompanyClient.Client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.want, lastAuth)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package bokio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klowdo/bokio-mcp/bokio/generated/general"
+)
+
+// Token is an OAuth2 token as persisted between server runs
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	TenantID     string    `json:"tenant_id,omitempty"`
+	TenantType   string    `json:"tenant_type,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// newToken converts a token endpoint response into a Token with an absolute expiry
+func newToken(resp *general.Token, now time.Time) (*Token, error) {
+	if resp == nil || resp.AccessToken == nil || *resp.AccessToken == "" {
+		return nil, fmt.Errorf("token response did not contain an access token")
+	}
+
+	token := &Token{AccessToken: *resp.AccessToken}
+	if resp.RefreshToken != nil {
+		token.RefreshToken = *resp.RefreshToken
+	}
+	if resp.TokenType != nil {
+		token.TokenType = *resp.TokenType
+	}
+	if resp.TenantId != nil {
+		token.TenantID = resp.TenantId.String()
+	}
+	if resp.TenantType != nil {
+		token.TenantType = string(*resp.TenantType)
+	}
+	if resp.ExpiresIn != nil && *resp.ExpiresIn > 0 {
+		token.Expiry = now.Add(time.Duration(*resp.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
+
+// expiresWithin reports whether the token expires within the given duration.
+// Tokens without a known expiry never expire.
+func (t *Token) expiresWithin(d time.Duration, now time.Time) bool {
+	if t.Expiry.IsZero() {
+		return false
+	}
+	return !now.Add(d).Before(t.Expiry)
+}
+
+// TokenStore persists OAuth2 tokens
+type TokenStore interface {
+	// Load returns the stored token, or nil if no token has been stored yet
+	Load() (*Token, error)
+	// Save replaces the stored token
+	Save(token *Token) error
+}
+
+// FileTokenStore stores a token as JSON in a file readable only by the owner
+type FileTokenStore struct {
+	Path string
+}
+
+// NewFileTokenStore creates a token store backed by the given file path
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path}
+}
+
+// DefaultTokenFile returns the default location of the token file
+func DefaultTokenFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "bokio-mcp", "token.json")
+}
+
+// Load implements TokenStore
+func (s *FileTokenStore) Load() (*Token, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token file %s: %w", s.Path, err)
+	}
+	return &token, nil
+}
+
+// Save implements TokenStore. The file is written atomically so a crash
+// never leaves a truncated token behind.
+func (s *FileTokenStore) Save(token *Token) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	return writeFileAtomic(s.Path, data, "token")
+}
+
+// writeFileAtomic replaces path with data, readable only by the owner. The
+// file is written to a temporary file first so a crash never leaves a
+// truncated file behind. name describes the file in errors.
+func writeFileAtomic(path string, data []byte, name string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", name, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+name+"-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create %s file: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s file: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s file: %w", name, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store %s file: %w", name, err)
+	}
+	return nil
+}
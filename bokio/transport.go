@@ -0,0 +1,213 @@
+package bokio
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRequestTimeout bounds each attempt of an API request
+	DefaultRequestTimeout = 30 * time.Second
+	// DefaultMaxRetries is how often idempotent requests are retried
+	DefaultMaxRetries = 3
+	// DefaultRateLimit is the sustained number of API requests per second
+	DefaultRateLimit = 5.0
+	// DefaultRateBurst is the number of API requests allowed in a burst
+	DefaultRateBurst = 10
+
+	// retryMinBackoff and retryMaxBackoff bound the delay between retries.
+	// A Retry-After longer than retryMaxBackoff is not waited for.
+	retryMinBackoff = 500 * time.Millisecond
+	retryMaxBackoff = 30 * time.Second
+)
+
+// httpDoer sends HTTP requests, like *http.Client
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// retryingClient sends requests through a client-side rate limiter, retries
+// idempotent requests after transport errors, 429 and 5xx responses with
+// exponential backoff, and bounds each attempt with a timeout
+type retryingClient struct {
+	client     httpDoer
+	limiter    *tokenBucket
+	maxRetries int
+	// sleep waits for d or until ctx is done, replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// newRetryingClient builds the transport of an AuthClient from config.
+// Zero values select the defaults, negative values disable retries or rate
+// limiting.
+func newRetryingClient(config *Config) *retryingClient {
+	timeout := config.RequestTimeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	maxRetries := config.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	rate, burst := config.RateLimit, config.RateBurst
+	if rate == 0 {
+		rate = DefaultRateLimit
+	}
+	if burst <= 0 {
+		burst = DefaultRateBurst
+	}
+
+	return &retryingClient{
+		client:     &http.Client{Timeout: timeout},
+		limiter:    newTokenBucket(rate, burst),
+		maxRetries: max(maxRetries, 0),
+		sleep:      sleepContext,
+	}
+}
+
+// Do sends req, retrying it when that is safe
+func (c *retryingClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retryable := isIdempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for attempt := 0; ; attempt++ {
+		if err := c.sleep(ctx, c.limiter.reserve()); err != nil {
+			return nil, err
+		}
+
+		attemptReq := req
+		if attempt > 0 {
+			var err error
+			if attemptReq, err = cloneRequest(ctx, req); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.client.Do(attemptReq)
+		if !retryable || attempt >= c.maxRetries || ctx.Err() != nil || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		delay := backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if retryAfter > retryMaxBackoff {
+					return resp, nil
+				}
+				delay = retryAfter
+			}
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isIdempotent reports whether req may be sent more than once. POST is
+// never retried: the Bokio API has no idempotency keys, so a retried create
+// could create the resource twice.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether a failed attempt is worth repeating
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// backoff returns the delay before retry attempt+1: exponential growth from
+// retryMinBackoff with jitter over the upper half of the interval
+func backoff(attempt int) time.Duration {
+	delay := retryMaxBackoff
+	if attempt < 16 {
+		delay = min(retryMinBackoff<<attempt, retryMaxBackoff)
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tokenBucket is a client-side rate limiter allowing rate requests per
+// second on average and bursts of up to burst requests. A nil bucket does
+// not limit.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// newTokenBucket returns a full bucket, or nil when rate is not positive
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), now: time.Now}
+}
+
+// reserve takes a token and returns how long to wait before using it
+func (b *tokenBucket) reserve() time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
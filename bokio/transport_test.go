@@ -0,0 +1,233 @@
+package bokio
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRetryingClient returns a client without rate limiting that records
+// its sleeps instead of waiting
+func newTestRetryingClient(maxRetries int) (*retryingClient, *[]time.Duration) {
+	var sleeps []time.Duration
+	client := &retryingClient{
+		client:     http.DefaultClient,
+		maxRetries: maxRetries,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			if d > 0 {
+				sleeps = append(sleeps, d)
+			}
+			return ctx.Err()
+		},
+	}
+	return client, &sleeps
+}
+
+// flakyServer fails the first failures requests with status and then succeeds
+func flakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write(append([]byte("ok:"), body...))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestRetryingClient(t *testing.T) {
+	t.Run("retries server errors", func(t *testing.T) {
+		server, calls := flakyServer(t, 2, http.StatusServiceUnavailable, nil)
+		client, sleeps := newTestRetryingClient(3)
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), calls.Load())
+		require.Len(t, *sleeps, 2)
+		assert.InDelta(t, retryMinBackoff, (*sleeps)[0], float64(retryMinBackoff/2))
+		assert.InDelta(t, 2*retryMinBackoff, (*sleeps)[1], float64(retryMinBackoff))
+	})
+
+	t.Run("respects Retry-After", func(t *testing.T) {
+		server, calls := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": []string{"7"}})
+		client, sleeps := newTestRetryingClient(3)
+
+		req, err := http.NewRequest(http.MethodDelete, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, []time.Duration{7 * time.Second}, *sleeps)
+	})
+
+	t.Run("gives up on long Retry-After", func(t *testing.T) {
+		server, calls := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": []string{"3600"}})
+		client, _ := newTestRetryingClient(3)
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("stops after max retries", func(t *testing.T) {
+		server, calls := flakyServer(t, 10, http.StatusBadGateway, nil)
+		client, _ := newTestRetryingClient(2)
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("does not retry POST", func(t *testing.T) {
+		server, calls := flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+		client, _ := newTestRetryingClient(3)
+
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("invoice"))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("retries PUT and replays the body", func(t *testing.T) {
+		server, calls := flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+		client, _ := newTestRetryingClient(3)
+
+		req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("invoice"))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "ok:invoice", string(body))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		server, calls := flakyServer(t, 1, http.StatusBadRequest, nil)
+		client, _ := newTestRetryingClient(3)
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("per-attempt timeout", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				time.Sleep(200 * time.Millisecond)
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client, _ := newTestRetryingClient(1)
+		client.client = &http.Client{Timeout: 50 * time.Millisecond}
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	delay, ok := parseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = parseRetryAfter("Thu, 10 Oct 2024 12:00:30 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	_, ok = parseRetryAfter("", now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC)
+	bucket := newTokenBucket(2, 3)
+	bucket.now = func() time.Time { return now }
+
+	// The burst is available immediately, then requests are spaced by 1/rate
+	for range 3 {
+		assert.Zero(t, bucket.reserve())
+	}
+	assert.Equal(t, 500*time.Millisecond, bucket.reserve())
+	assert.Equal(t, time.Second, bucket.reserve())
+
+	// Tokens refill over time but never beyond the burst
+	now = now.Add(time.Hour)
+	for range 3 {
+		assert.Zero(t, bucket.reserve())
+	}
+	assert.Equal(t, 500*time.Millisecond, bucket.reserve())
+
+	var unlimited *tokenBucket
+	assert.Zero(t, unlimited.reserve())
+}
+
+func TestNewRetryingClientDefaults(t *testing.T) {
+	client := newRetryingClient(&Config{})
+	assert.Equal(t, DefaultMaxRetries, client.maxRetries)
+	assert.Equal(t, DefaultRequestTimeout, client.client.(*http.Client).Timeout)
+	require.NotNil(t, client.limiter)
+	assert.Equal(t, DefaultRateLimit, client.limiter.rate)
+
+	client = newRetryingClient(&Config{MaxRetries: -1, RateLimit: -1, RequestTimeout: time.Second})
+	assert.Zero(t, client.maxRetries)
+	assert.Nil(t, client.limiter)
+	assert.Equal(t, time.Second, client.client.(*http.Client).Timeout)
+}
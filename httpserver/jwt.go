@@ -0,0 +1,191 @@
+package httpserver
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// jwtLeeway tolerates clock skew between the token issuer and the server
+const jwtLeeway = time.Minute
+
+// ErrInvalidToken is returned for JWTs that fail verification
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the JWT claims used to identify and authorize a principal
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	// Company and Role map subjects missing from the principals file; see
+	// Principal.Company and Principal.Role
+	Company string `json:"bokio_company,omitempty"`
+	Role    Role   `json:"bokio_role,omitempty"`
+}
+
+// audience is the aud claim, which is either a string or a list of strings
+type audience []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("aud must be a string or a list of strings: %w", err)
+	}
+	*a = list
+	return nil
+}
+
+// JWTVerifier verifies HS256 tokens signed with a shared secret or RS256
+// tokens signed with the private half of an RSA key pair
+type JWTVerifier struct {
+	// Secret verifies HS256 tokens
+	Secret []byte
+	// PublicKey verifies RS256 tokens
+	PublicKey *rsa.PublicKey
+	// Issuer and Audience, when set, must match the iss and aud claims
+	Issuer   string
+	Audience string
+
+	now func() time.Time
+}
+
+// LoadRSAPublicKey reads a PEM encoded RSA public key or certificate
+func LoadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in JWT public key file %s", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT certificate: %w", err)
+		}
+		key = cert.PublicKey
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("JWT public key in %s is not an RSA key", path)
+	}
+	return rsaKey, nil
+}
+
+// Verify checks the signature and time and audience claims of a compact JWT
+func (v *JWTVerifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed JWT", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: bad header: %v", ErrInvalidToken, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad signature encoding", ErrInvalidToken)
+	}
+	if err := v.verifySignature(header.Alg, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: bad claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.checkClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// verifySignature checks the signature for the algorithm named in the header.
+// Only algorithms with a configured key are accepted, so a token cannot pick
+// a weaker check than the server expects.
+func (v *JWTVerifier) verifySignature(alg, signed string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signed))
+	switch {
+	case alg == "HS256" && len(v.Secret) > 0:
+		mac := hmac.New(sha256.New, v.Secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+	case alg == "RS256" && v.PublicKey != nil:
+		if err := rsa.VerifyPKCS1v15(v.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, alg)
+	}
+	return nil
+}
+
+// checkClaims validates the registered claims
+func (v *JWTVerifier) checkClaims(claims *Claims) error {
+	now := time.Now()
+	if v.now != nil {
+		now = v.now()
+	}
+
+	switch {
+	case claims.Subject == "":
+		return fmt.Errorf("%w: missing sub claim", ErrInvalidToken)
+	case claims.ExpiresAt == 0:
+		return fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	case now.After(time.Unix(claims.ExpiresAt, 0).Add(jwtLeeway)):
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	case claims.NotBefore != 0 && now.Add(jwtLeeway).Before(time.Unix(claims.NotBefore, 0)):
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidToken)
+	case v.Issuer != "" && claims.Issuer != v.Issuer:
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	case v.Audience != "" && !slices.Contains(claims.Audience, v.Audience):
+		return fmt.Errorf("%w: token is not meant for audience %q", ErrInvalidToken, v.Audience)
+	}
+	return nil
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a JWT
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,141 @@
+package httpserver
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC)
+
+// signJWT builds a compact JWT; key is a []byte secret for HS256 or an
+// *rsa.PrivateKey for RS256
+func signJWT(t *testing.T, alg string, key any, claims map[string]any) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub": "ci-bot",
+		"iss": "https://auth.example.com",
+		"aud": []string{"bokio-mcp", "other"},
+		"exp": testNow.Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTVerifierHS256(t *testing.T) {
+	secret := []byte("shared-secret")
+	verifier := &JWTVerifier{Secret: secret, Issuer: "https://auth.example.com", Audience: "bokio-mcp", now: func() time.Time { return testNow }}
+
+	claims, err := verifier.Verify(signJWT(t, "HS256", secret, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "ci-bot", claims.Subject)
+
+	tests := []struct {
+		name     string
+		token    string
+		errorMsg string
+	}{
+		{"wrong secret", signJWT(t, "HS256", []byte("other"), validClaims()), "signature mismatch"},
+		{"unconfigured algorithm", signJWT(t, "none", []byte(""), validClaims()), "unsupported algorithm"},
+		{"malformed", "abc.def", "malformed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(tt.token)
+			require.ErrorIs(t, err, ErrInvalidToken)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+
+	claimTests := []struct {
+		name     string
+		modify   func(map[string]any)
+		errorMsg string
+	}{
+		{"expired", func(c map[string]any) { c["exp"] = testNow.Add(-2 * time.Minute).Unix() }, "expired"},
+		{"missing exp", func(c map[string]any) { delete(c, "exp") }, "missing exp"},
+		{"not yet valid", func(c map[string]any) { c["nbf"] = testNow.Add(time.Hour).Unix() }, "not valid yet"},
+		{"wrong issuer", func(c map[string]any) { c["iss"] = "https://evil.example.com" }, "unexpected issuer"},
+		{"wrong audience", func(c map[string]any) { c["aud"] = "other" }, "audience"},
+		{"missing subject", func(c map[string]any) { delete(c, "sub") }, "missing sub"},
+	}
+	for _, tt := range claimTests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.modify(claims)
+			_, err := verifier.Verify(signJWT(t, "HS256", secret, claims))
+			require.ErrorIs(t, err, ErrInvalidToken)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+
+	t.Run("expiry leeway", func(t *testing.T) {
+		claims := validClaims()
+		claims["exp"] = testNow.Add(-30 * time.Second).Unix()
+		_, err := verifier.Verify(signJWT(t, "HS256", secret, claims))
+		require.NoError(t, err)
+	})
+}
+
+func TestJWTVerifierRS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	publicKey, err := LoadRSAPublicKey(path)
+	require.NoError(t, err)
+	verifier := &JWTVerifier{PublicKey: publicKey, now: func() time.Time { return testNow }}
+
+	claims := validClaims()
+	claims["bokio_company"] = "acme"
+	claims["bokio_role"] = "read-write"
+	verified, err := verifier.Verify(signJWT(t, "RS256", privateKey, claims))
+	require.NoError(t, err)
+	assert.Equal(t, "acme", verified.Company)
+	assert.Equal(t, RoleReadWrite, verified.Role)
+
+	// Without a secret, HS256 tokens are refused rather than checked with the public key
+	_, err = verifier.Verify(signJWT(t, "HS256", der, validClaims()))
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = verifier.Verify(signJWT(t, "RS256", otherKey, validClaims()))
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,201 @@
+// Package httpserver serves the MCP server over streamable HTTP to remote
+// clients, authenticating each request with an API key or a JWT and mapping
+// the caller to a Bokio company and role
+package httpserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Role is the access level of a principal
+type Role string
+
+const (
+	// RoleReadOnly principals may only call tools that do not change data
+	RoleReadOnly Role = "read-only"
+	// RoleReadWrite principals may call every tool the server allows
+	RoleReadWrite Role = "read-write"
+)
+
+// parseRole validates a role, an empty role meaning read-only
+func parseRole(value Role) (Role, error) {
+	switch value {
+	case "", RoleReadOnly:
+		return RoleReadOnly, nil
+	case RoleReadWrite:
+		return RoleReadWrite, nil
+	}
+	return "", fmt.Errorf("invalid role %q (use %s or %s)", value, RoleReadOnly, RoleReadWrite)
+}
+
+// Principal is a remote MCP client allowed to use the server
+type Principal struct {
+	// Name identifies the principal in logs
+	Name string `json:"name"`
+	// APIKeySHA256 is the hex encoded SHA-256 hash of the principal's API key,
+	// so the key itself does not have to be written to the principals file
+	APIKeySHA256 string `json:"api_key_sha256,omitempty"`
+	// APIKeyEnv names an environment variable holding the principal's API key
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+	// Subject matches the sub claim of JWTs issued to the principal
+	Subject string `json:"subject,omitempty"`
+	// Company is the company ID or tenant alias the principal is restricted
+	// to; empty allows every company
+	Company string `json:"company,omitempty"`
+	// Role is the principal's access level, read-only when empty
+	Role Role `json:"role,omitempty"`
+}
+
+// ReadOnly reports whether the principal may only read
+func (p *Principal) ReadOnly() bool {
+	return p.Role != RoleReadWrite
+}
+
+// principalsFile is the on-disk format of the principal registry
+type principalsFile struct {
+	Principals []Principal `json:"principals"`
+}
+
+// PrincipalRegistry maps API keys and JWT subjects to principals
+type PrincipalRegistry struct {
+	principals []Principal
+	byKeyHash  map[string]*Principal
+	bySubject  map[string]*Principal
+}
+
+// NewPrincipalRegistry validates the principals and builds a registry
+func NewPrincipalRegistry(principals []Principal) (*PrincipalRegistry, error) {
+	registry := &PrincipalRegistry{
+		principals: make([]Principal, len(principals)),
+		byKeyHash:  make(map[string]*Principal, len(principals)),
+		bySubject:  make(map[string]*Principal, len(principals)),
+	}
+	copy(registry.principals, principals)
+
+	names := make(map[string]bool, len(principals))
+	for i := range registry.principals {
+		principal := &registry.principals[i]
+		if principal.Name == "" {
+			return nil, fmt.Errorf("principal %d: name is required", i+1)
+		}
+		if names[principal.Name] {
+			return nil, fmt.Errorf("principal %d: duplicate name %q", i+1, principal.Name)
+		}
+		names[principal.Name] = true
+
+		role, err := parseRole(principal.Role)
+		if err != nil {
+			return nil, fmt.Errorf("principal %q: %w", principal.Name, err)
+		}
+		principal.Role = role
+
+		keyHash, err := principal.keyHash()
+		if err != nil {
+			return nil, fmt.Errorf("principal %q: %w", principal.Name, err)
+		}
+		if keyHash == "" && principal.Subject == "" {
+			return nil, fmt.Errorf("principal %q: an API key or a JWT subject is required", principal.Name)
+		}
+
+		if keyHash != "" {
+			if _, exists := registry.byKeyHash[keyHash]; exists {
+				return nil, fmt.Errorf("principal %q: API key is already used by another principal", principal.Name)
+			}
+			registry.byKeyHash[keyHash] = principal
+		}
+		if principal.Subject != "" {
+			if _, exists := registry.bySubject[principal.Subject]; exists {
+				return nil, fmt.Errorf("principal %q: duplicate subject %q", principal.Name, principal.Subject)
+			}
+			registry.bySubject[principal.Subject] = principal
+		}
+	}
+
+	return registry, nil
+}
+
+// LoadPrincipalRegistry reads a JSON principals file
+func LoadPrincipalRegistry(path string) (*PrincipalRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read principals file: %w", err)
+	}
+
+	var file principalsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse principals file %s: %w", path, err)
+	}
+
+	return NewPrincipalRegistry(file.Principals)
+}
+
+// keyHash returns the normalized hash of the principal's API key, or an
+// empty string when it has none
+func (p *Principal) keyHash() (string, error) {
+	switch {
+	case p.APIKeySHA256 != "" && p.APIKeyEnv != "":
+		return "", errors.New("set either api_key_sha256 or api_key_env, not both")
+	case p.APIKeySHA256 != "":
+		hash := strings.ToLower(p.APIKeySHA256)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return "", errors.New("api_key_sha256 must be a hex encoded SHA-256 hash")
+		}
+		return hash, nil
+	case p.APIKeyEnv != "":
+		key := os.Getenv(p.APIKeyEnv)
+		if key == "" {
+			return "", fmt.Errorf("environment variable %s holding the API key is not set", p.APIKeyEnv)
+		}
+		return hashKey(key), nil
+	}
+	return "", nil
+}
+
+// hashKey returns the hex encoded SHA-256 hash of an API key. Keys are
+// looked up by hash so comparisons never touch the key itself.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ByAPIKey returns the principal owning an API key
+func (r *PrincipalRegistry) ByAPIKey(key string) (*Principal, bool) {
+	if r == nil || key == "" {
+		return nil, false
+	}
+	principal, ok := r.byKeyHash[hashKey(key)]
+	return principal, ok
+}
+
+// BySubject returns the principal with a JWT subject
+func (r *PrincipalRegistry) BySubject(subject string) (*Principal, bool) {
+	if r == nil || subject == "" {
+		return nil, false
+	}
+	principal, ok := r.bySubject[subject]
+	return principal, ok
+}
+
+// List returns all principals
+func (r *PrincipalRegistry) List() []Principal {
+	if r == nil {
+		return nil
+	}
+	principals := make([]Principal, len(r.principals))
+	copy(principals, r.principals)
+	return principals
+}
+
+// Len returns the number of registered principals
+func (r *PrincipalRegistry) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.principals)
+}
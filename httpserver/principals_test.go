@@ -0,0 +1,78 @@
+package httpserver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPrincipalRegistry(t *testing.T) {
+	t.Setenv("ALICE_MCP_KEY", "alice-secret")
+
+	path := filepath.Join(t.TempDir(), "principals.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"principals": [
+			{"name": "alice", "api_key_env": "ALICE_MCP_KEY", "company": "acme", "role": "read-write"},
+			{"name": "bob", "api_key_sha256": "`+hashKey("bob-secret")+`"},
+			{"name": "ci", "subject": "ci-bot", "company": "globex", "role": "read-only"}
+		]
+	}`), 0o600))
+
+	registry, err := LoadPrincipalRegistry(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, registry.Len())
+
+	alice, ok := registry.ByAPIKey("alice-secret")
+	require.True(t, ok)
+	assert.Equal(t, "alice", alice.Name)
+	assert.False(t, alice.ReadOnly())
+
+	bob, ok := registry.ByAPIKey("bob-secret")
+	require.True(t, ok)
+	assert.Equal(t, RoleReadOnly, bob.Role)
+	assert.True(t, bob.ReadOnly())
+
+	ci, ok := registry.BySubject("ci-bot")
+	require.True(t, ok)
+	assert.Equal(t, "globex", ci.Company)
+
+	_, ok = registry.ByAPIKey("wrong")
+	assert.False(t, ok)
+	_, ok = registry.ByAPIKey("")
+	assert.False(t, ok)
+	_, ok = registry.BySubject("someone")
+	assert.False(t, ok)
+
+	var none *PrincipalRegistry
+	_, ok = none.ByAPIKey("alice-secret")
+	assert.False(t, ok)
+	assert.Zero(t, none.Len())
+}
+
+func TestNewPrincipalRegistryValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		principals []Principal
+		errorMsg   string
+	}{
+		{"missing name", []Principal{{Subject: "a"}}, "name is required"},
+		{"duplicate name", []Principal{{Name: "a", Subject: "a"}, {Name: "a", Subject: "b"}}, "duplicate name"},
+		{"no credentials", []Principal{{Name: "a"}}, "API key or a JWT subject is required"},
+		{"bad role", []Principal{{Name: "a", Subject: "a", Role: "admin"}}, "invalid role"},
+		{"bad hash", []Principal{{Name: "a", APIKeySHA256: "abc"}}, "hex encoded SHA-256"},
+		{"unset env", []Principal{{Name: "a", APIKeyEnv: "BOKIO_MCP_TEST_UNSET_KEY"}}, "is not set"},
+		{"shared key", []Principal{{Name: "a", APIKeySHA256: hashKey("k")}, {Name: "b", APIKeySHA256: hashKey("k")}}, "already used"},
+		{"duplicate subject", []Principal{{Name: "a", Subject: "s"}, {Name: "b", Subject: "s"}}, "duplicate subject"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPrincipalRegistry(tt.principals)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
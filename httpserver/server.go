@@ -0,0 +1,391 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// DefaultAddr is the address the HTTP transport listens on by default
+	DefaultAddr = ":8080"
+	// MCPPath is the path of the streamable HTTP endpoint
+	MCPPath = "/mcp"
+
+	// sessionHeader carries the MCP session ID of streamable HTTP requests
+	sessionHeader = "Mcp-Session-Id"
+	// shutdownTimeout bounds the graceful shutdown of open connections
+	shutdownTimeout = 10 * time.Second
+)
+
+// ErrUnauthenticated is returned for requests without valid credentials
+var ErrUnauthenticated = errors.New("missing or invalid credentials")
+
+// Authenticator identifies the principal behind a request from an API key or
+// a JWT, sent as a bearer token or in the X-API-Key header
+type Authenticator struct {
+	Principals *PrincipalRegistry
+	// JWT verifies bearer tokens; nil disables JWT authentication
+	JWT *JWTVerifier
+}
+
+// Enabled reports whether any credentials are configured
+func (a *Authenticator) Enabled() bool {
+	return a != nil && (a.Principals.Len() > 0 || a.JWT != nil)
+}
+
+// Authenticate returns the principal the request's credentials belong to
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); token == "" && auth != "" {
+		scheme, value, _ := strings.Cut(auth, " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			return nil, fmt.Errorf("%w: unsupported authorization scheme %q", ErrUnauthenticated, scheme)
+		}
+		token = strings.TrimSpace(value)
+	}
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	if principal, ok := a.Principals.ByAPIKey(token); ok {
+		return principal, nil
+	}
+	if a.JWT == nil || strings.Count(token, ".") != 2 {
+		return nil, ErrUnauthenticated
+	}
+
+	claims, err := a.JWT.Verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if principal, ok := a.Principals.BySubject(claims.Subject); ok {
+		return principal, nil
+	}
+
+	// Subjects missing from the principals file are described by the token.
+	// Only the principals file can grant access to every company, so tokens
+	// minted for other purposes do not reach all tenants.
+	role, err := parseRole(claims.Role)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if claims.Company == "" {
+		return nil, fmt.Errorf("%w: token for unregistered subject %q has no bokio_company claim", ErrUnauthenticated, claims.Subject)
+	}
+	return &Principal{Name: "jwt:" + claims.Subject, Subject: claims.Subject, Company: claims.Company, Role: role}, nil
+}
+
+// ServerFactory builds the MCP server used by the sessions of a principal
+type ServerFactory func(principal *Principal) (*mcp.Server, error)
+
+// Handler serves the streamable MCP transport. Every request is
+// authenticated, each principal gets its own MCP server restricted to its
+// company and role, and sessions can only be used by the principal that
+// opened them.
+type Handler struct {
+	auth      *Authenticator
+	newServer ServerFactory
+	transport http.Handler
+
+	mu sync.Mutex
+	// servers holds the servers of principals with open sessions or
+	// requests in flight, keyed by principal key
+	servers map[string]*principalServer
+	// sessions maps open session IDs to the principal key owning them
+	sessions map[string]string
+}
+
+// principalServer is the server of a principal and the number of open
+// sessions and requests in flight using it
+type principalServer struct {
+	server *mcp.Server
+	users  int
+}
+
+// principalKey identifies the context value holding the request's principal
+type principalKey struct{}
+
+// PrincipalFromContext returns the principal of an authenticated request
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// NewHandler returns a handler authenticating requests with auth and
+// serving each principal from a server built by newServer
+func NewHandler(auth *Authenticator, newServer ServerFactory) *Handler {
+	h := &Handler{
+		auth:      auth,
+		newServer: newServer,
+		servers:   make(map[string]*principalServer),
+		sessions:  make(map[string]string),
+	}
+	h.transport = mcp.NewStreamableHTTPHandler(h.serverFor, nil)
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.auth.Authenticate(r)
+	if err != nil {
+		slog.Warn("Rejected MCP request", "remote_addr", r.RemoteAddr, "error", err)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="bokio-mcp"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	key := principalCacheKey(principal)
+
+	server, err := h.acquireServer(principal, key)
+	if err != nil {
+		slog.Error("Failed to create MCP server", "principal", principal.Name, "error", err)
+		http.Error(w, "server misconfigured for this principal", http.StatusInternalServerError)
+		return
+	}
+	defer h.releaseServer(key)
+
+	sessionID := r.Header.Get(sessionHeader)
+	if sessionID != "" {
+		if status := h.checkSession(sessionID, key); status != 0 {
+			slog.Warn("Rejected MCP request for a session the principal does not own", "principal", principal.Name, "status", status)
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), principalKey{}, principal))
+	if sessionID != "" {
+		h.transport.ServeHTTP(w, r)
+		if r.Method == http.MethodDelete {
+			// The transport closes the session on DELETE
+			h.forgetSession(sessionID)
+		}
+		return
+	}
+	h.transport.ServeHTTP(&sessionRecorder{ResponseWriter: w, record: func(id string) {
+		h.bindSession(id, key, server)
+		slog.Info("Opened MCP session", "principal", principal.Name, "company", principal.Company, "role", principal.Role)
+	}}, r)
+}
+
+// serverFor returns the server of the request's principal to the MCP transport
+func (h *Handler) serverFor(r *http.Request) *mcp.Server {
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ps, ok := h.servers[principalCacheKey(principal)]; ok {
+		return ps.server
+	}
+	return nil
+}
+
+// acquireServer returns the server of a principal for a request, building
+// it when the principal has no sessions or other requests in flight. Each
+// call must be paired with releaseServer.
+func (h *Handler) acquireServer(principal *Principal, key string) (*mcp.Server, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ps, ok := h.servers[key]
+	if !ok {
+		server, err := h.newServer(principal)
+		if err != nil {
+			return nil, err
+		}
+		ps = &principalServer{server: server}
+		h.servers[key] = ps
+	}
+	ps.users++
+	return ps.server, nil
+}
+
+// releaseServer ends a use of a principal's server. Servers are dropped
+// once unused, so principals described by JWT claims do not pile up.
+func (h *Handler) releaseServer(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.releaseServerLocked(key)
+}
+
+func (h *Handler) releaseServerLocked(key string) {
+	ps, ok := h.servers[key]
+	if !ok {
+		return
+	}
+	ps.users--
+	if ps.users <= 0 {
+		delete(h.servers, key)
+	}
+}
+
+// checkSession returns the status rejecting a request of the principal with
+// key for session id, or 0 when the principal owns the session. Unknown
+// sessions are rejected too: the transport may still hold a session whose
+// owner is not known here, and it must not be handed to another principal.
+func (h *Handler) checkSession(id, key string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	owner, ok := h.sessions[id]
+	switch {
+	case !ok:
+		return http.StatusNotFound
+	case owner != key:
+		return http.StatusForbidden
+	}
+	return 0
+}
+
+// bindSession records the principal owning a new session, which keeps the
+// principal's server. Ownership lasts until the client deletes the session
+// or its MCP connection ends.
+func (h *Handler) bindSession(id, key string, server *mcp.Server) {
+	h.mu.Lock()
+	if _, ok := h.sessions[id]; !ok {
+		h.sessions[id] = key
+		h.servers[key].users++
+	}
+	h.mu.Unlock()
+
+	for ss := range server.Sessions() {
+		if ss.ID() == id {
+			go func() {
+				_ = ss.Wait()
+				h.forgetSession(id)
+			}()
+			return
+		}
+	}
+}
+
+// forgetSession drops the owner of a closed session, so later requests for
+// it are rejected, and releases the owner's server
+func (h *Handler) forgetSession(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key, ok := h.sessions[id]
+	if !ok {
+		return
+	}
+	delete(h.sessions, id)
+	h.releaseServerLocked(key)
+}
+
+// principalCacheKey identifies principals with the same identity and access.
+// Principals described by JWT claims may differ between tokens.
+func principalCacheKey(p *Principal) string {
+	return p.Name + "\x00" + p.Company + "\x00" + string(p.Role)
+}
+
+// sessionRecorder captures the session ID the transport assigns when it
+// writes the response headers
+type sessionRecorder struct {
+	http.ResponseWriter
+	record  func(id string)
+	written bool
+}
+
+func (w *sessionRecorder) capture() {
+	if w.written {
+		return
+	}
+	w.written = true
+	if id := w.Header().Get(sessionHeader); id != "" {
+		w.record(id)
+	}
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *sessionRecorder) WriteHeader(status int) {
+	w.capture()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (w *sessionRecorder) Write(data []byte) (int, error) {
+	w.capture()
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush implements http.Flusher so streamed responses are not buffered
+func (w *sessionRecorder) Flush() {
+	w.capture()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *sessionRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Options configures the HTTP listener
+type Options struct {
+	// Addr is the listen address, DefaultAddr when empty
+	Addr string
+	// TLSCertFile and TLSKeyFile enable HTTPS when both are set
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// Serve listens on opts.Addr and serves handler at MCPPath, with an
+// unauthenticated health check at /healthz, until ctx is cancelled
+func Serve(ctx context.Context, opts Options, handler http.Handler) error {
+	if (opts.TLSCertFile == "") != (opts.TLSKeyFile == "") {
+		return errors.New("TLS needs both a certificate and a key file")
+	}
+	addr := opts.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(MCPPath, handler)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("HTTP server shutdown incomplete", "error", err)
+		}
+	}()
+
+	var err error
+	if opts.TLSCertFile != "" {
+		slog.Info("Serving MCP over HTTPS", "addr", addr, "path", MCPPath)
+		err = server.ListenAndServeTLS(opts.TLSCertFile, opts.TLSKeyFile)
+	} else {
+		slog.Info("Serving MCP over HTTP", "addr", addr, "path", MCPPath)
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		<-shutdownDone
+		return nil
+	}
+	return err
+}
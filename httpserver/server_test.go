@@ -0,0 +1,214 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransport stands in for the MCP transport, opening a session for
+// requests without a session ID and recording the server it was given
+type fakeTransport struct {
+	handler  *Handler
+	sessions int
+	servers  []*mcp.Server
+}
+
+func (f *fakeTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.servers = append(f.servers, f.handler.serverFor(r))
+	if r.Header.Get(sessionHeader) == "" {
+		f.sessions++
+		w.Header().Set(sessionHeader, string(rune('a'+f.sessions)))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func newTestHandler(t *testing.T) (*Handler, *fakeTransport, map[string]int) {
+	t.Helper()
+	registry, err := NewPrincipalRegistry([]Principal{
+		{Name: "alice", APIKeySHA256: hashKey("alice-key"), Company: "acme", Role: RoleReadWrite},
+		{Name: "bob", APIKeySHA256: hashKey("bob-key")},
+		{Name: "ci", Subject: "ci-bot", Company: "globex"},
+	})
+	require.NoError(t, err)
+
+	auth := &Authenticator{
+		Principals: registry,
+		JWT:        &JWTVerifier{Secret: []byte("secret"), now: func() time.Time { return testNow }},
+	}
+	built := map[string]int{}
+	handler := NewHandler(auth, func(principal *Principal) (*mcp.Server, error) {
+		built[principal.Name]++
+		return mcp.NewServer("test", "0", nil), nil
+	})
+	transport := &fakeTransport{handler: handler}
+	handler.transport = transport
+	return handler, transport, built
+}
+
+func serve(handler http.Handler, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, MCPPath, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlerAuthentication(t *testing.T) {
+	handler, transport, built := newTestHandler(t)
+
+	rec := serve(handler, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+
+	rec = serve(handler, http.Header{"Authorization": {"Bearer wrong"}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = serve(handler, http.Header{"Authorization": {"Basic YWxpY2U6a2V5"}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Zero(t, transport.sessions)
+
+	rec = serve(handler, http.Header{"Authorization": {"Bearer alice-key"}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(handler, http.Header{"X-Api-Key": {"alice-key"}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, built["alice"], "servers are built once per principal")
+	require.Len(t, transport.servers, 2)
+	assert.NotNil(t, transport.servers[0])
+	assert.Same(t, transport.servers[0], transport.servers[1])
+
+	// JWT subjects map to registered principals, unknown subjects use the token's claims
+	token := signJWT(t, "HS256", []byte("secret"), map[string]any{"sub": "ci-bot", "exp": testNow.Add(time.Hour).Unix()})
+	rec = serve(handler, http.Header{"Authorization": {"Bearer " + token}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, built["ci"])
+
+	token = signJWT(t, "HS256", []byte("secret"), map[string]any{"sub": "dana", "exp": testNow.Add(time.Hour).Unix(), "bokio_company": "acme"})
+	principal, err := handler.auth.Authenticate(&http.Request{Header: http.Header{"Authorization": {"Bearer " + token}}})
+	require.NoError(t, err)
+	assert.Equal(t, "jwt:dana", principal.Name)
+	assert.Equal(t, "acme", principal.Company)
+	assert.True(t, principal.ReadOnly())
+
+	// Unregistered subjects without a company are not granted every company
+	token = signJWT(t, "HS256", []byte("secret"), map[string]any{"sub": "dana", "exp": testNow.Add(time.Hour).Unix(), "bokio_role": "read-write"})
+	rec = serve(handler, http.Header{"Authorization": {"Bearer " + token}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Zero(t, built["jwt:dana"])
+
+	token = signJWT(t, "HS256", []byte("secret"), map[string]any{"sub": "dana", "exp": testNow.Add(-time.Hour).Unix(), "bokio_company": "acme"})
+	rec = serve(handler, http.Header{"Authorization": {"Bearer " + token}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestHandlerSessionBinding(t *testing.T) {
+	handler, _, _ := newTestHandler(t)
+
+	rec := serve(handler, http.Header{"Authorization": {"Bearer alice-key"}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	sessionID := rec.Header().Get(sessionHeader)
+	require.NotEmpty(t, sessionID)
+
+	rec = serve(handler, http.Header{"Authorization": {"Bearer alice-key"}, sessionHeader: {sessionID}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(handler, http.Header{"Authorization": {"Bearer bob-key"}, sessionHeader: {sessionID}})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// Sessions not opened through the handler are never passed on
+	rec = serve(handler, http.Header{"Authorization": {"Bearer bob-key"}, sessionHeader: {"unknown"}})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Deleting a session releases it for nobody
+	req := httptest.NewRequest(http.MethodDelete, MCPPath, nil)
+	req.Header.Set("Authorization", "Bearer alice-key")
+	req.Header.Set(sessionHeader, sessionID)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	rec = serve(handler, http.Header{"Authorization": {"Bearer bob-key"}, sessionHeader: {sessionID}})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serve(handler, http.Header{"Authorization": {"Bearer alice-key"}, sessionHeader: {sessionID}})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandlerServerEviction(t *testing.T) {
+	handler, _, built := newTestHandler(t)
+	servers := func() int {
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		return len(handler.servers)
+	}
+
+	rec := serve(handler, http.Header{"Authorization": {"Bearer alice-key"}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	sessionID := rec.Header().Get(sessionHeader)
+	assert.Equal(t, 1, servers(), "open sessions keep the server")
+
+	// Rejected requests do not leave a server behind
+	rec = serve(handler, http.Header{"Authorization": {"Bearer bob-key"}, sessionHeader: {sessionID}})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, 1, built["bob"])
+	assert.Equal(t, 1, servers())
+
+	req := httptest.NewRequest(http.MethodDelete, MCPPath, nil)
+	req.Header.Set("Authorization", "Bearer alice-key")
+	req.Header.Set(sessionHeader, sessionID)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Zero(t, servers(), "the server is dropped with the last session")
+
+	rec = serve(handler, http.Header{"Authorization": {"Bearer alice-key"}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2, built["alice"])
+}
+
+func TestHandlerServerError(t *testing.T) {
+	registry, err := NewPrincipalRegistry([]Principal{{Name: "alice", APIKeySHA256: hashKey("alice-key"), Company: "unknown"}})
+	require.NoError(t, err)
+	handler := NewHandler(&Authenticator{Principals: registry}, func(*Principal) (*mcp.Server, error) {
+		return nil, assert.AnError
+	})
+
+	rec := serve(handler, http.Header{"Authorization": {"Bearer alice-key"}})
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+// authTransport adds an API key to every request
+type authTransport string
+
+func (key authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("X-API-Key", string(key))
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestHandlerSessionLifecycle(t *testing.T) {
+	handler, _, _ := newTestHandler(t)
+	handler.transport = mcp.NewStreamableHTTPHandler(handler.serverFor, nil)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := mcp.NewClient("test", "0", nil)
+	session, err := client.Connect(context.Background(), mcp.NewStreamableClientTransport(server.URL,
+		&mcp.StreamableClientTransportOptions{HTTPClient: &http.Client{Transport: authTransport("alice-key")}}))
+	require.NoError(t, err)
+
+	handler.mu.Lock()
+	require.Len(t, handler.sessions, 1)
+	var sessionID string
+	for id := range handler.sessions {
+		sessionID = id
+	}
+	handler.mu.Unlock()
+	assert.Equal(t, http.StatusForbidden, handler.checkSession(sessionID, principalCacheKey(&Principal{Name: "bob"})))
+
+	require.NoError(t, session.Close())
+	assert.Eventually(t, func() bool {
+		return handler.checkSession(sessionID, principalCacheKey(&Principal{Name: "alice", Company: "acme", Role: RoleReadWrite})) == http.StatusNotFound
+	}, time.Second, 10*time.Millisecond, "closed sessions are released")
+}
@@ -2,13 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/httpserver"
 	"github.com/klowdo/bokio-mcp/tools"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -16,6 +22,9 @@ import (
 const (
 	serverName    = "bokio-mcp"
 	serverVersion = "0.1.0"
+
+	// validationTimeout bounds the startup credential check
+	validationTimeout = 15 * time.Second
 )
 
 func main() {
@@ -38,80 +47,293 @@ func main() {
 		cancel()
 	}()
 
-	if err := run(ctx); err != nil {
+	opts, err := parseFlags(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		os.Exit(2)
+	}
+
+	// `bokio-mcp login` runs the OAuth2 flow interactively and exits
+	if flag.Arg(0) == "login" {
+		if err := login(ctx, opts); err != nil {
+			slog.Error("Login failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := run(ctx, opts); err != nil {
 		slog.Error("Server failed", "error", err)
 		os.Exit(1)
 	}
 }
 
-func run(ctx context.Context) error {
-	// Load configuration from environment
-	config, err := loadConfig()
+// login performs the OAuth2 authorization code flow and stores the token
+func login(ctx context.Context, opts *serveOptions) error {
+	config, err := loadConfig(opts)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Initialize Bokio API client using only generated clients
 	bokioClient, err := bokio.NewAuthClient(config)
 	if err != nil {
 		return fmt.Errorf("failed to create Bokio auth client: %w", err)
 	}
 
-	// Create MCP server
-	server := mcp.NewServer(serverName, serverVersion, nil)
-
-	// Register tools with the server using ONLY generated API clients
-
-	// Register pure generated journal tools (working demonstration)
-	if err := tools.RegisterGeneratedJournalTools(server, bokioClient); err != nil {
-		return fmt.Errorf("failed to register generated journal tools: %w", err)
+	authURL, done, err := bokioClient.StartAuthorization(ctx)
+	if err != nil {
+		return err
 	}
 
-	// Register customer management tools using generated clients
-	if err := tools.RegisterCustomerTools(server, bokioClient); err != nil {
-		return fmt.Errorf("failed to register customer tools: %w", err)
+	fmt.Fprintf(os.Stderr, "Open this URL in your browser to authorize bokio-mcp:\n\n%s\n\n", authURL)
+	if err := <-done; err != nil {
+		return err
 	}
 
-	// Register item management tools using generated clients
-	if err := tools.RegisterItemTools(server, bokioClient); err != nil {
-		return fmt.Errorf("failed to register item tools: %w", err)
+	slog.Info("Authorization complete, token stored")
+	return nil
+}
+
+func run(ctx context.Context, opts *serveOptions) error {
+	// Load configuration from the config file, environment and flags
+	config, err := loadConfig(opts)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Register invoice management tools using generated clients
-	if err := tools.RegisterInvoiceTools(server, bokioClient); err != nil {
-		return fmt.Errorf("failed to register invoice tools: %w", err)
+	// Initialize Bokio API client using only generated clients
+	bokioClient, err := bokio.NewAuthClient(config)
+	if err != nil {
+		return fmt.Errorf("failed to create Bokio auth client: %w", err)
 	}
 
-	// Register upload management tools using generated clients
-	if err := tools.RegisterUploadTools(server, bokioClient); err != nil {
-		return fmt.Errorf("failed to register upload tools: %w", err)
+	// Fail fast on bad credentials instead of on the first tool call
+	if err := validateCredentials(ctx, bokioClient); err != nil {
+		return err
 	}
 
-	// TODO: Migrate remaining tools to use generated clients
-	// The old tools used manual types that don't exist in the actual API schema
-	// They need to be rewritten to use the generated client methods and types
+	authMethod := "Integration Token"
+	switch {
+	case bokioClient.UsesClientCredentials():
+		authMethod = "OAuth2 Client Credentials"
+	case bokioClient.UsesOAuth():
+		authMethod = "OAuth2"
+	}
 
 	slog.Info("Starting Bokio MCP server",
 		"name", serverName,
 		"version", serverVersion,
+		"transport", opts.String(),
 		"bokio_base_url", config.BaseURL,
-		"auth_method", "Integration Token",
+		"auth_method", authMethod,
 		"authenticated", bokioClient.IsAuthenticated(),
+		"companies", bokioClient.Tenants().Len(),
 		"read_only_mode", config.ReadOnly)
 
-	// Create and start the MCP server with stdio transport
-	transport := mcp.NewStdioTransport()
-	return server.Run(ctx, transport)
+	switch opts.Transport {
+	case "stdio":
+		server, err := newServer(bokioClient, config.ToolGroups, false)
+		if err != nil {
+			return err
+		}
+		// Create and start the MCP server with stdio transport
+		transport := mcp.NewStdioTransport()
+		return server.Run(ctx, transport)
+	case "http":
+		return serveHTTP(ctx, opts, bokioClient, config.ToolGroups)
+	default:
+		return fmt.Errorf("unknown transport %q (use stdio or http)", opts.Transport)
+	}
+}
+
+// toolGroup is a set of related tools that can be enabled in the configuration
+type toolGroup struct {
+	name     string
+	register func(*mcp.Server, *bokio.AuthClient) error
+	// local groups are not served to remote clients over HTTP
+	local bool
+}
+
+// toolGroups lists the tool groups in registration order. All tools use
+// ONLY generated API clients.
+var toolGroups = []toolGroup{
+	// OAuth2 authentication tools. The flow listens for the callback on the
+	// server host and replaces the token shared by every principal, so it is
+	// left to the local operator.
+	{"auth", tools.RegisterAuthTools, true},
+	// Company selection tools for multi-company setups
+	{"companies", tools.RegisterCompanyTools, false},
+	{"connections", tools.RegisterConnectionTools, false},
+	{"journal", tools.RegisterGeneratedJournalTools, false},
+	{"fiscal_years", tools.RegisterFiscalYearTools, false},
+	{"sie", tools.RegisterSIETools, false},
+	{"reports", tools.RegisterReportTools, false},
+	// BAS chart of accounts lookup, no Bokio API calls
+	{"accounts", tools.RegisterAccountTools, false},
+	{"customers", tools.RegisterCustomerTools, false},
+	{"items", tools.RegisterItemTools, false},
+	{"invoices", tools.RegisterInvoiceTools, false},
+	{"invoice_attachments", tools.RegisterInvoiceAttachmentTools, false},
+	{"uploads", tools.RegisterUploadTools, false},
+}
+
+// validateToolGroups checks that every enabled group exists
+func validateToolGroups(enabled []string) error {
+	for _, name := range enabled {
+		if !slices.ContainsFunc(toolGroups, func(group toolGroup) bool { return group.name == name }) {
+			names := make([]string, len(toolGroups))
+			for i, group := range toolGroups {
+				names[i] = group.name
+			}
+			return fmt.Errorf("unknown tool group %q (available: %s)", name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// enabledGroups returns the tool groups to register; every group is enabled
+// when enabled is empty. Local groups are left out of remote servers.
+func enabledGroups(enabled []string, remote bool) []toolGroup {
+	var groups []toolGroup
+	for _, group := range toolGroups {
+		if len(enabled) > 0 && !slices.Contains(enabled, group.name) {
+			continue
+		}
+		if remote && group.local {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// newServer creates an MCP server with the enabled tool groups acting
+// through client; remote servers leave out the local groups
+func newServer(bokioClient *bokio.AuthClient, enabled []string, remote bool) (*mcp.Server, error) {
+	// Create MCP server
+	server := mcp.NewServer(serverName, serverVersion, nil)
+
+	for _, group := range enabledGroups(enabled, remote) {
+		if err := group.register(server, bokioClient); err != nil {
+			return nil, fmt.Errorf("failed to register %s tools: %w", group.name, err)
+		}
+	}
+
+	// TODO: Migrate remaining tools to use generated clients
+	// The old tools used manual types that don't exist in the actual API schema
+	// They need to be rewritten to use the generated client methods and types
+
+	return server, nil
 }
 
-// loadConfig loads configuration from environment variables
-func loadConfig() (*bokio.Config, error) {
-	// Load configuration from environment
-	config := bokio.LoadConfigFromEnv()
+// serveHTTP serves the MCP server to authenticated remote clients. Each
+// principal gets a server whose tools are restricted to its company and role.
+func serveHTTP(ctx context.Context, opts *serveOptions, bokioClient *bokio.AuthClient, enabled []string) error {
+	auth, err := opts.authenticator()
+	if err != nil {
+		return err
+	}
+	if !auth.Enabled() {
+		return errors.New("the http transport requires a principals file or a JWT key for client authentication")
+	}
+
+	newPrincipalServer := func(principal *httpserver.Principal) (*mcp.Server, error) {
+		client, err := bokioClient.Restrict(principal.Company, principal.ReadOnly())
+		if err != nil {
+			return nil, fmt.Errorf("principal %s: %w", principal.Name, err)
+		}
+		return newServer(client, enabled, true)
+	}
+
+	// Catch principals mapped to unknown companies at startup
+	for _, principal := range auth.Principals.List() {
+		if _, err := bokioClient.Restrict(principal.Company, principal.ReadOnly()); err != nil {
+			return fmt.Errorf("principal %s: %w", principal.Name, err)
+		}
+	}
+	slog.Info("Configured HTTP client authentication", "principals", auth.Principals.Len(), "jwt", auth.JWT != nil)
+
+	return httpserver.Serve(ctx, httpserver.Options{
+		Addr:        opts.Addr,
+		TLSCertFile: opts.TLSCertFile,
+		TLSKeyFile:  opts.TLSKeyFile,
+	}, httpserver.NewHandler(auth, newPrincipalServer))
+}
 
-	if config.IntegrationToken == "" {
-		return nil, fmt.Errorf("BOKIO_INTEGRATION_TOKEN is required")
+// validateCredentials checks the client-wide credentials against the
+// connections endpoint and logs the tenants they can reach
+func validateCredentials(ctx context.Context, client *bokio.AuthClient) error {
+	if !client.HasClientCredentials() {
+		slog.Info("Skipping credential validation, only per-company tokens are configured")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, validationTimeout)
+	defer cancel()
+
+	connections, err := client.Connections(ctx)
+	if errors.Is(err, bokio.ErrNotAuthorized) {
+		slog.Warn("No OAuth2 token stored yet; run `bokio-mcp login` or call bokio_authenticate")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("credential validation failed: %w", err)
+	}
+
+	tenantIDs := make([]string, 0, len(connections))
+	for _, connection := range connections {
+		if connection.TenantId != nil {
+			tenantIDs = append(tenantIDs, connection.TenantId.String())
+		}
+	}
+	slog.Info("Validated Bokio credentials", "connections", len(connections), "tenant_ids", tenantIDs)
+
+	// Companies without their own token must be reachable with the shared credentials
+	for _, tenant := range client.Tenants().List() {
+		if !tenant.HasOwnToken() && !slices.Contains(tenantIDs, tenant.ID) {
+			slog.Warn("Configured company is not connected to the credentials", "company_id", tenant.ID, "alias", tenant.Alias)
+		}
+	}
+
+	return nil
+}
+
+// loadConfig loads the configuration from the config file profile, the
+// environment and the command line, logging where each setting came from
+func loadConfig(opts *serveOptions) (*bokio.Config, error) {
+	config, report, err := bokio.LoadConfig(bokio.ConfigOptions{
+		File:    opts.ConfigFile,
+		Profile: opts.Profile,
+		Flags:   opts.ConfigFlags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	logConfigReport(report)
+
+	if config.IntegrationToken == "" && config.ClientID == "" && config.TenantsFile == "" {
+		return nil, fmt.Errorf("BOKIO_INTEGRATION_TOKEN is required (or BOKIO_CLIENT_ID for OAuth2)")
+	}
+	if err := validateToolGroups(config.ToolGroups); err != nil {
+		return nil, err
 	}
 
 	return config, nil
 }
+
+// logConfigReport logs the config file and profile in use and the source of
+// every setting that is not at its default
+func logConfigReport(report *bokio.ConfigReport) {
+	if report.File == "" {
+		slog.Info("No config file found, using environment and flags", "default_path", bokio.DefaultConfigFile())
+	} else {
+		slog.Info("Loaded config file", "path", report.File, "profile", report.Profile)
+	}
+
+	for _, setting := range report.Settings {
+		attrs := []any{"setting", setting.Name, "value", setting.Value, "source", setting.Source, "from", setting.Origin}
+		if len(setting.Overrides) > 0 {
+			attrs = append(attrs, "overrides", strings.Join(setting.Overrides, ", "))
+		}
+		slog.Info("Config setting", attrs...)
+	}
+}
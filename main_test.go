@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"os"
 	"testing"
 
@@ -9,6 +10,12 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// testOptions returns options reading an empty config file, so a config file
+// on the machine running the tests cannot affect them
+func testOptions() *serveOptions {
+	return &serveOptions{ConfigFile: os.DevNull}
+}
+
 func TestLoadConfig(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -89,7 +96,7 @@ func TestLoadConfig(t *testing.T) {
 			}()
 
 			// Test loadConfig function
-			config, err := loadConfig()
+			config, err := loadConfig(testOptions())
 
 			if tt.expectError {
 				require.Error(t, err)
@@ -175,24 +182,25 @@ func TestConfigurationEdgeCases(t *testing.T) {
 		baseURL  string
 		readOnly string
 		expected bool // for ReadOnly field
+		wantErr  bool
 	}{
 		{
 			name:     "read-only with 'TRUE' (uppercase)",
 			token:    "test-token",
 			readOnly: "TRUE",
-			expected: false, // Only exactly "true" should be true
+			expected: true,
 		},
 		{
 			name:     "read-only with '1'",
 			token:    "test-token",
 			readOnly: "1",
-			expected: false, // Only exactly "true" should be true
+			expected: true,
 		},
 		{
 			name:     "read-only with 'yes'",
 			token:    "test-token",
 			readOnly: "yes",
-			expected: false, // Only exactly "true" should be true
+			wantErr:  true, // Unknown values must not silently allow writes
 		},
 		{
 			name:     "read-only exactly 'true'",
@@ -222,7 +230,11 @@ func TestConfigurationEdgeCases(t *testing.T) {
 				os.Unsetenv("BOKIO_READ_ONLY")
 			}()
 
-			config, err := loadConfig()
+			config, err := loadConfig(testOptions())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
 			require.NoError(t, err)
 			assert.Equal(t, tt.expected, config.ReadOnly)
 		})
@@ -275,7 +287,7 @@ func TestConfigValidationLogic(t *testing.T) {
 				}()
 			}
 
-			config, err := loadConfig()
+			config, err := loadConfig(testOptions())
 
 			if tt.expectError {
 				require.Error(t, err)
@@ -329,3 +341,84 @@ func TestEnvironmentVariablesPrecedence(t *testing.T) {
 		assert.Equal(t, true, config.ReadOnly)
 	})
 }
+
+func TestParseFlags(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		opts, err := parseFlags(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "stdio", opts.Transport)
+		assert.Equal(t, ":8080", opts.Addr)
+		assert.Equal(t, "stdio", opts.String())
+	})
+
+	t.Run("environment and flags", func(t *testing.T) {
+		t.Setenv("BOKIO_MCP_TRANSPORT", "http")
+		t.Setenv("BOKIO_MCP_ADDR", ":9000")
+		t.Setenv("BOKIO_MCP_JWT_SECRET", "secret")
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		opts, err := parseFlags(fs, []string{"--addr=:9443", "--tls-cert=cert.pem", "--tls-key=key.pem", "login"})
+		require.NoError(t, err)
+		assert.Equal(t, "http", opts.Transport)
+		assert.Equal(t, ":9443", opts.Addr)
+		assert.Equal(t, "https on :9443", opts.String())
+		assert.Equal(t, "login", fs.Arg(0))
+
+		auth, err := opts.authenticator()
+		require.NoError(t, err)
+		assert.True(t, auth.Enabled())
+		assert.Equal(t, []byte("secret"), auth.JWT.Secret)
+	})
+
+	t.Run("no client authentication", func(t *testing.T) {
+		opts, err := parseFlags(flag.NewFlagSet("test", flag.ContinueOnError), []string{"--transport=http"})
+		require.NoError(t, err)
+		auth, err := opts.authenticator()
+		require.NoError(t, err)
+		assert.False(t, auth.Enabled())
+	})
+}
+
+func TestParseConfigFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	opts, err := parseFlags(fs, []string{"--profile=sandbox", "--read-only", "--company=acme", "--tool-groups=invoices,customers"})
+	require.NoError(t, err)
+	assert.Equal(t, "sandbox", opts.Profile)
+	assert.Equal(t, map[string]string{"read_only": "true", "company": "acme", "tool_groups": "invoices,customers"}, opts.ConfigFlags)
+
+	// Flags that are not given do not override the environment or config file
+	opts, err = parseFlags(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	require.NoError(t, err)
+	assert.Empty(t, opts.ConfigFlags)
+}
+
+func TestValidateToolGroups(t *testing.T) {
+	require.NoError(t, validateToolGroups(nil))
+	require.NoError(t, validateToolGroups([]string{"invoices", "journal"}))
+
+	err := validateToolGroups([]string{"invoice"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown tool group "invoice"`)
+}
+
+func TestEnabledGroups(t *testing.T) {
+	names := func(groups []toolGroup) []string {
+		var names []string
+		for _, group := range groups {
+			names = append(names, group.name)
+		}
+		return names
+	}
+
+	local := names(enabledGroups(nil, false))
+	assert.Len(t, local, len(toolGroups))
+	assert.Contains(t, local, "auth")
+
+	remote := names(enabledGroups(nil, true))
+	assert.NotContains(t, remote, "auth", "remote clients cannot run the OAuth2 flow")
+	assert.Len(t, remote, len(toolGroups)-1)
+
+	assert.Equal(t, []string{"auth", "reports"}, names(enabledGroups([]string{"reports", "auth"}, false)))
+	assert.Equal(t, []string{"reports"}, names(enabledGroups([]string{"reports", "auth"}, true)))
+	assert.Empty(t, enabledGroups([]string{"auth"}, true))
+}
@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/bas"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Default result sizes of the account tools
+const (
+	defaultAccountSearchLimit  = 50
+	defaultAccountSuggestLimit = 5
+)
+
+// AccountsSearchParams defines parameters for searching the chart of accounts
+type AccountsSearchParams struct {
+	Query string `json:"query"`
+	Class int    `json:"class,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// AccountsSuggestParams defines parameters for suggesting accounts
+type AccountsSuggestParams struct {
+	Description string `json:"description"`
+	Limit       int    `json:"limit,omitempty"`
+}
+
+// AccountListResult defines the result for searching accounts
+type AccountListResult = ToolResult[[]bas.Account]
+
+// AccountSuggestionResult defines the result for suggesting accounts
+type AccountSuggestionResult = ToolResult[[]bas.Suggestion]
+
+// formatAccount renders an account as a single line
+func formatAccount(account *bas.Account) string {
+	line := fmt.Sprintf("%d %s (%s", account.Number, account.Name, account.Side)
+	if account.VATBox != "" {
+		line += ", momsdeklaration box " + account.VATBox
+	}
+	return line + ")"
+}
+
+// accountName returns the BAS name of an account, or "" when the account is
+// not in the bundled chart
+func accountName(number int32) string {
+	if account, ok := bas.Load().Account(number); ok {
+		return account.Name
+	}
+	return ""
+}
+
+// RegisterAccountTools registers tools for the bundled BAS chart of accounts
+func RegisterAccountTools(server *mcp.Server, client *bokio.AuthClient) error {
+	// Tool to look up accounts by number, name or keyword
+	searchAccountsTool := newTool(client, toolSpec[AccountsSearchParams, []bas.Account]{
+		Name:        "bokio_accounts_search",
+		Description: "Search the BAS 2024 chart of accounts by account number prefix (e.g. 19 for cash and bank), name or keyword. Returns the name, account class, normal debit/credit side and VAT rate and momsdeklaration box of each account.",
+		Handler: func(ctx context.Context, req *toolRequest, args AccountsSearchParams) (*mcp.CallToolResultFor[AccountListResult], error) {
+			if strings.TrimSpace(args.Query) == "" {
+				return nil, errors.New("query is required")
+			}
+			if args.Class != 0 && bas.ClassName(args.Class) == "" {
+				return nil, errors.New("class must be between 1 and 8")
+			}
+			limit := args.Limit
+			if limit <= 0 {
+				limit = defaultAccountSearchLimit
+			}
+
+			accounts := []bas.Account{}
+			for _, account := range bas.Load().Search(args.Query) {
+				if args.Class == 0 || account.Class == args.Class {
+					accounts = append(accounts, account)
+				}
+			}
+			total := len(accounts)
+			if total > limit {
+				accounts = accounts[:limit]
+			}
+
+			if total == 0 {
+				return structuredResult(fmt.Sprintf("No BAS accounts match %q. Try bokio_accounts_suggest with a description of the transaction.", args.Query), &accounts), nil
+			}
+			var b strings.Builder
+			fmt.Fprintf(&b, "✅ %d BAS accounts match %q", total, args.Query)
+			if total > limit {
+				fmt.Fprintf(&b, ", showing the first %d", limit)
+			}
+			b.WriteString("\n")
+			for i := range accounts {
+				fmt.Fprintf(&b, "\n%s", formatAccount(&accounts[i]))
+			}
+			return structuredResult(b.String(), &accounts), nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("query",
+				mcp.Description("Account number or prefix (e.g. 5010 or 50), or words from the account name or keywords (e.g. lokalhyra, bank fees)"),
+				mcp.Required(true),
+			),
+			mcp.Property("class",
+				mcp.Description("Only return accounts of this class, 1–8 (optional)"),
+			),
+			mcp.Property("limit",
+				mcp.Description(fmt.Sprintf("Maximum number of accounts to return (default %d)", defaultAccountSearchLimit)),
+			),
+		),
+	)
+
+	// Tool to suggest accounts for a transaction description
+	suggestAccountsTool := newTool(client, toolSpec[AccountsSuggestParams, []bas.Suggestion]{
+		Name:        "bokio_accounts_suggest",
+		Description: "Suggest BAS accounts for a transaction described in English or Swedish, such as \"office rent\" or \"software subscription\", with the reasons each account matched. Use it to pick the accounts of bokio_journal_entries_create; the counter account is usually 1930 (bank) or 2440 (supplier debts).",
+		Handler: func(ctx context.Context, req *toolRequest, args AccountsSuggestParams) (*mcp.CallToolResultFor[AccountSuggestionResult], error) {
+			if strings.TrimSpace(args.Description) == "" {
+				return nil, errors.New("description is required")
+			}
+			limit := args.Limit
+			if limit <= 0 {
+				limit = defaultAccountSuggestLimit
+			}
+
+			suggestions := bas.Load().Suggest(args.Description, limit)
+			if suggestions == nil {
+				suggestions = []bas.Suggestion{}
+			}
+			if len(suggestions) == 0 {
+				return structuredResult(fmt.Sprintf("No BAS accounts match %q. Try other words, or bokio_accounts_search with an account class or number.", args.Description), &suggestions), nil
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "✅ %d suggested accounts for %q\n", len(suggestions), args.Description)
+			for i := range suggestions {
+				s := &suggestions[i]
+				fmt.Fprintf(&b, "\n%s – %s", formatAccount(&s.Account), strings.Join(s.Reasons, ", "))
+			}
+			return structuredResult(b.String(), &suggestions), nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("description",
+				mcp.Description("What the transaction is for, e.g. \"office rent\", \"flight to Berlin\" or \"programvara\""),
+				mcp.Required(true),
+			),
+			mcp.Property("limit",
+				mcp.Description(fmt.Sprintf("Maximum number of suggestions (default %d)", defaultAccountSuggestLimit)),
+			),
+		),
+	)
+
+	addTools(server, client, searchAccountsTool, suggestAccountsTool)
+	return nil
+}
@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/klowdo/bokio-mcp/bokio/bas"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAccount(t *testing.T) {
+	chart := bas.Load()
+
+	rent, _ := chart.Account(5010)
+	assert.Equal(t, "5010 Lokalhyra (debit)", formatAccount(&rent))
+
+	vat, _ := chart.Account(2611)
+	assert.Equal(t, "2611 Utgående moms på försäljning inom Sverige, 25 % (credit, momsdeklaration box 10)", formatAccount(&vat))
+}
+
+func TestFormatJournalEntryAccountNames(t *testing.T) {
+	rent, bank, custom := int32(5010), int32(1930), int32(1999)
+	amount := 8000.0
+	entry := &company.JournalEntry{Items: &[]company.JournalEntryItem{
+		{Account: &rent, Debit: &amount},
+		{Account: &bank, Credit: &amount},
+		{Account: &custom, Debit: &amount},
+	}}
+
+	text := formatJournalEntry(entry)
+	assert.Contains(t, text, "\n5010 | Lokalhyra | 8000.00 | 0.00")
+	assert.Contains(t, text, "\n1930 | Företagskonto/checkkonto/affärskonto | 0.00 | 8000.00")
+	assert.Contains(t, text, "\n1999 |  | 8000.00 | 0.00", "accounts outside the chart have no name")
+}
@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AuthenticateParams defines parameters for starting the OAuth2 flow (no params needed)
+type AuthenticateParams struct{}
+
+// AuthenticateResult defines the result of starting the OAuth2 flow
+type AuthenticateResult struct {
+	AuthURL string `json:"auth_url,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AuthStatusParams defines parameters for checking authentication (no params needed)
+type AuthStatusParams struct{}
+
+// AuthStatusResult defines the result of checking authentication
+type AuthStatusResult struct {
+	Authenticated   bool   `json:"authenticated"`
+	Method          string `json:"method"`
+	TenantID        string `json:"tenant_id,omitempty"`
+	ExpiresAt       string `json:"expires_at,omitempty"`
+	HasRefreshToken bool   `json:"has_refresh_token,omitempty"`
+}
+
+// RegisterAuthTools registers OAuth2 authentication tools
+func RegisterAuthTools(server *mcp.Server, client *bokio.AuthClient) error {
+	// Tool to start the OAuth2 authorization code flow
+	authenticateTool := mcp.NewServerTool[AuthenticateParams, AuthenticateResult](
+		"bokio_authenticate",
+		"Start the OAuth2 authorization flow. Returns a URL the user must open to grant access; the token is stored automatically once the browser is redirected back.",
+		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[AuthenticateParams]) (*mcp.CallToolResultFor[AuthenticateResult], error) {
+			if !client.UsesOAuth() {
+				return &mcp.CallToolResultFor[AuthenticateResult]{
+					Content: []mcp.Content{
+						&mcp.TextContent{
+							Text: "OAuth2 is not configured; the server authenticates with BOKIO_INTEGRATION_TOKEN",
+						},
+					},
+				}, nil
+			}
+
+			if client.UsesClientCredentials() {
+				return &mcp.CallToolResultFor[AuthenticateResult]{
+					Content: []mcp.Content{
+						&mcp.TextContent{
+							Text: "The server uses the client_credentials grant; tokens are obtained automatically and no user authorization is needed",
+						},
+					},
+				}, nil
+			}
+
+			// The listener must outlive this tool call, so it is not bound to ctx
+			authURL, done, err := client.StartAuthorization(context.Background())
+			if err != nil {
+				return &mcp.CallToolResultFor[AuthenticateResult]{
+					Content: []mcp.Content{
+						&mcp.TextContent{
+							Text: fmt.Sprintf("Failed to start authorization: %v", err),
+						},
+					},
+					IsError: true,
+				}, nil
+			}
+
+			go func() {
+				if err := <-done; err != nil {
+					slog.Error("OAuth2 authorization failed", "error", err)
+					return
+				}
+				slog.Info("OAuth2 authorization completed")
+			}()
+
+			return &mcp.CallToolResultFor[AuthenticateResult]{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Open this URL to authorize Bokio access:\n\n%s\n\nThen call bokio_auth_status to confirm.", authURL),
+					},
+				},
+			}, nil
+		},
+	)
+
+	// Tool to check the current authentication state
+	authStatusTool := mcp.NewServerTool[AuthStatusParams, AuthStatusResult](
+		"bokio_auth_status",
+		"Check whether the server holds a valid Bokio access token",
+		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[AuthStatusParams]) (*mcp.CallToolResultFor[AuthStatusResult], error) {
+			if !client.UsesOAuth() {
+				return &mcp.CallToolResultFor[AuthStatusResult]{
+					Content: []mcp.Content{
+						&mcp.TextContent{
+							Text: fmt.Sprintf("Authenticated: %t\nMethod: integration token", client.IsAuthenticated()),
+						},
+					},
+				}, nil
+			}
+
+			method := "OAuth2"
+			if client.UsesClientCredentials() {
+				method = "OAuth2 client credentials"
+			}
+
+			token := client.CurrentToken()
+			if token == nil && client.UsesClientCredentials() {
+				return &mcp.CallToolResultFor[AuthStatusResult]{
+					Content: []mcp.Content{
+						&mcp.TextContent{
+							Text: "Authenticated: false\nMethod: " + method + "\n\nA token is requested on the first API call.",
+						},
+					},
+				}, nil
+			}
+			if token == nil {
+				return &mcp.CallToolResultFor[AuthStatusResult]{
+					Content: []mcp.Content{
+						&mcp.TextContent{
+							Text: "Authenticated: false\nMethod: OAuth2\n\nCall bokio_authenticate to authorize access.",
+						},
+					},
+				}, nil
+			}
+
+			expiresAt := "unknown"
+			if !token.Expiry.IsZero() {
+				expiresAt = token.Expiry.Format(time.RFC3339)
+			}
+
+			return &mcp.CallToolResultFor[AuthStatusResult]{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Authenticated: true\nMethod: %s\nTenant: %s\nExpires: %s\nRefresh token: %t",
+							method, token.TenantID, expiresAt, token.RefreshToken != ""),
+					},
+				},
+			}, nil
+		},
+	)
+
+	addTools(server, client, authenticateTool, authStatusTool)
+	return nil
+}
@@ -0,0 +1,208 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CompaniesListParams defines parameters for listing configured companies (no params needed)
+type CompaniesListParams struct{}
+
+// CompanySelectParams defines parameters for selecting the session's default company
+type CompanySelectParams struct {
+	Company string `json:"company"`
+}
+
+// CompanyInfo describes a company known to the server. Tokens from the
+// tenant registry are never included.
+type CompanyInfo struct {
+	ID       string `json:"id"`
+	Alias    string `json:"alias,omitempty"`
+	Name     string `json:"name,omitempty"`
+	OwnToken bool   `json:"own_token"`
+	Selected bool   `json:"selected"`
+}
+
+// CompanyListResult defines the result for listing companies
+type CompanyListResult = ToolResult[[]CompanyInfo]
+
+// CompanyResult defines the result for selecting a company
+type CompanyResult = ToolResult[CompanyInfo]
+
+// sessionCompanies remembers the company selected by each MCP session
+var sessionCompanies = struct {
+	sync.Mutex
+	selected map[*mcp.ServerSession]string
+}{selected: make(map[*mcp.ServerSession]string)}
+
+// sessionDone blocks until a session ends; replaced in tests
+var sessionDone = (*mcp.ServerSession).Wait
+
+// selectCompany sets the default company for a session. The selection is
+// dropped when the session ends.
+func selectCompany(session *mcp.ServerSession, companyID string) {
+	sessionCompanies.Lock()
+	_, existed := sessionCompanies.selected[session]
+	sessionCompanies.selected[session] = companyID
+	sessionCompanies.Unlock()
+
+	if session != nil && !existed {
+		go func() {
+			_ = sessionDone(session)
+			sessionCompanies.Lock()
+			delete(sessionCompanies.selected, session)
+			sessionCompanies.Unlock()
+		}()
+	}
+}
+
+// selectedCompany returns the company selected by a session, if any
+func selectedCompany(session *mcp.ServerSession) string {
+	sessionCompanies.Lock()
+	defer sessionCompanies.Unlock()
+	return sessionCompanies.selected[session]
+}
+
+// resolveCompanyRef determines which company a tool call targets. An explicit
+// company_id argument (ID or alias) wins, followed by the session's selected
+// company, the company the client is restricted to, the tenant registry
+// default and finally the configured company (BOKIO_COMPANY_ID). The
+// result is a company ID when the reference is known, otherwise it is
+// returned unchanged so callers report it as an invalid ID.
+func resolveCompanyRef(session *mcp.ServerSession, client *bokio.AuthClient, ref string) string {
+	if ref == "" {
+		ref = selectedCompany(session)
+	}
+	if ref == "" {
+		ref = client.RestrictedCompany()
+	}
+	if ref == "" {
+		if tenant := client.Tenants().Default(); tenant != nil {
+			ref = tenant.ID
+		}
+	}
+	if ref == "" {
+		ref = client.DefaultCompany()
+	}
+
+	if tenant, ok := client.Tenants().Resolve(ref); ok {
+		return tenant.ID
+	}
+	return ref
+}
+
+// RegisterCompanyTools registers tools for working with multiple companies
+func RegisterCompanyTools(server *mcp.Server, client *bokio.AuthClient) error {
+	// Tool to list the companies known to the server
+	listCompaniesTool := newTool(client, toolSpec[CompaniesListParams, []CompanyInfo]{
+		Name:        "bokio_companies_list",
+		Description: "List the companies configured in the tenant registry and show which one this session uses by default",
+		Handler: func(ctx context.Context, req *toolRequest, args CompaniesListParams) (*mcp.CallToolResultFor[CompanyListResult], error) {
+			current := resolveCompanyRef(req.Session, client, "")
+			tenants := client.Tenants().List()
+			if restricted := client.RestrictedCompany(); restricted != "" {
+				tenants = slices.DeleteFunc(tenants, func(tenant bokio.Tenant) bool { return tenant.ID != restricted })
+			}
+			companies := make([]CompanyInfo, 0, len(tenants))
+
+			if len(tenants) == 0 {
+				text := "No tenant registry configured (set BOKIO_TENANTS_FILE to work with several companies)"
+				if current != "" {
+					text += fmt.Sprintf("\n\nCurrent company: %s", current)
+				}
+				return structuredResult(text, &companies), nil
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "✅ %d configured companies\n", len(tenants))
+			for _, tenant := range tenants {
+				info := CompanyInfo{
+					ID:       tenant.ID,
+					Alias:    tenant.Alias,
+					Name:     tenant.Name,
+					OwnToken: tenant.HasOwnToken(),
+					Selected: tenant.ID == current,
+				}
+				companies = append(companies, info)
+
+				marker := " "
+				if info.Selected {
+					marker = "*"
+				}
+				fmt.Fprintf(&b, "\n%s %s", marker, tenant.ID)
+				if tenant.Alias != "" {
+					fmt.Fprintf(&b, " (%s)", tenant.Alias)
+				}
+				if tenant.Name != "" {
+					fmt.Fprintf(&b, " - %s", tenant.Name)
+				}
+				if info.OwnToken {
+					b.WriteString(" [own token]")
+				}
+			}
+			if current == "" {
+				b.WriteString("\n\nNo company selected; call bokio_company_select or pass company_id.")
+			}
+
+			return structuredResult(b.String(), &companies), nil
+		},
+	})
+
+	// Tool to choose the default company for the current session
+	selectCompanyTool := newTool(client, toolSpec[CompanySelectParams, CompanyInfo]{
+		Name:        "bokio_company_select",
+		Description: "Select the company used by this session when company_id is omitted from other tools",
+		Handler: func(ctx context.Context, req *toolRequest, args CompanySelectParams) (*mcp.CallToolResultFor[CompanyResult], error) {
+			ref := args.Company
+			if ref == "" {
+				return nil, errors.New("company is required (company ID or alias)")
+			}
+
+			tenant, ok := client.Tenants().Resolve(ref)
+			if !ok {
+				// Companies outside the registry can still be selected by ID
+				if companyUUID, err := uuid.Parse(ref); err == nil {
+					tenant, ok = &bokio.Tenant{ID: companyUUID.String()}, true
+				}
+			}
+			if !ok {
+				return nil, fmt.Errorf("unknown company %q; call bokio_companies_list to see the configured companies", ref)
+			}
+
+			if restricted := client.RestrictedCompany(); restricted != "" && tenant.ID != restricted {
+				return nil, fmt.Errorf("%w: %s", ErrCompanyNotAllowed, tenant.ID)
+			}
+
+			selectCompany(req.Session, tenant.ID)
+
+			return structuredResult(
+				fmt.Sprintf("✅ Selected company %s (%s) for this session", tenant.DisplayName(), tenant.ID),
+				&CompanyInfo{
+					ID:       tenant.ID,
+					Alias:    tenant.Alias,
+					Name:     tenant.Name,
+					OwnToken: tenant.HasOwnToken(),
+					Selected: true,
+				},
+			), nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("company",
+				mcp.Description("Company ID or alias from the tenant registry"),
+				mcp.Required(true),
+			),
+		),
+	)
+
+	addTools(server, client, listCompaniesTool, selectCompanyTool)
+	return nil
+}
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveCompanyRef(t *testing.T) {
+	const (
+		acmeID   = "11111111-1111-1111-1111-111111111111"
+		globexID = "22222222-2222-2222-2222-222222222222"
+		envID    = "33333333-3333-3333-3333-333333333333"
+	)
+
+	tenantsFile := filepath.Join(t.TempDir(), "tenants.json")
+	require.NoError(t, os.WriteFile(tenantsFile, []byte(`{
+		"default": "acme",
+		"companies": [
+			{"id": "`+acmeID+`", "alias": "acme"},
+			{"id": "`+globexID+`", "alias": "globex"}
+		]
+	}`), 0o600))
+
+	client, err := bokio.NewAuthClient(&bokio.Config{
+		IntegrationToken: "test-token",
+		BaseURL:          "https://api.bokio.se",
+		TenantsFile:      tenantsFile,
+	})
+	require.NoError(t, err)
+
+	plain, err := bokio.NewAuthClient(&bokio.Config{
+		IntegrationToken: "test-token",
+		BaseURL:          "https://api.bokio.se",
+		CompanyID:        envID,
+	})
+	require.NoError(t, err)
+
+	// Sessions in this test never end
+	done := make(chan struct{})
+	defer close(done)
+	sessionDone = func(*mcp.ServerSession) error {
+		<-done
+		return nil
+	}
+
+	session := &mcp.ServerSession{}
+	other := &mcp.ServerSession{}
+
+	// Registry default applies before anything is selected
+	assert.Equal(t, acmeID, resolveCompanyRef(session, client, ""))
+
+	// Aliases resolve case-insensitively, unknown references pass through
+	assert.Equal(t, globexID, resolveCompanyRef(session, client, "Globex"))
+	assert.Equal(t, "unknown", resolveCompanyRef(session, client, "unknown"))
+
+	// A selection only affects its own session and loses to an explicit argument
+	selectCompany(session, globexID)
+	assert.Equal(t, globexID, resolveCompanyRef(session, client, ""))
+	assert.Equal(t, acmeID, resolveCompanyRef(other, client, ""))
+	assert.Equal(t, acmeID, resolveCompanyRef(session, client, "acme"))
+
+	// Without a registry the configured company is the fallback
+	assert.Equal(t, envID, resolveCompanyRef(other, plain, ""))
+}
+
+func TestResolveCompanyRestricted(t *testing.T) {
+	const (
+		acmeID   = "11111111-1111-1111-1111-111111111111"
+		globexID = "22222222-2222-2222-2222-222222222222"
+	)
+
+	tenantsFile := filepath.Join(t.TempDir(), "tenants.json")
+	require.NoError(t, os.WriteFile(tenantsFile, []byte(`{
+		"default": "acme",
+		"companies": [
+			{"id": "`+acmeID+`", "alias": "acme"},
+			{"id": "`+globexID+`", "alias": "globex"}
+		]
+	}`), 0o600))
+
+	client, err := bokio.NewAuthClient(&bokio.Config{
+		IntegrationToken: "test-token",
+		BaseURL:          "https://api.bokio.se",
+		TenantsFile:      tenantsFile,
+	})
+	require.NoError(t, err)
+	restricted, err := client.Restrict("globex", true)
+	require.NoError(t, err)
+	assert.True(t, restricted.IsReadOnly())
+	assert.False(t, client.IsReadOnly())
+
+	// The restricted company replaces the registry default
+	assert.Equal(t, globexID, resolveCompanyRef(nil, restricted, ""))
+
+	resolve := func(ref string) (string, error) {
+		req := &toolRequest{Tool: "test", CompanyRef: ref}
+		err := resolveCompany(restricted)(func(context.Context, *toolRequest) error { return nil })(context.Background(), req)
+		return req.CompanyID.String(), err
+	}
+
+	companyID, err := resolve("")
+	require.NoError(t, err)
+	assert.Equal(t, globexID, companyID)
+
+	_, err = resolve("acme")
+	require.ErrorIs(t, err, ErrCompanyNotAllowed)
+	_, err = resolve("44444444-4444-4444-4444-444444444444")
+	require.ErrorIs(t, err, ErrCompanyNotAllowed)
+
+	_, err = client.Restrict("unknown", false)
+	require.Error(t, err)
+}
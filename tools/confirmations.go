@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// confirmationTTL is how long a confirmation token stays valid
+const confirmationTTL = 5 * time.Minute
+
+var (
+	// ErrConfirmationInvalid is returned for unknown, used or mismatched tokens
+	ErrConfirmationInvalid = errors.New("confirmation token is invalid or has already been used")
+	// ErrConfirmationExpired is returned for tokens older than confirmationTTL
+	ErrConfirmationExpired = errors.New("confirmation token has expired")
+)
+
+// pendingConfirmation is a destructive action waiting to be confirmed
+type pendingConfirmation struct {
+	session *mcp.ServerSession
+	action  string
+	expires time.Time
+}
+
+// confirmationStore hands out single-use tokens that bind a session to one
+// destructive action, such as deleting a specific customer
+type confirmationStore struct {
+	mu      sync.Mutex
+	pending map[string]pendingConfirmation
+	now     func() time.Time
+}
+
+// confirmations holds the pending confirmations of all sessions
+var confirmations = newConfirmationStore()
+
+func newConfirmationStore() *confirmationStore {
+	return &confirmationStore{
+		pending: make(map[string]pendingConfirmation),
+		now:     time.Now,
+	}
+}
+
+// issue creates a token confirming action within the session
+func (s *confirmationStore) issue(session *mcp.ServerSession, action string) (string, time.Time, error) {
+	raw := make([]byte, 6)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, pending := range s.pending {
+		if now.After(pending.expires) {
+			delete(s.pending, key)
+		}
+	}
+
+	expires := now.Add(confirmationTTL)
+	s.pending[token] = pendingConfirmation{session: session, action: action, expires: expires}
+	return token, expires, nil
+}
+
+// redeem consumes a token. It fails unless the token was issued to the same
+// session for the same action and has not expired.
+func (s *confirmationStore) redeem(session *mcp.ServerSession, action, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, ok := s.pending[token]
+	if !ok || pending.session != session || pending.action != action {
+		return ErrConfirmationInvalid
+	}
+	delete(s.pending, token)
+
+	if s.now().After(pending.expires) {
+		return ErrConfirmationExpired
+	}
+	return nil
+}
+
+// DeleteOutput is the structured output of delete tools. The first call of a
+// confirmed delete returns the entity with a token; the second reports Deleted.
+type DeleteOutput[T any] struct {
+	ID                string `json:"id"`
+	Entity            *T     `json:"entity,omitempty"`
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+	ExpiresAt         string `json:"expires_at,omitempty"`
+	Deleted           bool   `json:"deleted"`
+}
+
+// pendingDelete builds the output of the confirmation step of a delete tool
+func pendingDelete[T any](id uuid.UUID, entity *T, token string, expires time.Time) *DeleteOutput[T] {
+	return &DeleteOutput[T]{
+		ID:                id.String(),
+		Entity:            entity,
+		ConfirmationToken: token,
+		ExpiresAt:         expires.Format(time.RFC3339),
+	}
+}
+
+// confirmationPrompt tells the caller how to confirm a destructive action
+func confirmationPrompt(tool, token string, expires time.Time) string {
+	return fmt.Sprintf("To confirm, call %s again with the same arguments and confirmation_token %q before %s. Nothing has been deleted yet.",
+		tool, token, expires.Format(time.RFC3339))
+}
+
+// deleteFlow describes a delete tool guarded by a confirmation token
+type deleteFlow[T any] struct {
+	// Noun names the entity in messages, e.g. "customer"
+	Noun   string
+	ID     uuid.UUID
+	Token  string
+	Fetch  func(ctx context.Context) (*T, error)
+	Format func(*T) string
+	Delete func(ctx context.Context) error
+}
+
+// confirmDelete runs a two-step delete. Without a token it previews the
+// entity and issues a token; with a token it redeems it and deletes.
+func confirmDelete[T any](ctx context.Context, req *toolRequest, flow deleteFlow[T]) (*mcp.CallToolResultFor[ToolResult[DeleteOutput[T]]], error) {
+	action := fmt.Sprintf("delete %s %s/%s", flow.Noun, req.CompanyID, flow.ID)
+	label := strings.ToUpper(flow.Noun[:1]) + flow.Noun[1:]
+
+	if flow.Token == "" {
+		entity, err := flow.Fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		token, expires, err := confirmations.issue(req.Session, action)
+		if err != nil {
+			return nil, err
+		}
+		return structuredResult(
+			fmt.Sprintf("⚠️ The following %s will be deleted\n\nCompany: %s\n%s: %s\n\n%s",
+				flow.Noun, req.CompanyID, label, flow.Format(entity), confirmationPrompt(req.Tool, token, expires)),
+			pendingDelete(flow.ID, entity, token, expires),
+		), nil
+	}
+
+	if err := confirmations.redeem(req.Session, action, flow.Token); err != nil {
+		return nil, fmt.Errorf("%s was not deleted: %w; call %s without confirmation_token to get a new token", flow.Noun, err, req.Tool)
+	}
+	if err := flow.Delete(ctx); err != nil {
+		return nil, err
+	}
+
+	return structuredResult(
+		fmt.Sprintf("✅ Successfully deleted %s\n\nCompany: %s\n%s ID: %s", flow.Noun, req.CompanyID, label, flow.ID),
+		&DeleteOutput[T]{ID: flow.ID.String(), Deleted: true},
+	), nil
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmationStore(t *testing.T) {
+	now := time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC)
+	store := newConfirmationStore()
+	store.now = func() time.Time { return now }
+
+	session := &mcp.ServerSession{}
+	var other *mcp.ServerSession
+	action := "delete customer a/b"
+
+	t.Run("token is single use", func(t *testing.T) {
+		token, expires, err := store.issue(session, action)
+		require.NoError(t, err)
+		assert.Len(t, token, 12)
+		assert.Equal(t, now.Add(confirmationTTL), expires)
+
+		assert.NoError(t, store.redeem(session, action, token))
+		assert.ErrorIs(t, store.redeem(session, action, token), ErrConfirmationInvalid)
+	})
+
+	t.Run("token is bound to action and session", func(t *testing.T) {
+		token, _, err := store.issue(session, action)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, store.redeem(session, "delete customer a/c", token), ErrConfirmationInvalid)
+		assert.ErrorIs(t, store.redeem(other, action, token), ErrConfirmationInvalid)
+		assert.NoError(t, store.redeem(session, action, token))
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		assert.ErrorIs(t, store.redeem(session, action, "deadbeef0000"), ErrConfirmationInvalid)
+	})
+
+	t.Run("token expires", func(t *testing.T) {
+		token, _, err := store.issue(session, action)
+		require.NoError(t, err)
+
+		now = now.Add(confirmationTTL + time.Second)
+		assert.ErrorIs(t, store.redeem(session, action, token), ErrConfirmationExpired)
+		assert.ErrorIs(t, store.redeem(session, action, token), ErrConfirmationInvalid)
+	})
+
+	t.Run("expired tokens are pruned", func(t *testing.T) {
+		_, _, err := store.issue(session, action)
+		require.NoError(t, err)
+
+		now = now.Add(confirmationTTL + time.Second)
+		_, _, err = store.issue(session, action)
+		require.NoError(t, err)
+		assert.Len(t, store.pending, 1)
+	})
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/generated/general"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ConnectionsListParams defines parameters for listing connections (no params needed)
+type ConnectionsListParams struct{}
+
+// ConnectionsListResult defines the result for listing connections
+type ConnectionsListResult = ToolResult[[]general.Connection]
+
+// visibleConnections keeps the connections to the client's company when the
+// client is restricted to one
+func visibleConnections(client *bokio.AuthClient, connections []general.Connection) []general.Connection {
+	restricted := client.RestrictedCompany()
+	visible := []general.Connection{}
+	for _, connection := range connections {
+		if restricted == "" || (connection.TenantId != nil && connection.TenantId.String() == restricted) {
+			visible = append(visible, connection)
+		}
+	}
+	return visible
+}
+
+// RegisterConnectionTools registers tools for inspecting the integration's connections
+func RegisterConnectionTools(server *mcp.Server, client *bokio.AuthClient) error {
+	// Tool to list the tenants the current credentials can access
+	listConnectionsTool := newTool(client, toolSpec[ConnectionsListParams, []general.Connection]{
+		Name:        "bokio_connections_list",
+		Description: "List the Bokio companies (tenants) the configured credentials are connected to. Clients restricted to one company only see its connections.",
+		Handler: func(ctx context.Context, req *toolRequest, args ConnectionsListParams) (*mcp.CallToolResultFor[ConnectionsListResult], error) {
+			connections, err := client.Connections(ctx)
+			if err != nil {
+				return nil, err
+			}
+			connections = visibleConnections(client, connections)
+
+			if len(connections) == 0 {
+				return structuredResult("No connections found for the configured credentials", &connections), nil
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "✅ Successfully retrieved %d connections\n", len(connections))
+			for _, connection := range connections {
+				tenantID, connectionType := "unknown", "unknown"
+				if connection.TenantId != nil {
+					tenantID = connection.TenantId.String()
+				}
+				if connection.Type != nil {
+					connectionType = string(*connection.Type)
+				}
+				fmt.Fprintf(&b, "\nTenant: %s (%s)", tenantID, connectionType)
+				if connection.Id != nil {
+					fmt.Fprintf(&b, "\n  Connection ID: %s", connection.Id.String())
+				}
+				if tenant, ok := client.Tenants().Resolve(tenantID); ok && tenant.Alias != "" {
+					fmt.Fprintf(&b, "\n  Alias: %s", tenant.Alias)
+				}
+			}
+
+			return structuredResult(b.String(), &connections), nil
+		},
+	})
+
+	addTools(server, client, listConnectionsTool)
+	return nil
+}
@@ -0,0 +1,24 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio/generated/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisibleConnections(t *testing.T) {
+	acme := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	globex := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	connections := []general.Connection{{TenantId: &acme}, {TenantId: &globex}, {}}
+
+	client := newTestClient(t, false)
+	assert.Len(t, visibleConnections(client, connections), 3)
+
+	restricted, err := client.Restrict(globex.String(), false)
+	require.NoError(t, err)
+	assert.Equal(t, []general.Connection{{TenantId: &globex}}, visibleConnections(restricted, connections))
+	assert.Equal(t, []general.Connection{}, visibleConnections(restricted, nil))
+}
@@ -2,10 +2,10 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/klowdo/bokio-mcp/bokio"
@@ -16,18 +16,17 @@ import (
 
 // CustomersListParams defines parameters for listing customers
 type CustomersListParams struct {
-	CompanyID string  `json:"company_id"`
-	Page      *int32  `json:"page,omitempty"`
-	PageSize  *int32  `json:"page_size,omitempty"`
-	Search    *string `json:"search,omitempty"`
+	CompanyID string      `json:"company_id"`
+	Page      *int32      `json:"page,omitempty"`
+	PageSize  *int32      `json:"page_size,omitempty"`
+	All       bool        `json:"all,omitempty"`
+	MaxItems  *int        `json:"max_items,omitempty"`
+	Search    *string     `json:"search,omitempty"`
+	Filter    *FilterArgs `json:"filter,omitempty"`
 }
 
 // CustomersListResult defines the result for listing customers
-type CustomersListResult struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-}
+type CustomersListResult = ToolResult[Page[company.Customer]]
 
 // CustomerCreateParams defines parameters for creating a customer
 type CustomerCreateParams struct {
@@ -39,14 +38,11 @@ type CustomerCreateParams struct {
 	VatNumber          *string `json:"vat_number,omitempty"`
 	Type               string  `json:"type"` // "company" or "private"
 	PaymentTerms       *int    `json:"payment_terms,omitempty"`
+	IdempotencyKey     string  `json:"idempotency_key,omitempty"`
 }
 
 // CustomerCreateResult defines the result for creating a customer
-type CustomerCreateResult struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-}
+type CustomerCreateResult = ToolResult[company.Customer]
 
 // CustomerGetParams defines parameters for getting a customer
 type CustomerGetParams struct {
@@ -55,11 +51,7 @@ type CustomerGetParams struct {
 }
 
 // CustomerGetResult defines the result for getting a customer
-type CustomerGetResult struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-}
+type CustomerGetResult = ToolResult[company.Customer]
 
 // CustomerUpdateParams defines parameters for updating a customer
 type CustomerUpdateParams struct {
@@ -75,102 +67,98 @@ type CustomerUpdateParams struct {
 }
 
 // CustomerUpdateResult defines the result for updating a customer
-type CustomerUpdateResult struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+type CustomerUpdateResult = ToolResult[company.Customer]
+
+// CustomerDeleteParams defines parameters for deleting a customer
+type CustomerDeleteParams struct {
+	CompanyID         string `json:"company_id"`
+	CustomerID        string `json:"customer_id"`
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// CustomerDeleteResult defines the result for deleting a customer
+type CustomerDeleteResult = ToolResult[DeleteOutput[company.Customer]]
+
+// formatCustomer renders a customer as a single line
+func formatCustomer(customer *company.Customer) string {
+	text := fmt.Sprintf("%s (%s", customer.Name, customer.Type)
+	if customer.OrgNumber != nil && *customer.OrgNumber != "" {
+		text += ", org. no. " + *customer.OrgNumber
+	}
+	text += ")"
+	if customer.Id != nil {
+		text = fmt.Sprintf("%s: %s", customer.Id, text)
+	}
+	return text
+}
+
+// customerContacts builds the contact details of a customer from an email
+// address and phone number
+func customerContacts(email, phone *string) *[]struct {
+	Email     *string             `json:"email,omitempty"`
+	Id        *openapi_types.UUID `json:"id"`
+	IsDefault *bool               `json:"isDefault,omitempty"`
+	Name      *string             `json:"name,omitempty"`
+	Phone     *string             `json:"phone,omitempty"`
+} {
+	contacts := []struct {
+		Email     *string             `json:"email,omitempty"`
+		Id        *openapi_types.UUID `json:"id"`
+		IsDefault *bool               `json:"isDefault,omitempty"`
+		Name      *string             `json:"name,omitempty"`
+		Phone     *string             `json:"phone,omitempty"`
+	}{{
+		Email: email,
+		Phone: phone,
+	}}
+	return &contacts
+}
+
+// parseCustomerType validates a customer type argument
+func parseCustomerType(value string) (company.CustomerType, error) {
+	customerType := company.CustomerType(value)
+	if customerType != company.Company && customerType != company.Private {
+		return "", errors.New("customer type must be 'company' or 'private'")
+	}
+	return customerType, nil
 }
 
 // RegisterCustomerTools registers customer-related MCP tools using generated API clients
 func RegisterCustomerTools(server *mcp.Server, client *bokio.AuthClient) error {
 	// Tool to list customers using generated client
-	listCustomersTool := mcp.NewServerTool[CustomersListParams, CustomersListResult](
-		"bokio_customers_list",
-		"List customers for a company with optional pagination and filtering",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[CustomersListParams]) (*mcp.CallToolResultFor[CustomersListResult], error) {
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[CustomersListResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	listCustomersTool := newTool(client, toolSpec[CustomersListParams, Page[company.Customer]]{
+		Name:        "bokio_customers_list",
+		Description: "List customers for a company with optional pagination and filtering",
+		Handler: func(ctx context.Context, req *toolRequest, args CustomersListParams) (*mcp.CallToolResultFor[CustomersListResult], error) {
+			query, err := listQuery(args.Search, args.Filter, bokio.CustomerFilterFields)
 			if err != nil {
-				return &mcp.CallToolResultFor[CustomersListResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Create parameters for the generated client
-			genParams := &company.GetCustomerParams{
-				Page:     params.Arguments.Page,
-				PageSize: params.Arguments.PageSize,
-				Query:    params.Arguments.Search,
+				return nil, err
 			}
 
-			// Call the generated client method
-			resp, err := client.CompanyClient.GetCustomer(ctx, companyUUID, genParams)
+			page, err := listPages[company.Customer](ctx, req, listOptions{Page: args.Page, PageSize: args.PageSize, All: args.All, MaxItems: args.MaxItems}, "list customers",
+				func(ctx context.Context, page, pageSize *int32) (*http.Response, error) {
+					return client.CompanyClient.GetCustomer(ctx, req.CompanyID, &company.GetCustomerParams{
+						Page:     page,
+						PageSize: pageSize,
+						Query:    query,
+					})
+				})
 			if err != nil {
-				return &mcp.CallToolResultFor[CustomersListResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to list customers: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[CustomersListResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[CustomersListResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s\n\nCompany: %s\n", pageSummary("customers", page), req.CompanyID)
+			for i := range page.Items {
+				b.WriteString("\n" + formatCustomer(&page.Items[i]))
 			}
 
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[CustomersListResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully retrieved customers\n\nCompany: %s\nStatus: %d\nResponse: %v", companyIDStr, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(b.String(), page), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("page",
 				mcp.Description("Page number (optional)"),
@@ -178,158 +166,95 @@ func RegisterCustomerTools(server *mcp.Server, client *bokio.AuthClient) error {
 			mcp.Property("page_size",
 				mcp.Description("Items per page (optional)"),
 			),
+			mcp.Property("all",
+				mcp.Description("Fetch all pages instead of a single page, up to 10000 items (optional)"),
+			),
+			mcp.Property("max_items",
+				mcp.Description("Fetch pages until this many items are loaded (optional)"),
+			),
 			mcp.Property("search",
 				mcp.Description("Search customers by name or email (optional)"),
 			),
+			mcp.Property("filter",
+				mcp.Description(filterDescription+strings.Join(bokio.CustomerFilterFields.Names(), ", ")),
+			),
 		),
 	)
 
 	// Tool to create a customer using generated client
-	createCustomerTool := mcp.NewServerTool[CustomerCreateParams, CustomerCreateResult](
-		"bokio_customers_create",
-		"Create a new customer for a company",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[CustomerCreateParams]) (*mcp.CallToolResultFor[CustomerCreateResult], error) {
-			// Check read-only mode
-			if client.GetConfig().ReadOnly {
-				return &mcp.CallToolResultFor[CustomerCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Operation not allowed in read-only mode",
-						},
-					},
-				}, nil
-			}
-
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[CustomerCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	createCustomerTool := newTool(client, toolSpec[CustomerCreateParams, company.Customer]{
+		Name:        "bokio_customers_create",
+		Description: "Create a new customer for a company",
+		Write:       true,
+		Handler: func(ctx context.Context, req *toolRequest, args CustomerCreateParams) (*mcp.CallToolResultFor[CustomerCreateResult], error) {
+			if args.Name == "" {
+				return nil, errors.New("customer name is required")
+			}
+			customerType, err := parseCustomerType(args.Type)
 			if err != nil {
-				return &mcp.CallToolResultFor[CustomerCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Validate required fields
-			if params.Arguments.Name == "" {
-				return &mcp.CallToolResultFor[CustomerCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Customer name is required",
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Validate customer type
-			customerType := company.CustomerType(params.Arguments.Type)
-			if customerType != company.Company && customerType != company.Private {
-				return &mcp.CallToolResultFor[CustomerCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Customer type must be 'company' or 'private'",
-						},
-					},
-				}, nil
-			}
-
-			// Build customer object from parameters
 			customer := company.Customer{
-				Name: params.Arguments.Name,
-				Type: customerType,
-			}
-
-			// Add optional fields
-			if params.Arguments.Email != nil || params.Arguments.Phone != nil {
-				contactDetails := []struct {
-					Email     *string             `json:"email,omitempty"`
-					Id        *openapi_types.UUID `json:"id"`
-					IsDefault *bool               `json:"isDefault,omitempty"`
-					Name      *string             `json:"name,omitempty"`
-					Phone     *string             `json:"phone,omitempty"`
-				}{{
-					Email: params.Arguments.Email,
-					Phone: params.Arguments.Phone,
-				}}
-				customer.ContactsDetails = &contactDetails
-			}
-			if params.Arguments.OrganizationNumber != nil {
-				customer.OrgNumber = params.Arguments.OrganizationNumber
-			}
-			if params.Arguments.VatNumber != nil {
-				customer.VatNumber = params.Arguments.VatNumber
-			}
-			if params.Arguments.PaymentTerms != nil {
-				paymentTermsStr := fmt.Sprintf("%d", *params.Arguments.PaymentTerms)
-				customer.PaymentTerms = &paymentTermsStr
-			}
-
-			// Call the generated client method
-			resp, err := client.CompanyClient.PostCustomer(ctx, companyUUID, customer)
+				Name:      args.Name,
+				Type:      customerType,
+				OrgNumber: args.OrganizationNumber,
+				VatNumber: args.VatNumber,
+			}
+			if args.Email != nil || args.Phone != nil {
+				customer.ContactsDetails = customerContacts(args.Email, args.Phone)
+			}
+			if args.PaymentTerms != nil {
+				paymentTerms := fmt.Sprintf("%d", *args.PaymentTerms)
+				customer.PaymentTerms = &paymentTerms
+			}
+
+			outcome, err := idempotentCreate(ctx, client.Idempotency(), req, createFlow[company.Customer]{
+				Noun:    "customer",
+				Key:     args.IdempotencyKey,
+				Payload: customer,
+				Create: func(ctx context.Context, key string) (*company.Customer, error) {
+					resp, err := client.CompanyClient.PostCustomer(ctx, req.CompanyID, customer)
+					return decodeResponse[company.Customer](resp, err, "create customer")
+				},
+				ID: func(customer *company.Customer) *uuid.UUID { return customer.Id },
+				Fetch: func(ctx context.Context, id uuid.UUID) (*company.Customer, error) {
+					resp, err := client.CompanyClient.GetCustomersCustomerId(ctx, req.CompanyID, id)
+					return decodeResponse[company.Customer](resp, err, "get customer")
+				},
+				// Customers have no metadata, so match on name, type and registration numbers
+				Find: func(ctx context.Context, key string) (*company.Customer, error) {
+					query, err := bokio.And(bokio.Where("name", bokio.OpEqual, customer.Name)).Query(bokio.CustomerFilterFields)
+					if err != nil {
+						return nil, err
+					}
+					pageSize := allPageSize
+					resp, err := client.CompanyClient.GetCustomer(ctx, req.CompanyID, &company.GetCustomerParams{PageSize: &pageSize, Query: &query})
+					page, err := decodePage[company.Customer](resp, err, "search customers")
+					if err != nil {
+						return nil, err
+					}
+					for i := range page.Items {
+						found := &page.Items[i]
+						if found.Name == customer.Name && found.Type == customer.Type &&
+							equalStrings(found.OrgNumber, customer.OrgNumber) && equalStrings(found.VatNumber, customer.VatNumber) {
+							return found, nil
+						}
+					}
+					return nil, nil
+				},
+			})
 			if err != nil {
-				return &mcp.CallToolResultFor[CustomerCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to create customer: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[CustomerCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
-			}
-
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[CustomerCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[CustomerCreateResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully created customer\n\nCompany: %s\nCustomer: %s\nStatus: %d\nResponse: %v", companyIDStr, params.Arguments.Name, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			created := outcome.Resource
+			return structuredResult(fmt.Sprintf("%s\n\nCompany: %s\nCustomer: %s", idempotencySummary("customer", outcome), req.CompanyID, formatCustomer(created)), created), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("name",
 				mcp.Description("Customer name"),
@@ -354,123 +279,34 @@ func RegisterCustomerTools(server *mcp.Server, client *bokio.AuthClient) error {
 			mcp.Property("payment_terms",
 				mcp.Description("Payment terms in days (optional)"),
 			),
+			mcp.Property("idempotency_key",
+				mcp.Description(idempotencyKeyDescription),
+			),
 		),
 	)
 
 	// Tool to get a specific customer using generated client
-	getCustomerTool := mcp.NewServerTool[CustomerGetParams, CustomerGetResult](
-		"bokio_customers_get",
-		"Get a specific customer by ID",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[CustomerGetParams]) (*mcp.CallToolResultFor[CustomerGetResult], error) {
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[CustomerGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	getCustomerTool := newTool(client, toolSpec[CustomerGetParams, company.Customer]{
+		Name:        "bokio_customers_get",
+		Description: "Get a specific customer by ID",
+		Handler: func(ctx context.Context, req *toolRequest, args CustomerGetParams) (*mcp.CallToolResultFor[CustomerGetResult], error) {
+			customerID, err := parseID("customer_id", args.CustomerID)
 			if err != nil {
-				return &mcp.CallToolResultFor[CustomerGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Validate customer ID
-			if params.Arguments.CustomerID == "" {
-				return &mcp.CallToolResultFor[CustomerGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Customer ID is required",
-						},
-					},
-				}, nil
-			}
-
-			// Parse customer UUID
-			customerUUID, err := uuid.Parse(params.Arguments.CustomerID)
+			resp, err := client.CompanyClient.GetCustomersCustomerId(ctx, req.CompanyID, customerID)
+			customer, err := decodeResponse[company.Customer](resp, err, "get customer")
 			if err != nil {
-				return &mcp.CallToolResultFor[CustomerGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid customer ID format: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Call the generated client method
-			resp, err := client.CompanyClient.GetCustomersCustomerId(ctx, companyUUID, customerUUID)
-			if err != nil {
-				return &mcp.CallToolResultFor[CustomerGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to get customer: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode == http.StatusNotFound {
-				return &mcp.CallToolResultFor[CustomerGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Customer not found",
-						},
-					},
-				}, nil
-			}
-
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[CustomerGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
-			}
-
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[CustomerGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[CustomerGetResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully retrieved customer\n\nCompany: %s\nCustomer ID: %s\nStatus: %d\nResponse: %v", companyIDStr, params.Arguments.CustomerID, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("✅ Successfully retrieved customer\n\nCompany: %s\nCustomer: %s", req.CompanyID, formatCustomer(customer)), customer), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("customer_id",
 				mcp.Description("Customer UUID"),
@@ -480,174 +316,49 @@ func RegisterCustomerTools(server *mcp.Server, client *bokio.AuthClient) error {
 	)
 
 	// Tool to update a customer using generated client
-	updateCustomerTool := mcp.NewServerTool[CustomerUpdateParams, CustomerUpdateResult](
-		"bokio_customers_update",
-		"Update an existing customer",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[CustomerUpdateParams]) (*mcp.CallToolResultFor[CustomerUpdateResult], error) {
-			// Check read-only mode
-			if client.GetConfig().ReadOnly {
-				return &mcp.CallToolResultFor[CustomerUpdateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Operation not allowed in read-only mode",
-						},
-					},
-				}, nil
-			}
-
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[CustomerUpdateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
-			if err != nil {
-				return &mcp.CallToolResultFor[CustomerUpdateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Validate customer ID
-			if params.Arguments.CustomerID == "" {
-				return &mcp.CallToolResultFor[CustomerUpdateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Customer ID is required",
-						},
-					},
-				}, nil
-			}
-
-			// Parse customer UUID
-			customerUUID, err := uuid.Parse(params.Arguments.CustomerID)
+	updateCustomerTool := newTool(client, toolSpec[CustomerUpdateParams, company.Customer]{
+		Name:        "bokio_customers_update",
+		Description: "Update an existing customer",
+		Write:       true,
+		Handler: func(ctx context.Context, req *toolRequest, args CustomerUpdateParams) (*mcp.CallToolResultFor[CustomerUpdateResult], error) {
+			customerID, err := parseID("customer_id", args.CustomerID)
 			if err != nil {
-				return &mcp.CallToolResultFor[CustomerUpdateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid customer ID format: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Build customer object from parameters (only include provided fields)
-			customer := company.Customer{}
-
-			// Add optional fields only if provided
-			if params.Arguments.Name != nil {
-				customer.Name = *params.Arguments.Name
-			}
-			if params.Arguments.Email != nil || params.Arguments.Phone != nil {
-				contactDetails := []struct {
-					Email     *string             `json:"email,omitempty"`
-					Id        *openapi_types.UUID `json:"id"`
-					IsDefault *bool               `json:"isDefault,omitempty"`
-					Name      *string             `json:"name,omitempty"`
-					Phone     *string             `json:"phone,omitempty"`
-				}{{
-					Email: params.Arguments.Email,
-					Phone: params.Arguments.Phone,
-				}}
-				customer.ContactsDetails = &contactDetails
+			// Only include the provided fields
+			customer := company.Customer{
+				OrgNumber: args.OrganizationNumber,
+				VatNumber: args.VatNumber,
 			}
-			if params.Arguments.OrganizationNumber != nil {
-				customer.OrgNumber = params.Arguments.OrganizationNumber
+			if args.Name != nil {
+				customer.Name = *args.Name
 			}
-			if params.Arguments.VatNumber != nil {
-				customer.VatNumber = params.Arguments.VatNumber
+			if args.Email != nil || args.Phone != nil {
+				customer.ContactsDetails = customerContacts(args.Email, args.Phone)
 			}
-			if params.Arguments.Type != nil {
-				customerType := company.CustomerType(*params.Arguments.Type)
-				if customerType != company.Company && customerType != company.Private {
-					return &mcp.CallToolResultFor[CustomerUpdateResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: "Customer type must be 'company' or 'private'",
-							},
-						},
-					}, nil
+			if args.Type != nil {
+				if customer.Type, err = parseCustomerType(*args.Type); err != nil {
+					return nil, err
 				}
-				customer.Type = customerType
 			}
-			if params.Arguments.PaymentTerms != nil {
-				paymentTermsStr := fmt.Sprintf("%d", *params.Arguments.PaymentTerms)
-				customer.PaymentTerms = &paymentTermsStr
+			if args.PaymentTerms != nil {
+				paymentTerms := fmt.Sprintf("%d", *args.PaymentTerms)
+				customer.PaymentTerms = &paymentTerms
 			}
 
-			// Call the generated client method
-			resp, err := client.CompanyClient.PutCustomer(ctx, companyUUID, customerUUID, customer)
+			resp, err := client.CompanyClient.PutCustomer(ctx, req.CompanyID, customerID, customer)
+			updated, err := decodeResponse[company.Customer](resp, err, "update customer")
 			if err != nil {
-				return &mcp.CallToolResultFor[CustomerUpdateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to update customer: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode == http.StatusNotFound {
-				return &mcp.CallToolResultFor[CustomerUpdateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Customer not found",
-						},
-					},
-				}, nil
-			}
-
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[CustomerUpdateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[CustomerUpdateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[CustomerUpdateResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully updated customer\n\nCompany: %s\nCustomer ID: %s\nStatus: %d\nResponse: %v", companyIDStr, params.Arguments.CustomerID, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("✅ Successfully updated customer\n\nCompany: %s\nCustomer: %s", req.CompanyID, formatCustomer(updated)), updated), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("customer_id",
 				mcp.Description("Customer UUID"),
@@ -677,8 +388,56 @@ func RegisterCustomerTools(server *mcp.Server, client *bokio.AuthClient) error {
 		),
 	)
 
+	// Tool to delete a customer after an explicit confirmation
+	deleteCustomerTool := newTool(client, toolSpec[CustomerDeleteParams, DeleteOutput[company.Customer]]{
+		Name:        "bokio_customers_delete",
+		Description: "Delete a customer. The first call returns a preview and a confirmation token; call again with confirmation_token to delete.",
+		Write:       true,
+		Handler: func(ctx context.Context, req *toolRequest, args CustomerDeleteParams) (*mcp.CallToolResultFor[CustomerDeleteResult], error) {
+			customerID, err := parseID("customer_id", args.CustomerID)
+			if err != nil {
+				return nil, err
+			}
+
+			return confirmDelete(ctx, req, deleteFlow[company.Customer]{
+				Noun:  "customer",
+				ID:    customerID,
+				Token: args.ConfirmationToken,
+				Fetch: func(ctx context.Context) (*company.Customer, error) {
+					resp, err := client.CompanyClient.GetCustomersCustomerId(ctx, req.CompanyID, customerID)
+					return decodeResponse[company.Customer](resp, err, "get customer")
+				},
+				Format: formatCustomer,
+				Delete: func(ctx context.Context) error {
+					resp, err := client.CompanyClient.DeleteCustomer(ctx, req.CompanyID, customerID)
+					_, err = readResponse(resp, err, "delete customer")
+					return err
+				},
+			})
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("customer_id",
+				mcp.Description("Customer UUID"),
+				mcp.Required(true),
+			),
+			mcp.Property("confirmation_token",
+				mcp.Description("Token returned by the first call; omit it to preview the customer and get a token"),
+			),
+		),
+	)
+
 	// Register all tools
-	server.AddTools(listCustomersTool, createCustomerTool, getCustomerTool, updateCustomerTool)
+	addTools(server, client,
+		listCustomersTool,
+		createCustomerTool,
+		getCustomerTool,
+		updateCustomerTool,
+		deleteCustomerTool,
+	)
 
 	return nil
 }
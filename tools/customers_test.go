@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -347,16 +348,20 @@ func TestCustomerUpdateParams(t *testing.T) {
 
 func TestCustomerResultStructures(t *testing.T) {
 	// Test that result structures marshal/unmarshal correctly
+	customer := company.Customer{Name: "Test Customer", Type: "company"}
+
 	tests := []struct {
-		name   string
-		result interface{}
+		name     string
+		result   interface{}
+		wantData bool
 	}{
 		{
 			name: "CustomersListResult success",
 			result: CustomersListResult{
 				Success: true,
-				Data:    map[string]string{"test": "data"},
+				Data:    &Page[company.Customer]{Items: []company.Customer{customer}, CurrentPage: 1, TotalItems: 1, TotalPages: 1},
 			},
+			wantData: true,
 		},
 		{
 			name: "CustomersListResult error",
@@ -369,22 +374,25 @@ func TestCustomerResultStructures(t *testing.T) {
 			name: "CustomerCreateResult success",
 			result: CustomerCreateResult{
 				Success: true,
-				Data:    map[string]interface{}{"id": "cust-123", "name": "Test Customer"},
+				Data:    &customer,
 			},
+			wantData: true,
 		},
 		{
 			name: "CustomerGetResult",
 			result: CustomerGetResult{
 				Success: true,
-				Data:    map[string]string{"customer_id": "cust-123"},
+				Data:    &customer,
 			},
+			wantData: true,
 		},
 		{
 			name: "CustomerUpdateResult",
 			result: CustomerUpdateResult{
 				Success: true,
-				Data:    "Updated successfully",
+				Data:    &customer,
 			},
+			wantData: true,
 		},
 	}
 
@@ -401,6 +409,11 @@ func TestCustomerResultStructures(t *testing.T) {
 
 			// Verify basic structure
 			assert.Contains(t, unmarshaled, "success")
+			if tt.wantData {
+				assert.Contains(t, unmarshaled, "data")
+			} else {
+				assert.NotContains(t, unmarshaled, "data")
+			}
 		})
 	}
 }
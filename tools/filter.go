@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"errors"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+)
+
+// FilterArgs is the structured filter argument of list tools. Every
+// condition in All must match, and when Any is set at least one of its
+// conditions must match as well.
+type FilterArgs struct {
+	All []bokio.Condition `json:"all,omitempty"`
+	Any []bokio.Condition `json:"any,omitempty"`
+}
+
+// filter converts the arguments into a bokio.Filter
+func (a *FilterArgs) filter() bokio.Filter {
+	if len(a.Any) == 0 {
+		return bokio.And(a.All...)
+	}
+	alternatives := make([]bokio.Filter, len(a.Any))
+	for i, condition := range a.Any {
+		alternatives[i] = bokio.And(condition)
+	}
+	return bokio.Or(alternatives...).And(a.All...)
+}
+
+// filterDescription documents the filter argument of list tools
+const filterDescription = "Structured filter (optional), e.g. {\"all\": [{\"field\": \"status\", \"op\": \"==\", \"value\": \"paid\"}], \"any\": [...]}. " +
+	"Every condition in all must match and, when given, at least one in any. " +
+	"Operators are ==, !=, >, >=, <, <= for numbers and YYYY-MM-DD dates, and ==, !=, ~ (contains), !~ for strings. Supported fields: "
+
+// listQuery returns the query for a list endpoint from the raw query
+// argument or, when given, the structured filter validated against fields
+func listQuery(query *string, filter *FilterArgs, fields bokio.FilterFields) (*string, error) {
+	if filter == nil {
+		return query, nil
+	}
+	if query != nil && *query != "" {
+		return nil, errors.New("use either the query or the filter argument, not both")
+	}
+
+	rendered, err := filter.filter().Query(fields)
+	if err != nil {
+		return nil, err
+	}
+	if rendered == "" {
+		return nil, nil
+	}
+	return &rendered, nil
+}
@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListQuery(t *testing.T) {
+	raw := "status==paid"
+
+	t.Run("raw query", func(t *testing.T) {
+		query, err := listQuery(&raw, nil, bokio.InvoiceFilterFields)
+		require.NoError(t, err)
+		assert.Same(t, &raw, query)
+	})
+
+	t.Run("structured filter", func(t *testing.T) {
+		var args InvoiceListParams
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"filter": {
+				"all": [{"field": "totalAmount", "op": ">", "value": 1000}],
+				"any": [{"field": "status", "op": "==", "value": "paid"}, {"field": "status", "op": "==", "value": "credited"}]
+			}
+		}`), &args))
+
+		query, err := listQuery(args.Query, args.Filter, bokio.InvoiceFilterFields)
+		require.NoError(t, err)
+		require.NotNil(t, query)
+		assert.Equal(t, "status==paid&&totalAmount>1000||status==credited&&totalAmount>1000", *query)
+	})
+
+	t.Run("empty filter", func(t *testing.T) {
+		query, err := listQuery(nil, &FilterArgs{}, bokio.InvoiceFilterFields)
+		require.NoError(t, err)
+		assert.Nil(t, query)
+	})
+
+	t.Run("both", func(t *testing.T) {
+		_, err := listQuery(&raw, &FilterArgs{All: []bokio.Condition{bokio.Where("status", bokio.OpEqual, "paid")}}, bokio.InvoiceFilterFields)
+		assert.EqualError(t, err, "use either the query or the filter argument, not both")
+	})
+
+	t.Run("invalid field", func(t *testing.T) {
+		_, err := listQuery(nil, &FilterArgs{All: []bokio.Condition{bokio.Where("dueDate", bokio.OpEqual, "tomorrow")}}, bokio.UploadFilterFields)
+		assert.EqualError(t, err, `unknown filter field "dueDate", supported fields are description, journalEntryId`)
+	})
+}
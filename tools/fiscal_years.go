@@ -0,0 +1,183 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// FiscalYearsListParams defines parameters for listing fiscal years
+type FiscalYearsListParams struct {
+	CompanyID        string `json:"company_id"`
+	StartDate        string `json:"start_date,omitempty"`
+	EndDate          string `json:"end_date,omitempty"`
+	Status           string `json:"status,omitempty"`
+	AccountingMethod string `json:"accounting_method,omitempty"`
+}
+
+// FiscalYearGetParams defines parameters for getting a fiscal year. Exactly
+// one of FiscalYearID, Date or Current selects the year.
+type FiscalYearGetParams struct {
+	CompanyID    string `json:"company_id"`
+	FiscalYearID string `json:"fiscal_year_id,omitempty"`
+	Date         string `json:"date,omitempty"`
+	Current      bool   `json:"current,omitempty"`
+}
+
+// FiscalYearListResult defines the result for listing fiscal years
+type FiscalYearListResult = ToolResult[[]company.FiscalYear]
+
+// FiscalYearResult defines the result for getting a fiscal year
+type FiscalYearResult = ToolResult[company.FiscalYear]
+
+// formatFiscalYear renders a fiscal year as a single line
+func formatFiscalYear(year *company.FiscalYear) string {
+	status := "unknown"
+	if year.Status != nil {
+		status = string(*year.Status)
+	}
+	return fmt.Sprintf("%s: %s – %s (status: %s, accounting method: %s, VAT: %s)",
+		year.Id, year.StartDate.Format(bokio.DateLayout), year.EndDate.Format(bokio.DateLayout),
+		status, year.AccountingMethod, year.VatSetting)
+}
+
+// validateDateParam checks that an optional date parameter uses YYYY-MM-DD
+func validateDateParam(name, value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := time.Parse(bokio.DateLayout, value); err != nil {
+		return fmt.Errorf("%s must be a date in YYYY-MM-DD format", name)
+	}
+	return nil
+}
+
+// RegisterFiscalYearTools registers fiscal year MCP tools using generated API clients
+func RegisterFiscalYearTools(server *mcp.Server, client *bokio.AuthClient) error {
+	// Tool to list fiscal years with optional filters
+	listFiscalYearsTool := newTool(client, toolSpec[FiscalYearsListParams, []company.FiscalYear]{
+		Name:        "bokio_fiscal_years_list",
+		Description: "List fiscal years for a company, optionally filtered by date range, status and accounting method",
+		Handler: func(ctx context.Context, req *toolRequest, args FiscalYearsListParams) (*mcp.CallToolResultFor[FiscalYearListResult], error) {
+			// Validate filters before sending them to the API
+			filter := bokio.FiscalYearFilter{
+				StartDate:        args.StartDate,
+				EndDate:          args.EndDate,
+				Status:           args.Status,
+				AccountingMethod: args.AccountingMethod,
+			}
+			if err := validateDateParam("start_date", filter.StartDate); err != nil {
+				return nil, err
+			}
+			if err := validateDateParam("end_date", filter.EndDate); err != nil {
+				return nil, err
+			}
+			switch company.FiscalYearStatus(filter.Status) {
+			case "", company.Open, company.Closed:
+			default:
+				return nil, errors.New("status must be 'open' or 'closed'")
+			}
+			switch company.FiscalYearAccountingMethod(filter.AccountingMethod) {
+			case "", company.Accrual, company.Cash:
+			default:
+				return nil, errors.New("accounting method must be 'accrual' or 'cash'")
+			}
+
+			years, err := client.FiscalYears(ctx, req.CompanyID, filter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list fiscal years: %w", err)
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "✅ Successfully retrieved %d fiscal years\n\nCompany: %s\n", len(years), req.CompanyID)
+			for i := range years {
+				b.WriteString("\n" + formatFiscalYear(&years[i]))
+			}
+
+			return structuredResult(b.String(), &years), nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("start_date",
+				mcp.Description("Only fiscal years starting on or after this date (YYYY-MM-DD)"),
+			),
+			mcp.Property("end_date",
+				mcp.Description("Only fiscal years ending on or before this date (YYYY-MM-DD)"),
+			),
+			mcp.Property("status",
+				mcp.Description("Fiscal year status: open or closed"),
+			),
+			mcp.Property("accounting_method",
+				mcp.Description("Accounting method: accrual or cash"),
+			),
+		),
+	)
+
+	// Tool to get a fiscal year by ID, by date or the current open year
+	getFiscalYearTool := newTool(client, toolSpec[FiscalYearGetParams, company.FiscalYear]{
+		Name:        "bokio_fiscal_years_get",
+		Description: "Get a fiscal year by ID, the fiscal year containing a date, or the current open fiscal year",
+		Handler: func(ctx context.Context, req *toolRequest, args FiscalYearGetParams) (*mcp.CallToolResultFor[FiscalYearResult], error) {
+			selectors := 0
+			for _, set := range []bool{args.FiscalYearID != "", args.Date != "", args.Current} {
+				if set {
+					selectors++
+				}
+			}
+			if selectors != 1 {
+				return nil, errors.New("provide exactly one of fiscal_year_id, date or current")
+			}
+
+			var year *company.FiscalYear
+			var err error
+			switch {
+			case args.FiscalYearID != "":
+				fiscalYearID, parseErr := parseID("fiscal_year_id", args.FiscalYearID)
+				if parseErr != nil {
+					return nil, parseErr
+				}
+				year, err = client.FiscalYear(ctx, req.CompanyID, fiscalYearID)
+			case args.Date != "":
+				date, parseErr := time.Parse(bokio.DateLayout, args.Date)
+				if parseErr != nil {
+					return nil, errors.New("date must be a date in YYYY-MM-DD format")
+				}
+				year, err = client.FiscalYearContaining(ctx, req.CompanyID, date)
+			default:
+				year, err = client.CurrentFiscalYear(ctx, req.CompanyID, time.Now())
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get fiscal year: %w", err)
+			}
+
+			return structuredResult(fmt.Sprintf("✅ Successfully retrieved fiscal year\n\nCompany: %s\nFiscal year: %s", req.CompanyID, formatFiscalYear(year)), year), nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("fiscal_year_id",
+				mcp.Description("Fiscal year UUID"),
+			),
+			mcp.Property("date",
+				mcp.Description("Return the fiscal year containing this date (YYYY-MM-DD)"),
+			),
+			mcp.Property("current",
+				mcp.Description("Return the current open fiscal year"),
+			),
+		),
+	)
+
+	addTools(server, client, listFiscalYearsTool, getFiscalYearTool)
+	return nil
+}
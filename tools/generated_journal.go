@@ -2,130 +2,368 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/klowdo/bokio-mcp/bokio"
 	"github.com/klowdo/bokio-mcp/bokio/generated/company"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 // GeneratedJournalParams defines parameters for the generated journal tool
 type GeneratedJournalParams struct {
-	CompanyID string `json:"company_id"`
-	Page      *int32 `json:"page,omitempty"`
-	PageSize  *int32 `json:"page_size,omitempty"`
+	CompanyID string      `json:"company_id"`
+	Page      *int32      `json:"page,omitempty"`
+	PageSize  *int32      `json:"page_size,omitempty"`
+	All       bool        `json:"all,omitempty"`
+	MaxItems  *int        `json:"max_items,omitempty"`
+	Filter    *FilterArgs `json:"filter,omitempty"`
 }
 
-// GeneratedJournalResult defines the result using generated clients only
-type GeneratedJournalResult struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+// JournalEntryListResult defines the result for listing journal entries
+type JournalEntryListResult = ToolResult[Page[company.JournalEntry]]
+
+// JournalEntryResult defines the result for tools returning a single journal entry
+type JournalEntryResult = ToolResult[company.JournalEntry]
+
+// JournalEntryCreateParams defines parameters for creating a journal entry
+type JournalEntryCreateParams struct {
+	CompanyID string                     `json:"company_id"`
+	Title     string                     `json:"title"`
+	Date      string                     `json:"date"`
+	Items     []company.JournalEntryItem `json:"items"`
+	// IdempotencyKey makes retries of the create safe
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// JournalEntryGetParams defines parameters for getting or reversing a journal entry
+type JournalEntryGetParams struct {
+	CompanyID      string `json:"company_id"`
+	JournalEntryID string `json:"journal_entry_id"`
+}
+
+// formatJournalEntry renders a journal entry with its lines
+func formatJournalEntry(entry *company.JournalEntry) string {
+	var b strings.Builder
+	if entry.Id != nil {
+		fmt.Fprintf(&b, "ID: %s\n", entry.Id)
+	}
+	if entry.JournalEntryNumber != nil {
+		fmt.Fprintf(&b, "Number: %s\n", *entry.JournalEntryNumber)
+	}
+	if entry.Title != nil {
+		fmt.Fprintf(&b, "Title: %s\n", *entry.Title)
+	}
+	if entry.Date != nil {
+		fmt.Fprintf(&b, "Date: %s\n", entry.Date)
+	}
+	if entry.ReversingJournalEntryId != nil {
+		fmt.Fprintf(&b, "Reverses: %s\n", entry.ReversingJournalEntryId)
+	}
+	if entry.ReversedByJournalEntryId != nil {
+		fmt.Fprintf(&b, "Reversed by: %s\n", entry.ReversedByJournalEntryId)
+	}
+	if entry.Items != nil {
+		b.WriteString("\nAccount | Name | Debit | Credit")
+		for _, item := range *entry.Items {
+			var account int32
+			var debit, credit float64
+			if item.Account != nil {
+				account = *item.Account
+			}
+			if item.Debit != nil {
+				debit = *item.Debit
+			}
+			if item.Credit != nil {
+				credit = *item.Credit
+			}
+			fmt.Fprintf(&b, "\n%d | %s | %.2f | %.2f", account, accountName(account), debit, credit)
+		}
+	}
+	return b.String()
+}
+
+// sameJournalItems reports whether two journal entries book the same
+// amounts on the same accounts, in any order
+func sameJournalItems(a, b []company.JournalEntryItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	type line struct {
+		account       int32
+		debit, credit float64
+	}
+	key := func(item company.JournalEntryItem) line {
+		var l line
+		if item.Account != nil {
+			l.account = *item.Account
+		}
+		if item.Debit != nil {
+			l.debit = *item.Debit
+		}
+		if item.Credit != nil {
+			l.credit = *item.Credit
+		}
+		return l
+	}
+
+	counts := make(map[line]int, len(a))
+	for _, item := range a {
+		counts[key(item)]++
+	}
+	for _, item := range b {
+		l := key(item)
+		if counts[l] == 0 {
+			return false
+		}
+		counts[l]--
+	}
+	return true
 }
 
 // RegisterGeneratedJournalTools registers journal tools using ONLY generated API clients
 func RegisterGeneratedJournalTools(server *mcp.Server, client *bokio.AuthClient) error {
 	// Tool to list journal entries using generated client
-	listJournalTool := mcp.NewServerTool[GeneratedJournalParams, GeneratedJournalResult](
-		"bokio_journal_entries_list",
-		"List journal entries for a company with optional pagination",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GeneratedJournalParams]) (*mcp.CallToolResultFor[GeneratedJournalResult], error) {
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
+	listJournalTool := newTool(client, toolSpec[GeneratedJournalParams, Page[company.JournalEntry]]{
+		Name:        "bokio_journal_entries_list",
+		Description: "List journal entries for a company with optional pagination and filtering",
+		Handler: func(ctx context.Context, req *toolRequest, args GeneratedJournalParams) (*mcp.CallToolResultFor[JournalEntryListResult], error) {
+			query, err := listQuery(nil, args.Filter, bokio.JournalEntryFilterFields)
+			if err != nil {
+				return nil, err
 			}
 
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[GeneratedJournalResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
+			page, err := listPages[company.JournalEntry](ctx, req, listOptions{Page: args.Page, PageSize: args.PageSize, All: args.All, MaxItems: args.MaxItems}, "list journal entries",
+				func(ctx context.Context, page, pageSize *int32) (*http.Response, error) {
+					return client.CompanyClient.GetJournalentry(ctx, req.CompanyID, &company.GetJournalentryParams{
+						Page:     page,
+						PageSize: pageSize,
+						Query:    query,
+					})
+				})
+			if err != nil {
+				return nil, err
 			}
 
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s\n\nCompany: %s\n", pageSummary("journal entries", page), req.CompanyID)
+			for _, entry := range page.Items {
+				var number, title, date string
+				if entry.JournalEntryNumber != nil {
+					number = *entry.JournalEntryNumber
+				}
+				if entry.Title != nil {
+					title = *entry.Title
+				}
+				if entry.Date != nil {
+					date = entry.Date.Format(bokio.DateLayout)
+				}
+				fmt.Fprintf(&b, "\n%s %s %s", number, date, title)
+			}
+
+			return structuredResult(b.String(), page), nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("page",
+				mcp.Description("Page number (optional)"),
+			),
+			mcp.Property("page_size",
+				mcp.Description("Items per page (optional)"),
+			),
+			mcp.Property("all",
+				mcp.Description("Fetch all pages instead of a single page, up to 10000 items (optional)"),
+			),
+			mcp.Property("max_items",
+				mcp.Description("Fetch pages until this many items are loaded (optional)"),
+			),
+			mcp.Property("filter",
+				mcp.Description(filterDescription+strings.Join(bokio.JournalEntryFilterFields.Names(), ", ")),
+			),
+		),
+	)
+
+	// Tool to create a journal entry using generated client
+	createJournalTool := newTool(client, toolSpec[JournalEntryCreateParams, company.JournalEntry]{
+		Name:        "bokio_journal_entries_create",
+		Description: "Create a manual journal entry. Each item books either a debit or a credit on a BAS account; use bokio_accounts_suggest or bokio_accounts_search to find the accounts.",
+		Write:       true,
+		Facts: func(ctx context.Context, req *toolRequest, args JournalEntryCreateParams) (bokio.PolicyCall, error) {
+			return journalFacts(args.Date, args.Items), nil
+		},
+		Handler: func(ctx context.Context, req *toolRequest, args JournalEntryCreateParams) (*mcp.CallToolResultFor[JournalEntryResult], error) {
+			if args.Title == "" {
+				return nil, errors.New("title is required")
+			}
+			date, err := time.Parse(bokio.DateLayout, args.Date)
 			if err != nil {
-				return &mcp.CallToolResultFor[GeneratedJournalResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
+				return nil, errors.New("date is required in YYYY-MM-DD format")
 			}
 
-			// Create parameters for the generated client
-			genParams := &company.GetJournalentryParams{
-				Page:     params.Arguments.Page,
-				PageSize: params.Arguments.PageSize,
+			// Item IDs are assigned by Bokio
+			items := make([]company.JournalEntryItem, len(args.Items))
+			for i, item := range args.Items {
+				item.Id = nil
+				items[i] = item
 			}
 
-			// Call the generated client method
-			resp, err := client.CompanyClient.GetJournalentry(ctx, companyUUID, genParams)
+			body := company.PostJournalentryJSONRequestBody{
+				Title: &args.Title,
+				Date:  &openapi_types.Date{Time: date},
+				Items: &items,
+			}
+			outcome, err := idempotentCreate(ctx, client.Idempotency(), req, createFlow[company.JournalEntry]{
+				Noun:    "journal entry",
+				Key:     args.IdempotencyKey,
+				Payload: body,
+				// Catch unbalanced or malformed entries before the API does
+				Validate: func(ctx context.Context) error {
+					err := validateJournalEntry(ctx, client, req.CompanyID, date, items)
+					var validationErr *JournalValidationError
+					switch {
+					case errors.As(err, &validationErr):
+						return fmt.Errorf("journal entry was not posted because it failed validation:\n- %s", strings.Join(validationErr.Problems, "\n- "))
+					case err != nil:
+						return fmt.Errorf("failed to validate journal entry: %w", err)
+					}
+					return nil
+				},
+				Create: func(ctx context.Context, key string) (*company.JournalEntry, error) {
+					resp, err := client.CompanyClient.PostJournalentry(ctx, req.CompanyID, body)
+					return decodeResponse[company.JournalEntry](resp, err, "create journal entry")
+				},
+				ID: func(entry *company.JournalEntry) *uuid.UUID { return entry.Id },
+				Fetch: func(ctx context.Context, id uuid.UUID) (*company.JournalEntry, error) {
+					resp, err := client.CompanyClient.GetJournalentriesJournalId(ctx, req.CompanyID, id)
+					return decodeResponse[company.JournalEntry](resp, err, "get journal entry")
+				},
+				// Journal entries have no metadata, so match on title, date and items
+				Find: func(ctx context.Context, key string) (*company.JournalEntry, error) {
+					query, err := bokio.And(
+						bokio.Where("title", bokio.OpEqual, args.Title),
+						bokio.Where("date", bokio.OpEqual, args.Date),
+					).Query(bokio.JournalEntryFilterFields)
+					if err != nil {
+						return nil, err
+					}
+					pageSize := allPageSize
+					resp, err := client.CompanyClient.GetJournalentry(ctx, req.CompanyID, &company.GetJournalentryParams{PageSize: &pageSize, Query: &query})
+					page, err := decodePage[company.JournalEntry](resp, err, "search journal entries")
+					if err != nil {
+						return nil, err
+					}
+					for i := range page.Items {
+						found := &page.Items[i]
+						if found.Items != nil && sameJournalItems(*found.Items, items) {
+							return found, nil
+						}
+					}
+					return nil, nil
+				},
+			})
 			if err != nil {
-				return &mcp.CallToolResultFor[GeneratedJournalResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to list journal entries: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[GeneratedJournalResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
+
+			entry := outcome.Resource
+			return structuredResult(fmt.Sprintf("%s\n\nCompany: %s\n%s", idempotencySummary("journal entry", outcome), req.CompanyID, formatJournalEntry(entry)), entry), nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("title",
+				mcp.Description("Journal entry title"),
+				mcp.Required(true),
+			),
+			mcp.Property("date",
+				mcp.Description("Booking date (YYYY-MM-DD)"),
+				mcp.Required(true),
+			),
+			mcp.Property("items",
+				mcp.Description("Journal entry lines, each with account (4-digit BAS account) and either debit or credit"),
+				mcp.Required(true),
+			),
+			mcp.Property("idempotency_key",
+				mcp.Description(idempotencyKeyDescription),
+			),
+		),
+	)
+
+	// Tool to get a single journal entry using generated client
+	getJournalTool := newTool(client, toolSpec[JournalEntryGetParams, company.JournalEntry]{
+		Name:        "bokio_journal_entries_get",
+		Description: "Get a specific journal entry by ID",
+		Handler: func(ctx context.Context, req *toolRequest, args JournalEntryGetParams) (*mcp.CallToolResultFor[JournalEntryResult], error) {
+			journalID, err := parseID("journal_entry_id", args.JournalEntryID)
+			if err != nil {
+				return nil, err
 			}
 
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[GeneratedJournalResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+			resp, err := client.CompanyClient.GetJournalentriesJournalId(ctx, req.CompanyID, journalID)
+			entry, err := decodeResponse[company.JournalEntry](resp, err, "get journal entry")
+			if err != nil {
+				return nil, err
 			}
 
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[GeneratedJournalResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully retrieved journal entries\n\nCompany: %s\nStatus: %d\nResponse: %v", companyIDStr, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("✅ Successfully retrieved journal entry\n\nCompany: %s\n%s", req.CompanyID, formatJournalEntry(entry)), entry), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
-			mcp.Property("page",
-				mcp.Description("Page number (optional)"),
+			mcp.Property("journal_entry_id",
+				mcp.Description("Journal entry UUID"),
+				mcp.Required(true),
 			),
-			mcp.Property("page_size",
-				mcp.Description("Items per page (optional)"),
+		),
+	)
+
+	// Tool to reverse a journal entry using generated client
+	reverseJournalTool := newTool(client, toolSpec[JournalEntryGetParams, company.JournalEntry]{
+		Name:        "bokio_journal_entries_reverse",
+		Description: "Reverse a journal entry created through the API by booking an opposite entry. Entries created in the Bokio UI or already reversed cannot be reversed.",
+		Write:       true,
+		// The reversal is checked against the date of the entry it reverses
+		Facts: func(ctx context.Context, req *toolRequest, args JournalEntryGetParams) (bokio.PolicyCall, error) {
+			return existingJournalFacts(ctx, client, req, args.JournalEntryID)
+		},
+		Handler: func(ctx context.Context, req *toolRequest, args JournalEntryGetParams) (*mcp.CallToolResultFor[JournalEntryResult], error) {
+			journalID, err := parseID("journal_entry_id", args.JournalEntryID)
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := client.CompanyClient.ReverseJournalentry(ctx, req.CompanyID, journalID)
+			entry, err := decodeResponse[company.JournalEntry](resp, err, "reverse journal entry")
+			if err != nil {
+				return nil, err
+			}
+
+			return structuredResult(fmt.Sprintf("✅ Successfully reversed journal entry %s\n\nCompany: %s\nReversal entry:\n%s", journalID, req.CompanyID, formatJournalEntry(entry)), entry), nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("journal_entry_id",
+				mcp.Description("UUID of the journal entry to reverse"),
+				mcp.Required(true),
 			),
 		),
 	)
 
-	server.AddTools(listJournalTool)
+	addTools(server, client, listJournalTool, createJournalTool, getJournalTool, reverseJournalTool)
 	return nil
 }
@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalEntryCreateParams(t *testing.T) {
+	input := `{
+		"company_id": "test-company-123",
+		"title": "Bank fee",
+		"date": "2024-10-10",
+		"items": [
+			{"account": 6570, "debit": 25},
+			{"account": 1930, "credit": 25}
+		]
+	}`
+
+	var got JournalEntryCreateParams
+	require.NoError(t, json.Unmarshal([]byte(input), &got))
+
+	assert.Equal(t, "Bank fee", got.Title)
+	assert.Equal(t, "2024-10-10", got.Date)
+	require.Len(t, got.Items, 2)
+	assert.Equal(t, int32(6570), *got.Items[0].Account)
+	assert.Equal(t, 25.0, *got.Items[0].Debit)
+	assert.Nil(t, got.Items[0].Credit)
+	assert.Equal(t, 25.0, *got.Items[1].Credit)
+}
+
+func TestSameJournalItems(t *testing.T) {
+	line := func(account int32, debit, credit float64) company.JournalEntryItem {
+		return company.JournalEntryItem{Account: &account, Debit: &debit, Credit: &credit}
+	}
+	a := []company.JournalEntryItem{line(1930, 100, 0), line(3001, 0, 80), line(2611, 0, 20)}
+	b := []company.JournalEntryItem{line(2611, 0, 20), line(1930, 100, 0), line(3001, 0, 80)}
+
+	assert.True(t, sameJournalItems(a, b))
+	assert.False(t, sameJournalItems(a, b[:2]))
+	assert.False(t, sameJournalItems(a, []company.JournalEntryItem{line(1930, 100, 0), line(3001, 0, 100), line(2611, 0, 0)}))
+}
@@ -0,0 +1,333 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var (
+	// ErrReadOnly is returned by write tools when the server runs in read-only mode
+	ErrReadOnly = errors.New("operation not allowed in read-only mode")
+	// ErrCompanyRequired is returned when no company could be resolved for a call
+	ErrCompanyRequired = errors.New("company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)")
+	// ErrCompanyNotAllowed is returned when a call targets a company outside
+	// the client's restriction, see bokio.AuthClient.Restrict
+	ErrCompanyNotAllowed = errors.New("company is not available to this client")
+)
+
+// toolRequest carries the per-call state shared by middleware and handlers
+type toolRequest struct {
+	Tool    string
+	Session *mcp.ServerSession
+	// CompanyRef is the raw company_id argument, empty when omitted
+	CompanyRef string
+	// CompanyID is the resolved company, set for tools with a company_id argument
+	CompanyID uuid.UUID
+	// ProgressToken is set when the client asked for progress notifications
+	ProgressToken any
+}
+
+// notifyProgress reports progress to the client when it asked for it
+func (r *toolRequest) notifyProgress(ctx context.Context, progress, total int, message string) {
+	if r.Session == nil || r.ProgressToken == nil {
+		return
+	}
+	err := r.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: r.ProgressToken,
+		Progress:      float64(progress),
+		Total:         float64(total),
+		Message:       message,
+	})
+	if err != nil {
+		slog.Debug("failed to send progress notification", "tool", r.Tool, "error", err)
+	}
+}
+
+// toolStep is one stage of a tool call
+type toolStep func(ctx context.Context, req *toolRequest) error
+
+// toolMiddleware wraps a tool call with a concern shared by many tools
+type toolMiddleware func(next toolStep) toolStep
+
+// toolHandler is the endpoint specific part of a tool. Returned errors are
+// reported to the client as error results.
+type toolHandler[In, Out any] func(ctx context.Context, req *toolRequest, args In) (*mcp.CallToolResultFor[ToolResult[Out]], error)
+
+// toolSpec describes a tool built by newTool
+type toolSpec[In, Out any] struct {
+	Name        string
+	Description string
+	// Write marks tools that change data in Bokio; they are refused in read-only mode
+	Write bool
+	// Facts, when set, reports the amount and booking dates of a call, which
+	// the policy checks against its amount caps and closed periods
+	Facts func(ctx context.Context, req *toolRequest, args In) (bokio.PolicyCall, error)
+	// Middleware runs after the built-in middleware, just before Handler
+	Middleware []toolMiddleware
+	Handler    toolHandler[In, Out]
+}
+
+// newTool builds an MCP tool from spec, see newToolHandler
+func newTool[In, Out any](client *bokio.AuthClient, spec toolSpec[In, Out], opts ...mcp.ToolOption) *mcp.ServerTool {
+	tool := mcp.NewServerTool(spec.Name, spec.Description, newToolHandler(client, spec), opts...)
+	return withOutputSchema[ToolResult[Out]](tool)
+}
+
+// newToolHandler wraps spec.Handler in the shared middleware. Panics are
+// recovered, write tools are refused in read-only mode, the company_id
+// argument, when In has one, is resolved into req.CompanyID and the call is
+// checked against the client's policy. Errors are returned as results with
+// IsError set.
+func newToolHandler[In, Out any](client *bokio.AuthClient, spec toolSpec[In, Out]) mcp.ToolHandlerFor[In, ToolResult[Out]] {
+	companyField, scoped := companyArgument(reflect.TypeFor[In]())
+
+	middleware := []toolMiddleware{recoverPanics}
+	if spec.Write {
+		middleware = append(middleware, requireWritable(client))
+	}
+	if scoped {
+		middleware = append(middleware, resolveCompany(client))
+	}
+
+	return func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[In]) (*mcp.CallToolResultFor[ToolResult[Out]], error) {
+		req := &toolRequest{Tool: spec.Name, Session: session, ProgressToken: params.GetProgressToken()}
+		if scoped {
+			req.CompanyRef = reflect.ValueOf(params.Arguments).FieldByIndex(companyField).String()
+		}
+
+		var result *mcp.CallToolResultFor[ToolResult[Out]]
+		steps := slices.Concat(middleware, []toolMiddleware{enforcePolicy(client, spec.Facts, params.Arguments)}, spec.Middleware)
+		call := chain(steps, func(ctx context.Context, req *toolRequest) error {
+			var err error
+			result, err = spec.Handler(ctx, req, params.Arguments)
+			return err
+		})
+		if err := call(ctx, req); err != nil {
+			return errorResult[Out](err), nil
+		}
+		return result, nil
+	}
+}
+
+// chain wraps step in middleware, the first middleware running outermost
+func chain(middleware []toolMiddleware, step toolStep) toolStep {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		step = middleware[i](step)
+	}
+	return step
+}
+
+// errorResult reports err to the client as a tool error. Bokio API errors
+// keep their category, field errors and error ID in the structured content.
+func errorResult[T any](err error) *mcp.CallToolResultFor[ToolResult[T]] {
+	text := err.Error()
+	result := ToolResult[T]{Error: text}
+
+	var apiErr *bokio.APIError
+	if errors.As(err, &apiErr) {
+		result.APIError = apiErr
+		if hint := errorHints[apiErr.Category]; hint != "" {
+			text += "\n\n" + hint
+		}
+	}
+
+	return &mcp.CallToolResultFor[ToolResult[T]]{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text,
+			},
+		},
+		IsError:           true,
+		StructuredContent: result,
+	}
+}
+
+// errorHints tells the assistant how to recover from each error category
+var errorHints = map[bokio.ErrorCategory]string{
+	bokio.CategoryValidation:  "Correct the listed fields and call the tool again.",
+	bokio.CategoryNotFound:    "Check the IDs, for example by listing the resources first.",
+	bokio.CategoryAuth:        "Check the credentials with bokio_auth_status, or run bokio_authenticate.",
+	bokio.CategoryRateLimited: "Bokio is rate limiting requests, wait before calling the tool again.",
+	bokio.CategoryServer:      "Bokio failed to handle the request, retry later and quote the Bokio error ID to Bokio support if it persists.",
+}
+
+// companyArgument finds the string field of t tagged json:"company_id"
+func companyArgument(t reflect.Type) ([]int, bool) {
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	for _, field := range reflect.VisibleFields(t) {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "company_id" && field.Type.Kind() == reflect.String {
+			return field.Index, true
+		}
+	}
+	return nil, false
+}
+
+// recoverPanics turns a panicking handler into an error result
+func recoverPanics(next toolStep) toolStep {
+	return func(ctx context.Context, req *toolRequest) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("tool handler panicked", "tool", req.Tool, "panic", r)
+				err = fmt.Errorf("internal error in %s: %v", req.Tool, r)
+			}
+		}()
+		return next(ctx, req)
+	}
+}
+
+// requireWritable refuses the call when the client is in read-only mode
+func requireWritable(client *bokio.AuthClient) toolMiddleware {
+	return func(next toolStep) toolStep {
+		return func(ctx context.Context, req *toolRequest) error {
+			if client.IsReadOnly() {
+				return ErrReadOnly
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// resolveCompany resolves the company_id argument, the session's selected
+// company or the configured default into req.CompanyID and refuses
+// companies outside the client's restriction
+func resolveCompany(client *bokio.AuthClient) toolMiddleware {
+	return func(next toolStep) toolStep {
+		return func(ctx context.Context, req *toolRequest) error {
+			ref := resolveCompanyRef(req.Session, client, req.CompanyRef)
+			if ref == "" {
+				return ErrCompanyRequired
+			}
+			companyID, err := uuid.Parse(ref)
+			if err != nil {
+				return fmt.Errorf("invalid company ID %q: %w", ref, err)
+			}
+			if restricted := client.RestrictedCompany(); restricted != "" && companyID.String() != restricted {
+				return fmt.Errorf("%w: %s", ErrCompanyNotAllowed, companyID)
+			}
+			req.CompanyID = companyID
+			return next(ctx, req)
+		}
+	}
+}
+
+// enforcePolicy refuses calls the client's policy does not allow. facts,
+// when set, supplies the amount and dates of the call.
+func enforcePolicy[In any](client *bokio.AuthClient, facts func(context.Context, *toolRequest, In) (bokio.PolicyCall, error), args In) toolMiddleware {
+	return func(next toolStep) toolStep {
+		return func(ctx context.Context, req *toolRequest) error {
+			policy := client.Policy()
+			if policy == nil {
+				return next(ctx, req)
+			}
+
+			var call bokio.PolicyCall
+			if facts != nil {
+				var err error
+				if call, err = facts(ctx, req, args); err != nil {
+					return err
+				}
+			}
+			call.Tool = req.Tool
+			if req.CompanyID != uuid.Nil {
+				call.CompanyID = req.CompanyID.String()
+			}
+			if err := policy.Check(call); err != nil {
+				return err
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// addTools adds the tools the client's policy allows to server, leaving
+// the others out of tools/list
+func addTools(server *mcp.Server, client *bokio.AuthClient, tools ...*mcp.ServerTool) {
+	policy := client.Policy()
+	visible := make([]*mcp.ServerTool, 0, len(tools))
+	for _, tool := range tools {
+		if policy.Visible(tool.Tool.Name, client.RestrictedCompany()) {
+			visible = append(visible, tool)
+		}
+	}
+	server.AddTools(visible...)
+}
+
+// parseID parses a required UUID argument
+func parseID(name, value string) (uuid.UUID, error) {
+	if value == "" {
+		return uuid.Nil, fmt.Errorf("%s is required", name)
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	return id, nil
+}
+
+// convertArgument converts a free-form JSON argument into the API type T
+func convertArgument[T any](name string, value interface{}) (T, error) {
+	var converted T
+	data, err := json.Marshal(value)
+	if err != nil {
+		return converted, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, &converted); err != nil {
+		return converted, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return converted, nil
+}
+
+// readResponse returns the body of a successful API response. action
+// describes the call for transport errors, e.g. "get invoice".
+func readResponse(resp *http.Response, err error, action string) ([]byte, error) {
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, bokio.NewAPIError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// decodeResponse decodes the JSON body of a successful API response
+func decodeResponse[T any](resp *http.Response, err error, action string) (*T, error) {
+	body, err := readResponse(resp, err, action)
+	if err != nil {
+		return nil, err
+	}
+	var decoded T
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &decoded, nil
+}
+
+// decodePage decodes a paginated list response
+func decodePage[T any](resp *http.Response, err error, action string) (*Page[T], error) {
+	list, err := decodeResponse[listResponse[T]](resp, err, action)
+	if err != nil {
+		return nil, err
+	}
+	return list.page(), nil
+}
@@ -0,0 +1,296 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, readOnly bool) *bokio.AuthClient {
+	t.Helper()
+	client, err := bokio.NewAuthClient(&bokio.Config{
+		IntegrationToken: "test-token",
+		BaseURL:          "https://api.bokio.se",
+		ReadOnly:         readOnly,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func textOf(t *testing.T, content []mcp.Content) string {
+	t.Helper()
+	require.NotEmpty(t, content)
+	return content[0].(*mcp.TextContent).Text
+}
+
+func TestNewToolHandler(t *testing.T) {
+	t.Setenv("BOKIO_COMPANY_ID", "")
+	companyID := "11111111-1111-1111-1111-111111111111"
+
+	var called bool
+	var seen *toolRequest
+	handler := func(ctx context.Context, req *toolRequest, args CustomerGetParams) (*mcp.CallToolResultFor[CustomerGetResult], error) {
+		called, seen = true, req
+		if args.CustomerID == "fail" {
+			return nil, errors.New("customer_id is broken")
+		}
+		if args.CustomerID == "panic" {
+			panic("boom")
+		}
+		return structuredResult("ok", &company.Customer{Name: "Acme AB"}), nil
+	}
+	call := func(client *bokio.AuthClient, write bool, args CustomerGetParams) *mcp.CallToolResultFor[CustomerGetResult] {
+		called, seen = false, nil
+		h := newToolHandler(client, toolSpec[CustomerGetParams, company.Customer]{Name: "test_tool", Write: write, Handler: handler})
+		result, err := h(context.Background(), nil, &mcp.CallToolParamsFor[CustomerGetParams]{Arguments: args})
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("success", func(t *testing.T) {
+		result := call(newTestClient(t, false), false, CustomerGetParams{CompanyID: companyID})
+		assert.False(t, result.IsError)
+		assert.True(t, result.StructuredContent.Success)
+		require.NotNil(t, seen)
+		assert.Equal(t, "test_tool", seen.Tool)
+		assert.Equal(t, uuid.MustParse(companyID), seen.CompanyID)
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		result := call(newTestClient(t, false), false, CustomerGetParams{CompanyID: companyID, CustomerID: "fail"})
+		assert.True(t, result.IsError)
+		assert.False(t, result.StructuredContent.Success)
+		assert.Equal(t, "customer_id is broken", result.StructuredContent.Error)
+		assert.Equal(t, "customer_id is broken", textOf(t, result.Content))
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		result := call(newTestClient(t, false), false, CustomerGetParams{CompanyID: companyID, CustomerID: "panic"})
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.StructuredContent.Error, "internal error in test_tool: boom")
+	})
+
+	t.Run("read-only write tool", func(t *testing.T) {
+		result := call(newTestClient(t, true), true, CustomerGetParams{CompanyID: companyID})
+		assert.True(t, result.IsError)
+		assert.Equal(t, ErrReadOnly.Error(), result.StructuredContent.Error)
+		assert.False(t, called)
+	})
+
+	t.Run("read-only read tool", func(t *testing.T) {
+		result := call(newTestClient(t, true), false, CustomerGetParams{CompanyID: companyID})
+		assert.False(t, result.IsError)
+		assert.True(t, called)
+	})
+
+	t.Run("missing company", func(t *testing.T) {
+		result := call(newTestClient(t, false), false, CustomerGetParams{})
+		assert.True(t, result.IsError)
+		assert.Equal(t, ErrCompanyRequired.Error(), result.StructuredContent.Error)
+		assert.False(t, called)
+	})
+
+	t.Run("invalid company", func(t *testing.T) {
+		result := call(newTestClient(t, false), false, CustomerGetParams{CompanyID: "not-a-uuid"})
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.StructuredContent.Error, `invalid company ID "not-a-uuid"`)
+		assert.False(t, called)
+	})
+
+	t.Run("company from environment", func(t *testing.T) {
+		t.Setenv("BOKIO_COMPANY_ID", companyID)
+		t.Setenv("BOKIO_INTEGRATION_TOKEN", "test-token")
+		client, err := bokio.NewAuthClient(bokio.LoadConfigFromEnv())
+		require.NoError(t, err)
+		result := call(client, false, CustomerGetParams{})
+		assert.False(t, result.IsError)
+		assert.Equal(t, uuid.MustParse(companyID), seen.CompanyID)
+	})
+}
+
+func TestNewToolHandlerPolicy(t *testing.T) {
+	companyID := "11111111-1111-1111-1111-111111111111"
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policyFile, []byte(`
+deny: [test_denied]
+amount_caps: {test_*: 100}
+companies:
+  `+companyID+`:
+    closed_periods: [{to: 2023-12-31}]
+`), 0o600))
+	client, err := bokio.NewAuthClient(&bokio.Config{IntegrationToken: "test-token", PolicyFile: policyFile})
+	require.NoError(t, err)
+
+	var called bool
+	call := func(name string, amount float64, date string) *mcp.CallToolResultFor[CustomerGetResult] {
+		called = false
+		h := newToolHandler(client, toolSpec[CustomerGetParams, company.Customer]{
+			Name: name,
+			Facts: func(ctx context.Context, req *toolRequest, args CustomerGetParams) (bokio.PolicyCall, error) {
+				return journalFacts(date, []company.JournalEntryItem{{Debit: &amount}}), nil
+			},
+			Handler: func(ctx context.Context, req *toolRequest, args CustomerGetParams) (*mcp.CallToolResultFor[CustomerGetResult], error) {
+				called = true
+				return structuredResult("ok", &company.Customer{}), nil
+			},
+		})
+		result, err := h(context.Background(), nil, &mcp.CallToolParamsFor[CustomerGetParams]{Arguments: CustomerGetParams{CompanyID: companyID}})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call("test_tool", 100, "2024-01-01")
+	assert.False(t, result.IsError)
+	assert.True(t, called)
+
+	for _, tt := range []struct {
+		tool, date string
+		amount     float64
+		errorMsg   string
+	}{
+		{"test_denied", "2024-01-01", 1, `deny pattern "test_denied"`},
+		{"test_tool", "2024-01-01", 101, "exceeds the cap"},
+		{"test_tool", "2023-06-30", 1, "for company " + companyID + ": date 2023-06-30 is in the closed period"},
+	} {
+		result := call(tt.tool, tt.amount, tt.date)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.StructuredContent.Error, tt.errorMsg)
+		assert.False(t, called)
+	}
+}
+
+func TestErrorResult(t *testing.T) {
+	apiErr := bokio.NewAPIError(http.StatusUnprocessableEntity, []byte(`{"code":"validation-error","message":"Invalid invoice","bokioErrorId":"44444444-4444-4444-4444-444444444444","errors":[{"field":"#/customerRef","message":"Customer is required"}]}`))
+	result := errorResult[company.Invoice](fmt.Errorf("create invoice: %w", apiErr))
+
+	assert.True(t, result.IsError)
+	assert.Same(t, apiErr, result.StructuredContent.APIError)
+	assert.Equal(t, "create invoice: "+apiErr.Error(), result.StructuredContent.Error)
+	assert.Equal(t, result.StructuredContent.Error+"\n\n"+errorHints[bokio.CategoryValidation], textOf(t, result.Content))
+
+	// Errors raised before calling the API carry no API details
+	result = errorResult[company.Invoice](errors.New("invoice_id is required"))
+	assert.Nil(t, result.StructuredContent.APIError)
+	assert.Equal(t, "invoice_id is required", textOf(t, result.Content))
+}
+
+func TestToolMiddlewareOrder(t *testing.T) {
+	var order []string
+	trace := func(name string) toolMiddleware {
+		return func(next toolStep) toolStep {
+			return func(ctx context.Context, req *toolRequest) error {
+				order = append(order, name)
+				return next(ctx, req)
+			}
+		}
+	}
+
+	step := chain([]toolMiddleware{trace("outer"), trace("inner")}, func(ctx context.Context, req *toolRequest) error {
+		order = append(order, "handler")
+		return nil
+	})
+	require.NoError(t, step(context.Background(), &toolRequest{}))
+	assert.Equal(t, []string{"outer", "inner", "handler"}, order)
+}
+
+func TestCompanyArgument(t *testing.T) {
+	index, ok := companyArgument(reflect.TypeFor[InvoiceGetParams]())
+	require.True(t, ok)
+	assert.Equal(t, "company-ref", reflect.ValueOf(InvoiceGetParams{CompanyID: "company-ref"}).FieldByIndex(index).String())
+
+	_, ok = companyArgument(reflect.TypeFor[CompanySelectParams]())
+	assert.False(t, ok)
+	_, ok = companyArgument(reflect.TypeFor[ConnectionsListParams]())
+	assert.False(t, ok)
+}
+
+func TestParseID(t *testing.T) {
+	id, err := parseID("invoice_id", "22222222-2222-2222-2222-222222222222")
+	require.NoError(t, err)
+	assert.Equal(t, uuid.MustParse("22222222-2222-2222-2222-222222222222"), id)
+
+	_, err = parseID("invoice_id", "")
+	assert.EqualError(t, err, "invoice_id is required")
+
+	_, err = parseID("invoice_id", "nope")
+	assert.ErrorContains(t, err, `invalid invoice_id "nope"`)
+}
+
+func testResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		customer, err := decodeResponse[company.Customer](testResponse(http.StatusOK, `{"name":"Acme AB","type":"company"}`), nil, "get customer")
+		require.NoError(t, err)
+		assert.Equal(t, "Acme AB", customer.Name)
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		_, err := decodeResponse[company.Customer](nil, errors.New("connection refused"), "get customer")
+		assert.EqualError(t, err, "failed to get customer: connection refused")
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		_, err := decodeResponse[company.Customer](testResponse(http.StatusBadRequest,
+			`{"code":"validation-error","message":"Invalid customer","errors":[{"field":"name","message":"Name is required"}]}`), nil, "create customer")
+
+		var apiErr *bokio.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, bokio.CategoryValidation, apiErr.Category)
+		assert.Equal(t, []bokio.FieldError{{Field: "name", Message: "Name is required"}}, apiErr.Fields)
+	})
+
+	t.Run("non-json error", func(t *testing.T) {
+		_, err := decodeResponse[company.Customer](testResponse(http.StatusBadGateway, "<html>bad gateway</html>"), nil, "get customer")
+
+		var apiErr *bokio.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, bokio.CategoryServer, apiErr.Category)
+		assert.Empty(t, apiErr.Message)
+		assert.Equal(t, "API returned status 502 (server)", err.Error())
+	})
+
+	t.Run("page", func(t *testing.T) {
+		page, err := decodePage[company.Customer](testResponse(http.StatusOK, `{"currentPage":1,"totalItems":1,"totalPages":1,"items":[{"name":"Acme AB","type":"company"}]}`), nil, "list customers")
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, int32(1), page.TotalItems)
+	})
+}
+
+func TestReadDownload(t *testing.T) {
+	resp := testResponse(http.StatusOK, "%PDF-1.4")
+	resp.Header.Set("Content-Type", "application/pdf")
+	resp.Header.Set("Content-Disposition", `attachment; filename="kvitto 1.pdf"`)
+
+	download, data, err := readDownload(resp, nil, "download upload", "fallback", "bokio://companies/c/uploads/u")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("%PDF-1.4"), data)
+	assert.Equal(t, &FileDownload{
+		FileName:    "kvitto 1.pdf",
+		ContentType: "application/pdf",
+		Size:        8,
+		URI:         "bokio://companies/c/uploads/u/kvitto%201.pdf",
+	}, download)
+}
@@ -0,0 +1,230 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio"
+)
+
+const (
+	// idempotencyTTL is how long explicit idempotency keys are remembered
+	idempotencyTTL = 30 * 24 * time.Hour
+	// derivedKeyTTL is how long keys derived from the request content are
+	// remembered. It is short so identical resources can still be created
+	// deliberately on a later day.
+	derivedKeyTTL = 24 * time.Hour
+	// invoiceIdempotencyMetadata is the invoice metadata key holding the idempotency key
+	invoiceIdempotencyMetadata = "idempotencyKey"
+)
+
+// idempotencyKeyDescription documents the idempotency_key argument of create tools
+const idempotencyKeyDescription = "Key making retries safe (optional): calls with a key that already created a resource return that resource instead of creating another. " +
+	"Defaults to a hash of the request, remembered for 24 hours; pass a new key to deliberately create an identical resource."
+
+// idempotencyKeyPattern restricts keys to characters that are safe in queries and metadata
+var idempotencyKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,100}$`)
+
+// createLocks serializes idempotent creates per company and key, so
+// concurrent calls with the same key cannot both create a resource while
+// creates with other keys go ahead
+var createLocks = &keyedMutex{locks: map[string]*keyLock{}}
+
+// keyedMutex hands out a mutex per key and drops it once no call holds or
+// waits for it
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock is the mutex of a key with the number of calls using it
+type keyLock struct {
+	sync.Mutex
+	users int
+}
+
+// lock locks key and returns the function unlocking it
+func (m *keyedMutex) lock(key string) (unlock func()) {
+	m.mu.Lock()
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyLock{}
+		m.locks[key] = l
+	}
+	l.users++
+	m.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		l.users--
+		if l.users == 0 {
+			delete(m.locks, key)
+		}
+	}
+}
+
+// createFlow describes a create operation made idempotent by idempotentCreate
+type createFlow[T any] struct {
+	// Noun names the resource in scopes and messages, e.g. "invoice"
+	Noun string
+	// Key is the caller's idempotency key; when empty one is derived from Payload
+	Key string
+	// Payload is the request content the derived key is computed from
+	Payload any
+	// Validate checks the request before the key is recorded (optional)
+	Validate func(ctx context.Context) error
+	// Create creates the resource, tagging it with key where the API allows
+	Create func(ctx context.Context, key string) (*T, error)
+	// ID returns the ID of a created resource
+	ID func(*T) *uuid.UUID
+	// Fetch loads a resource created by an earlier call
+	Fetch func(ctx context.Context, id uuid.UUID) (*T, error)
+	// Find looks for a resource created by an earlier call whose outcome is
+	// unknown, for example because it timed out. It returns nil when none exists.
+	Find func(ctx context.Context, key string) (*T, error)
+}
+
+// createOutcome is the result of an idempotent create
+type createOutcome[T any] struct {
+	Resource *T
+	Key      string
+	// Replayed is set when the resource was created by an earlier call
+	Replayed bool
+}
+
+// idempotentCreate runs flow.Create at most once per idempotency key and
+// company. A key is recorded as pending before the create and with the
+// resource ID after it; a replay of a completed key returns the original
+// resource, a replay of a pending key first looks for the resource in Bokio.
+// The pending record is only kept when the outcome of the create is unknown.
+func idempotentCreate[T any](ctx context.Context, store bokio.IdempotencyStore, req *toolRequest, flow createFlow[T]) (*createOutcome[T], error) {
+	scope := fmt.Sprintf("create %s %s", flow.Noun, req.CompanyID)
+	key, ttl := flow.Key, idempotencyTTL
+	if key == "" {
+		derived, err := deriveIdempotencyKey(scope, flow.Payload)
+		if err != nil {
+			return nil, err
+		}
+		key, ttl = derived, derivedKeyTTL
+	} else if !idempotencyKeyPattern.MatchString(key) {
+		return nil, errors.New("idempotency_key must be 1-100 letters, digits or the characters _ . : -")
+	}
+
+	unlock := createLocks.lock(scope + "\n" + key)
+	defer unlock()
+
+	record, err := store.Get(scope, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read idempotency record: %w", err)
+	}
+
+	if record != nil && record.ResourceID != "" {
+		id, err := uuid.Parse(record.ResourceID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s ID %q in idempotency record: %w", flow.Noun, record.ResourceID, err)
+		}
+		resource, err := flow.Fetch(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &createOutcome[T]{Resource: resource, Key: key, Replayed: true}, nil
+	}
+
+	if record != nil {
+		resource, err := flow.Find(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look for a %s created by an earlier call with idempotency key %s: %w; "+
+				"pass a new idempotency_key if you are sure it was not created", flow.Noun, key, err)
+		}
+		if resource != nil {
+			recordCreated(store, record, flow.ID(resource))
+			return &createOutcome[T]{Resource: resource, Key: key, Replayed: true}, nil
+		}
+	}
+
+	if flow.Validate != nil {
+		if err := flow.Validate(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	record = &bokio.IdempotencyRecord{Scope: scope, Key: key, CreatedAt: now, ExpiresAt: now.Add(ttl)}
+	if err := store.Put(record); err != nil {
+		return nil, fmt.Errorf("failed to record idempotency key: %w", err)
+	}
+
+	resource, err := flow.Create(ctx, key)
+	if err != nil {
+		if !createOutcomeUnknown(err) {
+			// Nothing was created; a pending record would make a retry
+			// search for a resource that does not exist
+			if err := store.Delete(scope, key); err != nil {
+				slog.Warn("Failed to remove idempotency key", "scope", scope, "key", key, "error", err)
+			}
+		}
+		return nil, err
+	}
+	recordCreated(store, record, flow.ID(resource))
+	return &createOutcome[T]{Resource: resource, Key: key}, nil
+}
+
+// createOutcomeUnknown reports whether a failed create may still have
+// created the resource. Bokio rejecting the request with a client error
+// means it did not; timeouts, transport errors, server errors and
+// unreadable responses leave it open.
+func createOutcomeUnknown(err error) bool {
+	var apiErr *bokio.APIError
+	return !errors.As(err, &apiErr) || apiErr.StatusCode >= 500
+}
+
+// recordCreated completes a pending idempotency record with the created
+// resource. Failing to record it only loses protection against duplicates.
+func recordCreated(store bokio.IdempotencyStore, record *bokio.IdempotencyRecord, id *uuid.UUID) {
+	if id == nil {
+		return
+	}
+	completed := *record
+	completed.ResourceID = id.String()
+	if err := store.Put(&completed); err != nil {
+		slog.Warn("Failed to record idempotency key", "scope", record.Scope, "key", record.Key, "error", err)
+	}
+}
+
+// deriveIdempotencyKey hashes the operation scope and request content
+func deriveIdempotencyKey(scope string, payload any) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive idempotency key: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(scope+"\n"), data...))
+	return "auto_" + hex.EncodeToString(sum[:16]), nil
+}
+
+// idempotencySummary renders the first line of an idempotent create's summary
+func idempotencySummary[T any](noun string, outcome *createOutcome[T]) string {
+	if outcome.Replayed {
+		return fmt.Sprintf("♻️ The %s was already created with idempotency key %s, returning the original", noun, outcome.Key)
+	}
+	return fmt.Sprintf("✅ Successfully created %s (idempotency key %s)", noun, outcome.Key)
+}
+
+// equalStrings reports whether two optional strings are both absent or equal
+func equalStrings(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
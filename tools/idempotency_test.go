@@ -0,0 +1,255 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testResource struct {
+	ID   *uuid.UUID
+	Name string
+}
+
+// fakeCreates is an API holding resources created through createFlow
+type fakeCreates struct {
+	resources map[uuid.UUID]*testResource
+	keys      map[string]uuid.UUID
+	creates   int
+	// lost drops the response of the next create after creating the resource
+	lost bool
+}
+
+func newFakeCreates() *fakeCreates {
+	return &fakeCreates{resources: map[uuid.UUID]*testResource{}, keys: map[string]uuid.UUID{}}
+}
+
+func (f *fakeCreates) flow(key, name string) createFlow[testResource] {
+	return createFlow[testResource]{
+		Noun:    "thing",
+		Key:     key,
+		Payload: map[string]string{"name": name},
+		Create: func(ctx context.Context, key string) (*testResource, error) {
+			f.creates++
+			id := uuid.New()
+			f.resources[id] = &testResource{ID: &id, Name: name}
+			f.keys[key] = id
+			if f.lost {
+				f.lost = false
+				return nil, context.DeadlineExceeded
+			}
+			return f.resources[id], nil
+		},
+		ID: func(r *testResource) *uuid.UUID { return r.ID },
+		Fetch: func(ctx context.Context, id uuid.UUID) (*testResource, error) {
+			return f.resources[id], nil
+		},
+		Find: func(ctx context.Context, key string) (*testResource, error) {
+			if id, ok := f.keys[key]; ok {
+				return f.resources[id], nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestIdempotentCreate(t *testing.T) {
+	ctx := context.Background()
+	req := &toolRequest{Tool: "test_create", CompanyID: uuid.New()}
+	newStore := func(t *testing.T) bokio.IdempotencyStore {
+		return bokio.NewFileIdempotencyStore(filepath.Join(t.TempDir(), "idempotency.json"))
+	}
+
+	t.Run("replays an explicit key", func(t *testing.T) {
+		api, store := newFakeCreates(), newStore(t)
+
+		first, err := idempotentCreate(ctx, store, req, api.flow("order-1", "a"))
+		require.NoError(t, err)
+		assert.False(t, first.Replayed)
+		assert.Equal(t, "order-1", first.Key)
+
+		second, err := idempotentCreate(ctx, store, req, api.flow("order-1", "a"))
+		require.NoError(t, err)
+		assert.True(t, second.Replayed)
+		assert.Equal(t, first.Resource.ID, second.Resource.ID)
+		assert.Equal(t, 1, api.creates)
+
+		third, err := idempotentCreate(ctx, store, req, api.flow("order-2", "a"))
+		require.NoError(t, err)
+		assert.False(t, third.Replayed)
+		assert.Equal(t, 2, api.creates)
+	})
+
+	t.Run("derives a key from the payload", func(t *testing.T) {
+		api, store := newFakeCreates(), newStore(t)
+
+		first, err := idempotentCreate(ctx, store, req, api.flow("", "a"))
+		require.NoError(t, err)
+		assert.Regexp(t, `^auto_[0-9a-f]{32}$`, first.Key)
+
+		second, err := idempotentCreate(ctx, store, req, api.flow("", "a"))
+		require.NoError(t, err)
+		assert.True(t, second.Replayed)
+		assert.Equal(t, first.Key, second.Key)
+
+		other, err := idempotentCreate(ctx, store, req, api.flow("", "b"))
+		require.NoError(t, err)
+		assert.False(t, other.Replayed)
+		assert.NotEqual(t, first.Key, other.Key)
+		assert.Equal(t, 2, api.creates)
+	})
+
+	t.Run("keys are scoped by company", func(t *testing.T) {
+		api, store := newFakeCreates(), newStore(t)
+
+		_, err := idempotentCreate(ctx, store, req, api.flow("order-1", "a"))
+		require.NoError(t, err)
+		other, err := idempotentCreate(ctx, store, &toolRequest{CompanyID: uuid.New()}, api.flow("order-1", "a"))
+		require.NoError(t, err)
+		assert.False(t, other.Replayed)
+		assert.Equal(t, 2, api.creates)
+	})
+
+	t.Run("finds a resource whose create outcome was lost", func(t *testing.T) {
+		api, store := newFakeCreates(), newStore(t)
+		api.lost = true
+
+		_, err := idempotentCreate(ctx, store, req, api.flow("order-1", "a"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		retry, err := idempotentCreate(ctx, store, req, api.flow("order-1", "a"))
+		require.NoError(t, err)
+		assert.True(t, retry.Replayed)
+		assert.Equal(t, 1, api.creates)
+
+		// The found resource is recorded, so later replays fetch it directly
+		flow := api.flow("order-1", "a")
+		flow.Find = func(ctx context.Context, key string) (*testResource, error) {
+			t.Fatal("unexpected search")
+			return nil, nil
+		}
+		_, err = idempotentCreate(ctx, store, req, flow)
+		require.NoError(t, err)
+	})
+
+	t.Run("creates when a failed create left nothing behind", func(t *testing.T) {
+		api, store := newFakeCreates(), newStore(t)
+		flow := api.flow("order-1", "a")
+		create := flow.Create
+		flow.Create = func(ctx context.Context, key string) (*testResource, error) {
+			return nil, errors.New("validation failed")
+		}
+		_, err := idempotentCreate(ctx, store, req, flow)
+		require.Error(t, err)
+
+		flow.Create = create
+		result, err := idempotentCreate(ctx, store, req, flow)
+		require.NoError(t, err)
+		assert.False(t, result.Replayed)
+		assert.Equal(t, 1, api.creates)
+	})
+
+	t.Run("forgets the key when Bokio rejects the create", func(t *testing.T) {
+		api, store := newFakeCreates(), newStore(t)
+		flow := api.flow("order-1", "a")
+		flow.Create = func(ctx context.Context, key string) (*testResource, error) {
+			return nil, bokio.NewAPIError(http.StatusBadRequest, nil)
+		}
+		// A search would match an unrelated resource
+		flow.Find = func(ctx context.Context, key string) (*testResource, error) {
+			return &testResource{Name: "unrelated"}, nil
+		}
+		_, err := idempotentCreate(ctx, store, req, flow)
+		require.Error(t, err)
+
+		record, err := store.Get(fmt.Sprintf("create thing %s", req.CompanyID), "order-1")
+		require.NoError(t, err)
+		assert.Nil(t, record)
+
+		result, err := idempotentCreate(ctx, store, req, api.flow("order-1", "a"))
+		require.NoError(t, err)
+		assert.False(t, result.Replayed)
+		assert.Equal(t, "a", result.Resource.Name)
+	})
+
+	t.Run("keeps the key pending after a server error", func(t *testing.T) {
+		api, store := newFakeCreates(), newStore(t)
+		flow := api.flow("order-1", "a")
+		flow.Create = func(ctx context.Context, key string) (*testResource, error) {
+			return nil, bokio.NewAPIError(http.StatusBadGateway, nil)
+		}
+		_, err := idempotentCreate(ctx, store, req, flow)
+		require.Error(t, err)
+
+		record, err := store.Get(fmt.Sprintf("create thing %s", req.CompanyID), "order-1")
+		require.NoError(t, err)
+		require.NotNil(t, record)
+		assert.Empty(t, record.ResourceID)
+	})
+
+	t.Run("validates before recording the key", func(t *testing.T) {
+		api, store := newFakeCreates(), newStore(t)
+		flow := api.flow("order-1", "a")
+		flow.Validate = func(ctx context.Context) error { return errors.New("unbalanced") }
+		_, err := idempotentCreate(ctx, store, req, flow)
+		require.EqualError(t, err, "unbalanced")
+		assert.Zero(t, api.creates)
+
+		record, err := store.Get(fmt.Sprintf("create thing %s", req.CompanyID), "order-1")
+		require.NoError(t, err)
+		assert.Nil(t, record)
+	})
+
+	t.Run("does not create when the search fails", func(t *testing.T) {
+		api, store := newFakeCreates(), newStore(t)
+		api.lost = true
+		_, err := idempotentCreate(ctx, store, req, api.flow("order-1", "a"))
+		require.Error(t, err)
+
+		flow := api.flow("order-1", "a")
+		flow.Find = func(ctx context.Context, key string) (*testResource, error) {
+			return nil, errors.New("service unavailable")
+		}
+		_, err = idempotentCreate(ctx, store, req, flow)
+		require.ErrorContains(t, err, "pass a new idempotency_key")
+		assert.Equal(t, 1, api.creates)
+	})
+
+	t.Run("creates with other keys are not blocked", func(t *testing.T) {
+		api, store := newFakeCreates(), newStore(t)
+		started, release := make(chan struct{}), make(chan struct{})
+		slow := api.flow("order-1", "a")
+		create := slow.Create
+		slow.Create = func(ctx context.Context, key string) (*testResource, error) {
+			close(started)
+			<-release
+			return create(ctx, key)
+		}
+		done := make(chan error)
+		go func() {
+			_, err := idempotentCreate(ctx, store, req, slow)
+			done <- err
+		}()
+		<-started
+
+		_, err := idempotentCreate(ctx, store, req, api.flow("order-2", "b"))
+		require.NoError(t, err)
+		close(release)
+		require.NoError(t, <-done)
+	})
+
+	t.Run("rejects malformed keys", func(t *testing.T) {
+		api, store := newFakeCreates(), newStore(t)
+		_, err := idempotentCreate(ctx, store, req, api.flow("order 1", "a"))
+		require.ErrorContains(t, err, "idempotency_key")
+		assert.Zero(t, api.creates)
+	})
+}
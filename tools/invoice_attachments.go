@@ -0,0 +1,313 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// InvoiceAttachmentListParams defines parameters for listing invoice attachments
+type InvoiceAttachmentListParams struct {
+	CompanyID string      `json:"company_id"`
+	InvoiceID string      `json:"invoice_id"`
+	Page      *int32      `json:"page,omitempty"`
+	PageSize  *int32      `json:"page_size,omitempty"`
+	Query     *string     `json:"query,omitempty"`
+	Filter    *FilterArgs `json:"filter,omitempty"`
+}
+
+// InvoiceAttachmentAddParams defines parameters for adding an invoice attachment
+type InvoiceAttachmentAddParams struct {
+	CompanyID   string `json:"company_id"`
+	InvoiceID   string `json:"invoice_id"`
+	FileContent string `json:"file_content"` // Base64 encoded file content
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+}
+
+// InvoiceAttachmentParams defines parameters for tools acting on a single attachment
+type InvoiceAttachmentParams struct {
+	CompanyID    string `json:"company_id"`
+	InvoiceID    string `json:"invoice_id"`
+	AttachmentID string `json:"attachment_id"`
+}
+
+// InvoiceAttachmentListResult defines the result for listing invoice attachments
+type InvoiceAttachmentListResult = ToolResult[Page[company.InvoiceAttachment]]
+
+// InvoiceAttachmentResult defines the result for tools returning a single attachment
+type InvoiceAttachmentResult = ToolResult[company.InvoiceAttachment]
+
+// InvoiceAttachmentDownloadResult defines the result for downloading an attachment
+type InvoiceAttachmentDownloadResult = ToolResult[FileDownload]
+
+// InvoiceAttachmentDeleteResult defines the result for deleting an attachment
+type InvoiceAttachmentDeleteResult = ToolResult[DeleteOutput[company.InvoiceAttachment]]
+
+// newMultipartFile builds a multipart/form-data body with a single "file"
+// part. It returns the body and the Content-Type header including the boundary.
+func newMultipartFile(fileName, contentType string, data []byte) (*bytes.Buffer, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     "file",
+		"filename": fileName,
+	}))
+	header.Set("Content-Type", contentType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, "", fmt.Errorf("failed to write file data: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	return &buf, writer.FormDataContentType(), nil
+}
+
+// formatInvoiceAttachment renders an attachment as a single line
+func formatInvoiceAttachment(attachment *company.InvoiceAttachment) string {
+	var id, fileName string
+	if attachment.Id != nil {
+		id = attachment.Id.String()
+	}
+	if attachment.FileName != nil {
+		fileName = *attachment.FileName
+	}
+	return fmt.Sprintf("%s: %s", id, fileName)
+}
+
+// parseAttachmentIDs validates the invoice and attachment identifiers of
+// tools acting on a single attachment
+func parseAttachmentIDs(args InvoiceAttachmentParams) (uuid.UUID, uuid.UUID, error) {
+	invoiceID, err := parseID("invoice_id", args.InvoiceID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	attachmentID, err := parseID("attachment_id", args.AttachmentID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return invoiceID, attachmentID, nil
+}
+
+// RegisterInvoiceAttachmentTools registers invoice attachment tools using generated API clients
+func RegisterInvoiceAttachmentTools(server *mcp.Server, client *bokio.AuthClient) error {
+	attachmentInput := mcp.Input(
+		mcp.Property("company_id",
+			mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+		),
+		mcp.Property("invoice_id",
+			mcp.Description("Invoice UUID"),
+			mcp.Required(true),
+		),
+		mcp.Property("attachment_id",
+			mcp.Description("Attachment UUID"),
+			mcp.Required(true),
+		),
+	)
+
+	// Tool to list the attachments of an invoice
+	listAttachmentsTool := newTool(client, toolSpec[InvoiceAttachmentListParams, Page[company.InvoiceAttachment]]{
+		Name:        "bokio_invoice_attachments_list",
+		Description: "List the files attached to an invoice",
+		Handler: func(ctx context.Context, req *toolRequest, args InvoiceAttachmentListParams) (*mcp.CallToolResultFor[InvoiceAttachmentListResult], error) {
+			invoiceID, err := parseID("invoice_id", args.InvoiceID)
+			if err != nil {
+				return nil, err
+			}
+
+			query, err := listQuery(args.Query, args.Filter, bokio.InvoiceAttachmentFilterFields)
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := client.CompanyClient.GetInvoiceAttachments(ctx, req.CompanyID, invoiceID, &company.GetInvoiceAttachmentsParams{
+				Page:     args.Page,
+				PageSize: args.PageSize,
+				Query:    query,
+			})
+			page, err := decodePage[company.InvoiceAttachment](resp, err, "list invoice attachments")
+			if err != nil {
+				return nil, err
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s\n\nInvoice: %s\n", pageSummary("invoice attachments", page), invoiceID)
+			for i := range page.Items {
+				b.WriteString("\n" + formatInvoiceAttachment(&page.Items[i]))
+			}
+
+			return structuredResult(b.String(), page), nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("invoice_id",
+				mcp.Description("Invoice UUID"),
+				mcp.Required(true),
+			),
+			mcp.Property("page",
+				mcp.Description("Page number (optional)"),
+			),
+			mcp.Property("page_size",
+				mcp.Description("Items per page (optional)"),
+			),
+			mcp.Property("query",
+				mcp.Description("Optional query to filter on fileName (optional)"),
+			),
+			mcp.Property("filter",
+				mcp.Description(filterDescription+strings.Join(bokio.InvoiceAttachmentFilterFields.Names(), ", ")),
+			),
+		),
+	)
+
+	// Tool to attach a file to a draft invoice
+	addAttachmentTool := newTool(client, toolSpec[InvoiceAttachmentAddParams, company.InvoiceAttachment]{
+		Name:        "bokio_invoice_attachments_add",
+		Description: "Attach a file to a draft invoice. Files may be at most 4 MB and 10 MB in total per invoice.",
+		Write:       true,
+		Handler: func(ctx context.Context, req *toolRequest, args InvoiceAttachmentAddParams) (*mcp.CallToolResultFor[InvoiceAttachmentResult], error) {
+			invoiceID, err := parseID("invoice_id", args.InvoiceID)
+			if err != nil {
+				return nil, err
+			}
+			fileData, err := decodeFileContent(args.FileContent, args.FileName)
+			if err != nil {
+				return nil, err
+			}
+
+			contentType := args.ContentType
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			body, formContentType, err := newMultipartFile(args.FileName, contentType, fileData)
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := client.CompanyClient.PostInvoiceAttachmentWithBody(ctx, req.CompanyID, invoiceID,
+				&company.PostInvoiceAttachmentParams{ContentType: formContentType}, formContentType, body)
+			// The response is a oneOf wrapper around the attachment
+			attachment, err := decodeResponse[company.InvoiceAttachment](resp, err, "add invoice attachment")
+			if err != nil {
+				return nil, err
+			}
+
+			return structuredResult(fmt.Sprintf("✅ Successfully added invoice attachment\n\nInvoice: %s\nAttachment: %s\nFile Size: %d bytes", invoiceID, formatInvoiceAttachment(attachment), len(fileData)), attachment), nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("invoice_id",
+				mcp.Description("Invoice UUID (the invoice must be a draft)"),
+				mcp.Required(true),
+			),
+			mcp.Property("file_content",
+				mcp.Description("Base64 encoded file content"),
+				mcp.Required(true),
+			),
+			mcp.Property("file_name",
+				mcp.Description("Name of the file"),
+				mcp.Required(true),
+			),
+			mcp.Property("content_type",
+				mcp.Description("MIME type of the file, e.g. application/pdf (optional)"),
+			),
+		),
+	)
+
+	// Tool to get the metadata of an attachment
+	getAttachmentTool := newTool(client, toolSpec[InvoiceAttachmentParams, company.InvoiceAttachment]{
+		Name:        "bokio_invoice_attachments_get",
+		Description: "Get the metadata of an invoice attachment",
+		Handler: func(ctx context.Context, req *toolRequest, args InvoiceAttachmentParams) (*mcp.CallToolResultFor[InvoiceAttachmentResult], error) {
+			invoiceID, attachmentID, err := parseAttachmentIDs(args)
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := client.CompanyClient.GetInvoiceAttachment(ctx, req.CompanyID, invoiceID, attachmentID)
+			attachment, err := decodeResponse[company.InvoiceAttachment](resp, err, "get invoice attachment")
+			if err != nil {
+				return nil, err
+			}
+
+			return structuredResult(fmt.Sprintf("✅ Successfully retrieved invoice attachment\n\nInvoice: %s\nAttachment: %s", invoiceID, formatInvoiceAttachment(attachment)), attachment), nil
+		},
+	}, attachmentInput)
+
+	// Tool to download an attachment as a binary resource
+	downloadAttachmentTool := newTool(client, toolSpec[InvoiceAttachmentParams, FileDownload]{
+		Name:        "bokio_invoice_attachments_download",
+		Description: "Download an invoice attachment. The file is returned as an embedded binary resource.",
+		Handler: func(ctx context.Context, req *toolRequest, args InvoiceAttachmentParams) (*mcp.CallToolResultFor[InvoiceAttachmentDownloadResult], error) {
+			invoiceID, attachmentID, err := parseAttachmentIDs(args)
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := client.CompanyClient.DownloadInvoiceAttachment(ctx, req.CompanyID, invoiceID, attachmentID)
+			download, data, err := readDownload(resp, err, "download invoice attachment",
+				fmt.Sprintf("attachment_%s", attachmentID),
+				fmt.Sprintf("bokio://companies/%s/invoices/%s/attachments/%s", req.CompanyID, invoiceID, attachmentID))
+			if err != nil {
+				return nil, err
+			}
+
+			return fileResult(fmt.Sprintf("✅ Successfully downloaded invoice attachment\n\nInvoice: %s\nFile Name: %s\nContent-Type: %s\nFile Size: %d bytes",
+				invoiceID, download.FileName, download.ContentType, download.Size), download, data), nil
+		},
+	}, attachmentInput)
+
+	// Tool to remove an attachment from a draft invoice
+	deleteAttachmentTool := newTool(client, toolSpec[InvoiceAttachmentParams, DeleteOutput[company.InvoiceAttachment]]{
+		Name:        "bokio_invoice_attachments_delete",
+		Description: "Remove an attachment from a draft invoice",
+		Write:       true,
+		Handler: func(ctx context.Context, req *toolRequest, args InvoiceAttachmentParams) (*mcp.CallToolResultFor[InvoiceAttachmentDeleteResult], error) {
+			invoiceID, attachmentID, err := parseAttachmentIDs(args)
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := client.CompanyClient.DeleteInvoiceAttachment(ctx, req.CompanyID, invoiceID, attachmentID)
+			if _, err := readResponse(resp, err, "delete invoice attachment"); err != nil {
+				return nil, err
+			}
+
+			return structuredResult(
+				fmt.Sprintf("✅ Successfully deleted invoice attachment\n\nInvoice: %s\nAttachment ID: %s", invoiceID, attachmentID),
+				&DeleteOutput[company.InvoiceAttachment]{ID: attachmentID.String(), Deleted: true},
+			), nil
+		},
+	}, attachmentInput)
+
+	addTools(server, client,
+		listAttachmentsTool,
+		addAttachmentTool,
+		getAttachmentTool,
+		downloadAttachmentTool,
+		deleteAttachmentTool,
+	)
+	return nil
+}
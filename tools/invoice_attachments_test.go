@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMultipartFile(t *testing.T) {
+	body, contentType, err := newMultipartFile("kvitto.pdf", "application/pdf", []byte("%PDF-1.4"))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/form-data", mediaType)
+
+	reader := multipart.NewReader(body, params["boundary"])
+	part, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "file", part.FormName())
+	assert.Equal(t, "kvitto.pdf", part.FileName())
+	assert.Equal(t, "application/pdf", part.Header.Get("Content-Type"))
+
+	data, err := io.ReadAll(part)
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4", string(data))
+
+	_, err = reader.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
@@ -2,10 +2,10 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
-	"os"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/klowdo/bokio-mcp/bokio"
@@ -15,16 +15,20 @@ import (
 
 // InvoiceListParams defines parameters for listing invoices
 type InvoiceListParams struct {
-	CompanyID string  `json:"company_id"`
-	Page      *int32  `json:"page,omitempty"`
-	PageSize  *int32  `json:"page_size,omitempty"`
-	Query     *string `json:"query,omitempty"`
+	CompanyID string      `json:"company_id"`
+	Page      *int32      `json:"page,omitempty"`
+	PageSize  *int32      `json:"page_size,omitempty"`
+	All       bool        `json:"all,omitempty"`
+	MaxItems  *int        `json:"max_items,omitempty"`
+	Query     *string     `json:"query,omitempty"`
+	Filter    *FilterArgs `json:"filter,omitempty"`
 }
 
 // InvoiceCreateParams defines parameters for creating invoices
 type InvoiceCreateParams struct {
-	CompanyID string      `json:"company_id"`
-	Invoice   interface{} `json:"invoice"`
+	CompanyID      string      `json:"company_id"`
+	Invoice        interface{} `json:"invoice"`
+	IdempotencyKey string      `json:"idempotency_key,omitempty"`
 }
 
 // InvoiceGetParams defines parameters for getting a specific invoice
@@ -53,103 +57,81 @@ type InvoiceLineItemsCreateParams struct {
 	LineItem  interface{} `json:"line_item"`
 }
 
-// InvoiceResult defines the result structure for all invoice operations
-type InvoiceResult struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+// InvoiceListResult defines the result for listing invoices
+type InvoiceListResult = ToolResult[Page[company.Invoice]]
+
+// InvoiceResult defines the result for tools returning a single invoice
+type InvoiceResult = ToolResult[company.Invoice]
+
+// InvoiceLineItemsResult defines the result for listing invoice line items
+type InvoiceLineItemsResult = ToolResult[[]company.Invoice_LineItems_Item]
+
+// InvoiceLineItemResult defines the result for creating an invoice line item
+type InvoiceLineItemResult = ToolResult[company.Invoice_LineItems_Item]
+
+// formatInvoice renders an invoice as a single line
+func formatInvoice(invoice *company.Invoice) string {
+	number := "draft"
+	if invoice.InvoiceNumber != nil && *invoice.InvoiceNumber != "" {
+		number = "#" + *invoice.InvoiceNumber
+	}
+	var id, customer, status, currency string
+	if invoice.Id != nil {
+		id = invoice.Id.String()
+	}
+	if invoice.CustomerRef != nil && invoice.CustomerRef.Name != nil {
+		customer = *invoice.CustomerRef.Name
+	}
+	if invoice.Status != nil {
+		status = string(*invoice.Status)
+	}
+	if invoice.Currency != nil {
+		currency = *invoice.Currency
+	}
+	var total float64
+	if invoice.TotalAmount != nil {
+		total = *invoice.TotalAmount
+	}
+	return fmt.Sprintf("%s: %s %s, %s, invoice date %s, due %s, total %.2f %s",
+		id, number, customer, status, invoice.InvoiceDate.Format(bokio.DateLayout), invoice.DueDate.Format(bokio.DateLayout), total, currency)
 }
 
 // RegisterInvoiceTools registers all invoice management tools using ONLY generated API clients
 func RegisterInvoiceTools(server *mcp.Server, client *bokio.AuthClient) error {
 	// Tool to list invoices with pagination and filtering
-	listInvoicesTool := mcp.NewServerTool[InvoiceListParams, InvoiceResult](
-		"bokio_invoices_list",
-		"List invoices for a company with optional pagination and filtering",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[InvoiceListParams]) (*mcp.CallToolResultFor[InvoiceResult], error) {
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	listInvoicesTool := newTool(client, toolSpec[InvoiceListParams, Page[company.Invoice]]{
+		Name:        "bokio_invoices_list",
+		Description: "List invoices for a company with optional pagination and filtering",
+		Handler: func(ctx context.Context, req *toolRequest, args InvoiceListParams) (*mcp.CallToolResultFor[InvoiceListResult], error) {
+			query, err := listQuery(args.Query, args.Filter, bokio.InvoiceFilterFields)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Create parameters for the generated client
-			genParams := &company.GetInvoiceParams{
-				Page:     params.Arguments.Page,
-				PageSize: params.Arguments.PageSize,
-				Query:    params.Arguments.Query,
+				return nil, err
 			}
 
-			// Call the generated client method
-			resp, err := client.CompanyClient.GetInvoice(ctx, companyUUID, genParams)
+			page, err := listPages[company.Invoice](ctx, req, listOptions{Page: args.Page, PageSize: args.PageSize, All: args.All, MaxItems: args.MaxItems}, "list invoices",
+				func(ctx context.Context, page, pageSize *int32) (*http.Response, error) {
+					return client.CompanyClient.GetInvoice(ctx, req.CompanyID, &company.GetInvoiceParams{
+						Page:     page,
+						PageSize: pageSize,
+						Query:    query,
+					})
+				})
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to list invoices: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s\n\nCompany: %s\n", pageSummary("invoices", page), req.CompanyID)
+			for i := range page.Items {
+				b.WriteString("\n" + formatInvoice(&page.Items[i]))
 			}
 
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[InvoiceResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully retrieved invoices\n\nCompany: %s\nStatus: %d\nResponse: %v", companyIDStr, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(b.String(), page), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("page",
 				mcp.Description("Page number (optional)"),
@@ -157,238 +139,122 @@ func RegisterInvoiceTools(server *mcp.Server, client *bokio.AuthClient) error {
 			mcp.Property("page_size",
 				mcp.Description("Items per page (optional)"),
 			),
+			mcp.Property("all",
+				mcp.Description("Fetch all pages instead of a single page, up to 10000 items (optional)"),
+			),
+			mcp.Property("max_items",
+				mcp.Description("Fetch pages until this many items are loaded (optional)"),
+			),
 			mcp.Property("query",
 				mcp.Description("Optional query to filter the data set (optional)"),
 			),
+			mcp.Property("filter",
+				mcp.Description(filterDescription+strings.Join(bokio.InvoiceFilterFields.Names(), ", ")),
+			),
 		),
 	)
 
 	// Tool to create a new invoice
-	createInvoiceTool := mcp.NewServerTool[InvoiceCreateParams, InvoiceResult](
-		"bokio_invoices_create",
-		"Create a new invoice for a company",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[InvoiceCreateParams]) (*mcp.CallToolResultFor[InvoiceResult], error) {
-			// Check if client is in read-only mode
-			if client.GetConfig().ReadOnly {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Operation not allowed in read-only mode",
-						},
-					},
-				}, nil
-			}
-
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	createInvoiceTool := newTool(client, toolSpec[InvoiceCreateParams, company.Invoice]{
+		Name:        "bokio_invoices_create",
+		Description: "Create a new invoice for a company",
+		Write:       true,
+		Facts: func(ctx context.Context, req *toolRequest, args InvoiceCreateParams) (bokio.PolicyCall, error) {
+			invoice, err := convertArgument[company.Invoice]("invoice", args.Invoice)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
+				return bokio.PolicyCall{}, err
 			}
-
-			// Convert invoice data to proper type
-			invoiceData, err := json.Marshal(params.Arguments.Invoice)
+			return invoiceFacts(&invoice)
+		},
+		Handler: func(ctx context.Context, req *toolRequest, args InvoiceCreateParams) (*mcp.CallToolResultFor[InvoiceResult], error) {
+			body, err := convertArgument[company.PostInvoiceJSONRequestBody]("invoice", args.Invoice)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid invoice data: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			var invoiceBody company.PostInvoiceJSONRequestBody
-			if err := json.Unmarshal(invoiceData, &invoiceBody); err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to parse invoice data: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Call the generated client method
-			resp, err := client.CompanyClient.PostInvoice(ctx, companyUUID, invoiceBody)
+				return nil, err
+			}
+
+			outcome, err := idempotentCreate(ctx, client.Idempotency(), req, createFlow[company.Invoice]{
+				Noun:    "invoice",
+				Key:     args.IdempotencyKey,
+				Payload: body,
+				Create: func(ctx context.Context, key string) (*company.Invoice, error) {
+					// Tag the invoice so a retry can find it if the response is lost
+					metadata := map[string]string{}
+					if body.Metadata != nil {
+						maps.Copy(metadata, *body.Metadata)
+					}
+					metadata[invoiceIdempotencyMetadata] = key
+					body.Metadata = &metadata
+
+					resp, err := client.CompanyClient.PostInvoice(ctx, req.CompanyID, body)
+					return decodeResponse[company.Invoice](resp, err, "create invoice")
+				},
+				ID: func(invoice *company.Invoice) *uuid.UUID { return invoice.Id },
+				Fetch: func(ctx context.Context, id uuid.UUID) (*company.Invoice, error) {
+					resp, err := client.CompanyClient.GetInvoicesInvoiceId(ctx, req.CompanyID, id)
+					return decodeResponse[company.Invoice](resp, err, "get invoice")
+				},
+				Find: func(ctx context.Context, key string) (*company.Invoice, error) {
+					query, err := bokio.And(bokio.Where("metadata."+invoiceIdempotencyMetadata, bokio.OpEqual, key)).Query(bokio.InvoiceFilterFields)
+					if err != nil {
+						return nil, err
+					}
+					resp, err := client.CompanyClient.GetInvoice(ctx, req.CompanyID, &company.GetInvoiceParams{Query: &query})
+					page, err := decodePage[company.Invoice](resp, err, "search invoices")
+					if err != nil {
+						return nil, err
+					}
+					for i := range page.Items {
+						if metadata := page.Items[i].Metadata; metadata != nil && (*metadata)[invoiceIdempotencyMetadata] == key {
+							return &page.Items[i], nil
+						}
+					}
+					return nil, nil
+				},
+			})
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to create invoice: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
-			}
-
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[InvoiceResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully created invoice\n\nCompany: %s\nStatus: %d\nResponse: %v", companyIDStr, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			invoice := outcome.Resource
+			return structuredResult(fmt.Sprintf("%s\n\nCompany: %s\nInvoice: %s", idempotencySummary("invoice", outcome), req.CompanyID, formatInvoice(invoice)), invoice), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("invoice",
 				mcp.Description("Invoice data object to create"),
 				mcp.Required(true),
 			),
+			mcp.Property("idempotency_key",
+				mcp.Description(idempotencyKeyDescription),
+			),
 		),
 	)
 
 	// Tool to get a specific invoice by ID
-	getInvoiceTool := mcp.NewServerTool[InvoiceGetParams, InvoiceResult](
-		"bokio_invoices_get",
-		"Get a specific invoice by ID",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[InvoiceGetParams]) (*mcp.CallToolResultFor[InvoiceResult], error) {
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			if params.Arguments.InvoiceID == "" {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Invoice ID is required",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	getInvoiceTool := newTool(client, toolSpec[InvoiceGetParams, company.Invoice]{
+		Name:        "bokio_invoices_get",
+		Description: "Get a specific invoice by ID",
+		Handler: func(ctx context.Context, req *toolRequest, args InvoiceGetParams) (*mcp.CallToolResultFor[InvoiceResult], error) {
+			invoiceID, err := parseID("invoice_id", args.InvoiceID)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Parse invoice UUID
-			invoiceUUID, err := uuid.Parse(params.Arguments.InvoiceID)
+			resp, err := client.CompanyClient.GetInvoicesInvoiceId(ctx, req.CompanyID, invoiceID)
+			invoice, err := decodeResponse[company.Invoice](resp, err, "get invoice")
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid invoice ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Call the generated client method
-			resp, err := client.CompanyClient.GetInvoicesInvoiceId(ctx, companyUUID, invoiceUUID)
-			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to get invoice: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
-			}
-
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[InvoiceResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully retrieved invoice\n\nCompany: %s\nInvoice: %s\nStatus: %d\nResponse: %v", companyIDStr, params.Arguments.InvoiceID, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("✅ Successfully retrieved invoice\n\nCompany: %s\nInvoice: %s\nLine items: %d", req.CompanyID, formatInvoice(invoice), len(invoice.LineItems)), invoice), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("invoice_id",
 				mcp.Description("Invoice UUID to retrieve"),
@@ -398,142 +264,39 @@ func RegisterInvoiceTools(server *mcp.Server, client *bokio.AuthClient) error {
 	)
 
 	// Tool to update an invoice
-	updateInvoiceTool := mcp.NewServerTool[InvoiceUpdateParams, InvoiceResult](
-		"bokio_invoices_update",
-		"Update an existing invoice",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[InvoiceUpdateParams]) (*mcp.CallToolResultFor[InvoiceResult], error) {
-			// Check if client is in read-only mode
-			if client.GetConfig().ReadOnly {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Operation not allowed in read-only mode",
-						},
-					},
-				}, nil
-			}
-
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			if params.Arguments.InvoiceID == "" {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Invoice ID is required",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	updateInvoiceTool := newTool(client, toolSpec[InvoiceUpdateParams, company.Invoice]{
+		Name:        "bokio_invoices_update",
+		Description: "Update an existing invoice",
+		Write:       true,
+		Facts: func(ctx context.Context, req *toolRequest, args InvoiceUpdateParams) (bokio.PolicyCall, error) {
+			invoice, err := convertArgument[company.Invoice]("invoice", args.Invoice)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
+				return bokio.PolicyCall{}, err
 			}
-
-			// Parse invoice UUID
-			invoiceUUID, err := uuid.Parse(params.Arguments.InvoiceID)
+			return invoiceFacts(&invoice)
+		},
+		Handler: func(ctx context.Context, req *toolRequest, args InvoiceUpdateParams) (*mcp.CallToolResultFor[InvoiceResult], error) {
+			invoiceID, err := parseID("invoice_id", args.InvoiceID)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid invoice ID format: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
-
-			// Convert invoice data to proper type
-			invoiceData, err := json.Marshal(params.Arguments.Invoice)
+			body, err := convertArgument[company.PutInvoiceJSONRequestBody]("invoice", args.Invoice)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid invoice data: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			var invoiceBody company.PutInvoiceJSONRequestBody
-			if err := json.Unmarshal(invoiceData, &invoiceBody); err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to parse invoice data: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Call the generated client method
-			resp, err := client.CompanyClient.PutInvoice(ctx, companyUUID, invoiceUUID, invoiceBody)
+			resp, err := client.CompanyClient.PutInvoice(ctx, req.CompanyID, invoiceID, body)
+			invoice, err := decodeResponse[company.Invoice](resp, err, "update invoice")
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to update invoice: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[InvoiceResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully updated invoice\n\nCompany: %s\nInvoice: %s\nStatus: %d\nResponse: %v", companyIDStr, params.Arguments.InvoiceID, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("✅ Successfully updated invoice\n\nCompany: %s\nInvoice: %s", req.CompanyID, formatInvoice(invoice)), invoice), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("invoice_id",
 				mcp.Description("Invoice UUID to update"),
@@ -547,116 +310,38 @@ func RegisterInvoiceTools(server *mcp.Server, client *bokio.AuthClient) error {
 	)
 
 	// Tool to list invoice line items (gets invoice details including line items)
-	listLineItemsTool := mcp.NewServerTool[InvoiceLineItemsListParams, InvoiceResult](
-		"bokio_invoices_line_items_list",
-		"List line items for a specific invoice (retrieves invoice details including line items)",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[InvoiceLineItemsListParams]) (*mcp.CallToolResultFor[InvoiceResult], error) {
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			if params.Arguments.InvoiceID == "" {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Invoice ID is required",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
-			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Parse invoice UUID
-			invoiceUUID, err := uuid.Parse(params.Arguments.InvoiceID)
+	listLineItemsTool := newTool(client, toolSpec[InvoiceLineItemsListParams, []company.Invoice_LineItems_Item]{
+		Name:        "bokio_invoices_line_items_list",
+		Description: "List line items for a specific invoice (retrieves invoice details including line items)",
+		Handler: func(ctx context.Context, req *toolRequest, args InvoiceLineItemsListParams) (*mcp.CallToolResultFor[InvoiceLineItemsResult], error) {
+			invoiceID, err := parseID("invoice_id", args.InvoiceID)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid invoice ID format: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Call the generated client method to get invoice details (including line items)
-			resp, err := client.CompanyClient.GetInvoicesInvoiceId(ctx, companyUUID, invoiceUUID)
+			resp, err := client.CompanyClient.GetInvoicesInvoiceId(ctx, req.CompanyID, invoiceID)
+			invoice, err := decodeResponse[company.Invoice](resp, err, "get invoice line items")
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to get invoice line items: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+			lineItems := invoice.LineItems
+			if lineItems == nil {
+				lineItems = []company.Invoice_LineItems_Item{}
 			}
 
-			// Extract line items from the invoice response
-			var lineItems interface{}
-			if respMap, ok := responseData.(map[string]interface{}); ok {
-				if items, exists := respMap["lineItems"]; exists {
-					lineItems = items
-				}
+			var b strings.Builder
+			fmt.Fprintf(&b, "✅ Successfully retrieved %d invoice line items\n\nCompany: %s\nInvoice: %s\n", len(lineItems), req.CompanyID, invoiceID)
+			for i := range lineItems {
+				b.WriteString("\n" + formatItem(lineItems[i]))
 			}
 
-			// Return success with line items data
-			return &mcp.CallToolResultFor[InvoiceResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully retrieved invoice line items\n\nCompany: %s\nInvoice: %s\nStatus: %d\nLine Items: %v", companyIDStr, params.Arguments.InvoiceID, resp.StatusCode, lineItems),
-					},
-				},
-			}, nil
+			return structuredResult(b.String(), &lineItems), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("invoice_id",
 				mcp.Description("Invoice UUID to get line items for"),
@@ -666,142 +351,44 @@ func RegisterInvoiceTools(server *mcp.Server, client *bokio.AuthClient) error {
 	)
 
 	// Tool to create a new invoice line item
-	createLineItemTool := mcp.NewServerTool[InvoiceLineItemsCreateParams, InvoiceResult](
-		"bokio_invoices_line_items_create",
-		"Create a new line item for an invoice",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[InvoiceLineItemsCreateParams]) (*mcp.CallToolResultFor[InvoiceResult], error) {
-			// Check if client is in read-only mode
-			if client.GetConfig().ReadOnly {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Operation not allowed in read-only mode",
-						},
-					},
-				}, nil
-			}
-
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			if params.Arguments.InvoiceID == "" {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Invoice ID is required",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	createLineItemTool := newTool(client, toolSpec[InvoiceLineItemsCreateParams, company.Invoice_LineItems_Item]{
+		Name:        "bokio_invoices_line_items_create",
+		Description: "Create a new line item for an invoice",
+		Write:       true,
+		// The invoice total after adding the line item is checked
+		Facts: func(ctx context.Context, req *toolRequest, args InvoiceLineItemsCreateParams) (bokio.PolicyCall, error) {
+			lineItem, err := convertArgument[company.PostInvoiceLineItemJSONRequestBody]("line_item", args.LineItem)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
+				return bokio.PolicyCall{}, err
 			}
-
-			// Parse invoice UUID
-			invoiceUUID, err := uuid.Parse(params.Arguments.InvoiceID)
+			amount, err := lineItemAmount(lineItem)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid invoice ID format: %v", err),
-						},
-					},
-				}, nil
+				return bokio.PolicyCall{}, err
 			}
-
-			// Convert line item data to proper type
-			lineItemData, err := json.Marshal(params.Arguments.LineItem)
+			return existingInvoiceFacts(ctx, client, req, args.InvoiceID, amount)
+		},
+		Handler: func(ctx context.Context, req *toolRequest, args InvoiceLineItemsCreateParams) (*mcp.CallToolResultFor[InvoiceLineItemResult], error) {
+			invoiceID, err := parseID("invoice_id", args.InvoiceID)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid line item data: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
-
-			var lineItemBody company.PostInvoiceLineItemJSONRequestBody
-			if err := json.Unmarshal(lineItemData, &lineItemBody); err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to parse line item data: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Call the generated client method
-			resp, err := client.CompanyClient.PostInvoiceLineItem(ctx, companyUUID, invoiceUUID, lineItemBody)
+			body, err := convertArgument[company.PostInvoiceLineItemJSONRequestBody]("line_item", args.LineItem)
 			if err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to create line item: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[InvoiceResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+			resp, err := client.CompanyClient.PostInvoiceLineItem(ctx, req.CompanyID, invoiceID, body)
+			lineItem, err := decodeResponse[company.Invoice_LineItems_Item](resp, err, "create line item")
+			if err != nil {
+				return nil, err
 			}
 
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[InvoiceResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully created line item\n\nCompany: %s\nInvoice: %s\nStatus: %d\nResponse: %v", companyIDStr, params.Arguments.InvoiceID, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("✅ Successfully created line item\n\nCompany: %s\nInvoice: %s\n%s", req.CompanyID, invoiceID, formatItem(*lineItem)), lineItem), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("invoice_id",
 				mcp.Description("Invoice UUID to add line item to"),
@@ -815,13 +402,14 @@ func RegisterInvoiceTools(server *mcp.Server, client *bokio.AuthClient) error {
 	)
 
 	// Add all tools to the server
-	server.AddTools(
+	addTools(server, client,
 		listInvoicesTool,
 		createInvoiceTool,
 		getInvoiceTool,
 		updateInvoiceTool,
 		listLineItemsTool,
 		createLineItemTool,
+		receivablesAgingTool(client),
 	)
 
 	return nil
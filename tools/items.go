@@ -3,22 +3,27 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/klowdo/bokio-mcp/bokio"
 	"github.com/klowdo/bokio-mcp/bokio/generated/company"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 // ItemListParams defines parameters for listing items
 type ItemListParams struct {
-	CompanyID string  `json:"company_id"`
-	Page      *int32  `json:"page,omitempty"`
-	PageSize  *int32  `json:"page_size,omitempty"`
-	Query     *string `json:"query,omitempty"`
+	CompanyID string      `json:"company_id"`
+	Page      *int32      `json:"page,omitempty"`
+	PageSize  *int32      `json:"page_size,omitempty"`
+	All       bool        `json:"all,omitempty"`
+	MaxItems  *int        `json:"max_items,omitempty"`
+	Query     *string     `json:"query,omitempty"`
+	Filter    *FilterArgs `json:"filter,omitempty"`
 }
 
 // ItemCreateParams defines parameters for creating an item
@@ -50,103 +55,147 @@ type ItemUpdateParams struct {
 	UnitType    *string  `json:"unit_type,omitempty"`    // for salesItem
 }
 
-// ItemResult defines the result structure for item operations
-type ItemResult struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+// ItemDeleteParams defines parameters for deleting an item
+type ItemDeleteParams struct {
+	CompanyID         string `json:"company_id"`
+	ItemID            string `json:"item_id"`
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// ItemListResult defines the result for listing items
+type ItemListResult = ToolResult[Page[company.Item]]
+
+// ItemResult defines the result for tools returning a single item
+type ItemResult = ToolResult[company.Item]
+
+// ItemDeleteResult defines the result for deleting an item
+type ItemDeleteResult = ToolResult[DeleteOutput[company.Item]]
+
+// formatItem renders an item or invoice line item as a single line. Both are
+// oneOf unions of sales and description-only items, so they are decoded loosely.
+func formatItem(item json.Marshaler) string {
+	data, err := item.MarshalJSON()
+	if err != nil {
+		return fmt.Sprintf("invalid item: %v", err)
+	}
+
+	var fields struct {
+		Id          *openapi_types.UUID `json:"id"`
+		Description string              `json:"description"`
+		ItemType    string              `json:"itemType"`
+		Quantity    *float64            `json:"quantity"`
+		UnitPrice   *float64            `json:"unitPrice"`
+		UnitType    string              `json:"unitType"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return string(data)
+	}
+
+	text := fields.Description
+	if fields.Id != nil {
+		text = fmt.Sprintf("%s: %s", fields.Id, text)
+	}
+	details := []string{fields.ItemType}
+	if fields.Quantity != nil {
+		details = append(details, fmt.Sprintf("quantity %g", *fields.Quantity))
+	}
+	if fields.UnitPrice != nil {
+		details = append(details, fmt.Sprintf("%.2f per %s", *fields.UnitPrice, fields.UnitType))
+	}
+	return fmt.Sprintf("%s (%s)", text, strings.Join(details, ", "))
 }
 
 // RegisterItemTools registers item management tools using ONLY generated API clients
-func RegisterItemTools(server *mcp.Server, client *bokio.AuthClient) error {
-	// Tool to list items
-	listItemsTool := mcp.NewServerTool[ItemListParams, ItemResult](
-		"bokio_items_list",
-		"List inventory items for a company with optional pagination and filtering",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ItemListParams]) (*mcp.CallToolResultFor[ItemResult], error) {
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
 
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
+// newItemBody builds the oneOf request body of the item create and update
+// endpoints. id is nil when creating an item.
+func newItemBody[T any](args ItemCreateParams, id *uuid.UUID) (T, error) {
+	var body T
+	if args.Description == "" {
+		return body, errors.New("description is required")
+	}
+
+	var item interface{}
+	switch args.ItemType {
+	case "salesItem":
+		if args.UnitPrice == nil {
+			return body, errors.New("unit_price is required for salesItem")
+		}
+		if args.TaxRate == nil {
+			return body, errors.New("tax_rate is required for salesItem")
+		}
+
+		// Default values
+		productType := "goods"
+		if args.ProductType != nil {
+			productType = *args.ProductType
+		}
+		unitType := "piece"
+		if args.UnitType != nil {
+			unitType = *args.UnitType
+		}
+
+		item = company.SalesItem{
+			Description: args.Description,
+			Id:          id,
+			ItemType:    company.SalesItemItemTypeSalesItem,
+			ProductType: company.SalesItemProductType(productType),
+			TaxRate:     *args.TaxRate,
+			UnitPrice:   *args.UnitPrice,
+			UnitType:    company.SalesItemUnitType(unitType),
+		}
+	case "descriptionOnlyItem":
+		item = company.DescriptionOnlyItem{
+			Description: args.Description,
+			Id:          id,
+			ItemType:    company.DescriptionOnlyItemItemTypeDescriptionOnlyItem,
+		}
+	case "":
+		return body, errors.New("item_type is required (salesItem or descriptionOnlyItem)")
+	default:
+		return body, errors.New("item_type must be either 'salesItem' or 'descriptionOnlyItem'")
+	}
+
+	// Round-trip through JSON to populate the union type
+	return convertArgument[T]("item", item)
+}
 
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+// RegisterItemTools registers item management tools using ONLY generated API clients
+func RegisterItemTools(server *mcp.Server, client *bokio.AuthClient) error {
+	// Tool to list items
+	listItemsTool := newTool(client, toolSpec[ItemListParams, Page[company.Item]]{
+		Name:        "bokio_items_list",
+		Description: "List inventory items for a company with optional pagination and filtering",
+		Handler: func(ctx context.Context, req *toolRequest, args ItemListParams) (*mcp.CallToolResultFor[ItemListResult], error) {
+			query, err := listQuery(args.Query, args.Filter, bokio.ItemFilterFields)
 			if err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Create parameters for the generated client
-			genParams := &company.GetItemsParams{
-				Page:     params.Arguments.Page,
-				PageSize: params.Arguments.PageSize,
-				Query:    params.Arguments.Query,
-			}
-
-			// Call the generated client method
-			resp, err := client.CompanyClient.GetItems(ctx, companyUUID, genParams)
+			page, err := listPages[company.Item](ctx, req, listOptions{Page: args.Page, PageSize: args.PageSize, All: args.All, MaxItems: args.MaxItems}, "list items",
+				func(ctx context.Context, page, pageSize *int32) (*http.Response, error) {
+					return client.CompanyClient.GetItems(ctx, req.CompanyID, &company.GetItemsParams{
+						Page:     page,
+						PageSize: pageSize,
+						Query:    query,
+					})
+				})
 			if err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to list items: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s\n\nCompany: %s\n", pageSummary("items", page), req.CompanyID)
+			for i := range page.Items {
+				b.WriteString("\n" + formatItem(page.Items[i]))
 			}
 
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[ItemResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully retrieved items\n\nCompany: %s\nStatus: %d\nResponse: %v", companyIDStr, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(b.String(), page), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("page",
 				mcp.Description("Page number (optional)"),
@@ -154,230 +203,44 @@ func RegisterItemTools(server *mcp.Server, client *bokio.AuthClient) error {
 			mcp.Property("page_size",
 				mcp.Description("Items per page (optional)"),
 			),
+			mcp.Property("all",
+				mcp.Description("Fetch all pages instead of a single page, up to 10000 items (optional)"),
+			),
+			mcp.Property("max_items",
+				mcp.Description("Fetch pages until this many items are loaded (optional)"),
+			),
 			mcp.Property("query",
 				mcp.Description("Optional query to filter items (optional)"),
 			),
+			mcp.Property("filter",
+				mcp.Description(filterDescription+strings.Join(bokio.ItemFilterFields.Names(), ", ")),
+			),
 		),
 	)
 
 	// Tool to create a new item
-	createItemTool := mcp.NewServerTool[ItemCreateParams, ItemResult](
-		"bokio_items_create",
-		"Create a new inventory item (salesItem or descriptionOnlyItem)",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ItemCreateParams]) (*mcp.CallToolResultFor[ItemResult], error) {
-			// Check for read-only mode
-			if client.GetConfig().ReadOnly {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Create operation not allowed in read-only mode",
-						},
-					},
-				}, nil
-			}
-
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	createItemTool := newTool(client, toolSpec[ItemCreateParams, company.Item]{
+		Name:        "bokio_items_create",
+		Description: "Create a new inventory item (salesItem or descriptionOnlyItem)",
+		Write:       true,
+		Handler: func(ctx context.Context, req *toolRequest, args ItemCreateParams) (*mcp.CallToolResultFor[ItemResult], error) {
+			body, err := newItemBody[company.PostItemJSONRequestBody](args, nil)
 			if err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Validate required parameters
-			if params.Arguments.ItemType == "" {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "item_type is required (salesItem or descriptionOnlyItem)",
-						},
-					},
-				}, nil
-			}
-
-			if params.Arguments.Description == "" {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "description is required",
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Create the request body based on item type
-			var requestBody company.PostItemJSONRequestBody
-
-			if params.Arguments.ItemType == "salesItem" {
-				// Validate required fields for salesItem
-				if params.Arguments.UnitPrice == nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: "unit_price is required for salesItem",
-							},
-						},
-					}, nil
-				}
-				if params.Arguments.TaxRate == nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: "tax_rate is required for salesItem",
-							},
-						},
-					}, nil
-				}
-
-				// Default values
-				productType := "goods"
-				if params.Arguments.ProductType != nil {
-					productType = *params.Arguments.ProductType
-				}
-				unitType := "piece"
-				if params.Arguments.UnitType != nil {
-					unitType = *params.Arguments.UnitType
-				}
-
-				salesItem := company.SalesItem{
-					Description: params.Arguments.Description,
-					ItemType:    company.SalesItemItemTypeSalesItem,
-					ProductType: company.SalesItemProductType(productType),
-					TaxRate:     *params.Arguments.TaxRate,
-					UnitPrice:   *params.Arguments.UnitPrice,
-					UnitType:    company.SalesItemUnitType(unitType),
-				}
-
-				// Marshal to JSON to create the union type
-				salesItemJSON, err := json.Marshal(salesItem)
-				if err != nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: fmt.Sprintf("Failed to marshal salesItem: %v", err),
-							},
-						},
-					}, nil
-				}
-
-				// Unmarshal into the request body type to handle union type properly
-				if err := json.Unmarshal(salesItemJSON, &requestBody); err != nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: fmt.Sprintf("Failed to create request body: %v", err),
-							},
-						},
-					}, nil
-				}
-
-			} else if params.Arguments.ItemType == "descriptionOnlyItem" {
-				descItem := company.DescriptionOnlyItem{
-					Description: params.Arguments.Description,
-					ItemType:    company.DescriptionOnlyItemItemTypeDescriptionOnlyItem,
-				}
-
-				// Marshal to JSON to create the union type
-				descItemJSON, err := json.Marshal(descItem)
-				if err != nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: fmt.Sprintf("Failed to marshal descriptionOnlyItem: %v", err),
-							},
-						},
-					}, nil
-				}
-
-				// Unmarshal into the request body type to handle union type properly
-				if err := json.Unmarshal(descItemJSON, &requestBody); err != nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: fmt.Sprintf("Failed to create request body: %v", err),
-							},
-						},
-					}, nil
-				}
-
-			} else {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "item_type must be either 'salesItem' or 'descriptionOnlyItem'",
-						},
-					},
-				}, nil
-			}
-
-			// Call the generated client method
-			resp, err := client.CompanyClient.PostItem(ctx, companyUUID, requestBody)
+			resp, err := client.CompanyClient.PostItem(ctx, req.CompanyID, body)
+			item, err := decodeResponse[company.Item](resp, err, "create item")
 			if err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to create item: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
-			}
-
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[ItemResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully created item\n\nCompany: %s\nItem Type: %s\nDescription: %s\nStatus: %d\nResponse: %v", companyIDStr, params.Arguments.ItemType, params.Arguments.Description, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("✅ Successfully created item\n\nCompany: %s\nItem: %s", req.CompanyID, formatItem(*item)), item), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("item_type",
 				mcp.Description("Type of item: 'salesItem' or 'descriptionOnlyItem'"),
@@ -403,108 +266,27 @@ func RegisterItemTools(server *mcp.Server, client *bokio.AuthClient) error {
 	)
 
 	// Tool to get a specific item by ID
-	getItemTool := mcp.NewServerTool[ItemGetParams, ItemResult](
-		"bokio_items_get",
-		"Get a specific inventory item by ID",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ItemGetParams]) (*mcp.CallToolResultFor[ItemResult], error) {
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			if params.Arguments.ItemID == "" {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "item_id is required",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	getItemTool := newTool(client, toolSpec[ItemGetParams, company.Item]{
+		Name:        "bokio_items_get",
+		Description: "Get a specific inventory item by ID",
+		Handler: func(ctx context.Context, req *toolRequest, args ItemGetParams) (*mcp.CallToolResultFor[ItemResult], error) {
+			itemID, err := parseID("item_id", args.ItemID)
 			if err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Parse item UUID
-			itemUUID, err := uuid.Parse(params.Arguments.ItemID)
+			resp, err := client.CompanyClient.GetItemsItemId(ctx, req.CompanyID, itemID)
+			item, err := decodeResponse[company.Item](resp, err, "get item")
 			if err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid item ID format: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Call the generated client method
-			resp, err := client.CompanyClient.GetItemsItemId(ctx, companyUUID, itemUUID)
-			if err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to get item: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
-			}
-
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[ItemResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully retrieved item\n\nCompany: %s\nItem ID: %s\nStatus: %d\nResponse: %v", companyIDStr, params.Arguments.ItemID, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("✅ Successfully retrieved item\n\nCompany: %s\nItem: %s", req.CompanyID, formatItem(*item)), item), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("item_id",
 				mcp.Description("Item UUID"),
@@ -514,247 +296,39 @@ func RegisterItemTools(server *mcp.Server, client *bokio.AuthClient) error {
 	)
 
 	// Tool to update an item
-	updateItemTool := mcp.NewServerTool[ItemUpdateParams, ItemResult](
-		"bokio_items_update",
-		"Update an existing inventory item",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ItemUpdateParams]) (*mcp.CallToolResultFor[ItemResult], error) {
-			// Check for read-only mode
-			if client.GetConfig().ReadOnly {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Update operation not allowed in read-only mode",
-						},
-					},
-				}, nil
-			}
-
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			if params.Arguments.ItemID == "" {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "item_id is required",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	updateItemTool := newTool(client, toolSpec[ItemUpdateParams, company.Item]{
+		Name:        "bokio_items_update",
+		Description: "Update an existing inventory item",
+		Write:       true,
+		Handler: func(ctx context.Context, req *toolRequest, args ItemUpdateParams) (*mcp.CallToolResultFor[ItemResult], error) {
+			itemID, err := parseID("item_id", args.ItemID)
 			if err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Parse item UUID
-			itemUUID, err := uuid.Parse(params.Arguments.ItemID)
+				return nil, err
+			}
+			body, err := newItemBody[company.PutItemJSONRequestBody](ItemCreateParams{
+				ItemType:    args.ItemType,
+				Description: args.Description,
+				UnitPrice:   args.UnitPrice,
+				TaxRate:     args.TaxRate,
+				ProductType: args.ProductType,
+				UnitType:    args.UnitType,
+			}, &itemID)
 			if err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid item ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Validate required parameters
-			if params.Arguments.ItemType == "" {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "item_type is required (salesItem or descriptionOnlyItem)",
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			if params.Arguments.Description == "" {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "description is required",
-						},
-					},
-				}, nil
-			}
-
-			// Create the request body based on item type
-			var requestBody company.PutItemJSONRequestBody
-
-			if params.Arguments.ItemType == "salesItem" {
-				// Validate required fields for salesItem
-				if params.Arguments.UnitPrice == nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: "unit_price is required for salesItem",
-							},
-						},
-					}, nil
-				}
-				if params.Arguments.TaxRate == nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: "tax_rate is required for salesItem",
-							},
-						},
-					}, nil
-				}
-
-				// Default values
-				productType := "goods"
-				if params.Arguments.ProductType != nil {
-					productType = *params.Arguments.ProductType
-				}
-				unitType := "piece"
-				if params.Arguments.UnitType != nil {
-					unitType = *params.Arguments.UnitType
-				}
-
-				salesItem := company.SalesItem{
-					Description: params.Arguments.Description,
-					Id:          &itemUUID,
-					ItemType:    company.SalesItemItemTypeSalesItem,
-					ProductType: company.SalesItemProductType(productType),
-					TaxRate:     *params.Arguments.TaxRate,
-					UnitPrice:   *params.Arguments.UnitPrice,
-					UnitType:    company.SalesItemUnitType(unitType),
-				}
-
-				// Marshal to JSON to create the union type
-				salesItemJSON, err := json.Marshal(salesItem)
-				if err != nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: fmt.Sprintf("Failed to marshal salesItem: %v", err),
-							},
-						},
-					}, nil
-				}
-
-				// Unmarshal into the request body type to handle union type properly
-				if err := json.Unmarshal(salesItemJSON, &requestBody); err != nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: fmt.Sprintf("Failed to create request body: %v", err),
-							},
-						},
-					}, nil
-				}
-
-			} else if params.Arguments.ItemType == "descriptionOnlyItem" {
-				descItem := company.DescriptionOnlyItem{
-					Description: params.Arguments.Description,
-					Id:          &itemUUID,
-					ItemType:    company.DescriptionOnlyItemItemTypeDescriptionOnlyItem,
-				}
-
-				// Marshal to JSON to create the union type
-				descItemJSON, err := json.Marshal(descItem)
-				if err != nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: fmt.Sprintf("Failed to marshal descriptionOnlyItem: %v", err),
-							},
-						},
-					}, nil
-				}
-
-				// Unmarshal into the request body type to handle union type properly
-				if err := json.Unmarshal(descItemJSON, &requestBody); err != nil {
-					return &mcp.CallToolResultFor[ItemResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: fmt.Sprintf("Failed to create request body: %v", err),
-							},
-						},
-					}, nil
-				}
-
-			} else {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "item_type must be either 'salesItem' or 'descriptionOnlyItem'",
-						},
-					},
-				}, nil
-			}
-
-			// Call the generated client method
-			resp, err := client.CompanyClient.PutItem(ctx, companyUUID, itemUUID, requestBody)
+			resp, err := client.CompanyClient.PutItem(ctx, req.CompanyID, itemID, body)
+			item, err := decodeResponse[company.Item](resp, err, "update item")
 			if err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to update item: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[ItemResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[ItemResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully updated item\n\nCompany: %s\nItem ID: %s\nItem Type: %s\nDescription: %s\nStatus: %d\nResponse: %v", companyIDStr, params.Arguments.ItemID, params.Arguments.ItemType, params.Arguments.Description, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("✅ Successfully updated item\n\nCompany: %s\nItem: %s", req.CompanyID, formatItem(*item)), item), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("item_id",
 				mcp.Description("Item UUID"),
@@ -783,6 +357,48 @@ func RegisterItemTools(server *mcp.Server, client *bokio.AuthClient) error {
 		),
 	)
 
-	server.AddTools(listItemsTool, createItemTool, getItemTool, updateItemTool)
+	// Tool to delete an item after an explicit confirmation
+	deleteItemTool := newTool(client, toolSpec[ItemDeleteParams, DeleteOutput[company.Item]]{
+		Name:        "bokio_items_delete",
+		Description: "Delete an inventory item. The first call returns a preview and a confirmation token; call again with confirmation_token to delete.",
+		Write:       true,
+		Handler: func(ctx context.Context, req *toolRequest, args ItemDeleteParams) (*mcp.CallToolResultFor[ItemDeleteResult], error) {
+			itemID, err := parseID("item_id", args.ItemID)
+			if err != nil {
+				return nil, err
+			}
+
+			return confirmDelete(ctx, req, deleteFlow[company.Item]{
+				Noun:  "item",
+				ID:    itemID,
+				Token: args.ConfirmationToken,
+				Fetch: func(ctx context.Context) (*company.Item, error) {
+					resp, err := client.CompanyClient.GetItemsItemId(ctx, req.CompanyID, itemID)
+					return decodeResponse[company.Item](resp, err, "get item")
+				},
+				Format: func(item *company.Item) string { return formatItem(*item) },
+				Delete: func(ctx context.Context) error {
+					resp, err := client.CompanyClient.DeleteItem(ctx, req.CompanyID, itemID)
+					_, err = readResponse(resp, err, "delete item")
+					return err
+				},
+			})
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("item_id",
+				mcp.Description("Item UUID"),
+				mcp.Required(true),
+			),
+			mcp.Property("confirmation_token",
+				mcp.Description("Token returned by the first call; omit it to preview the item and get a token"),
+			),
+		),
+	)
+
+	addTools(server, client, listItemsTool, createItemTool, getItemTool, updateItemTool, deleteItemTool)
 	return nil
 }
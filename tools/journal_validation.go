@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+)
+
+// BAS account numbers are four digits in account classes 1-8
+const (
+	minBASAccount = 1000
+	maxBASAccount = 8999
+)
+
+// JournalValidationError lists every problem found in a journal entry
+type JournalValidationError struct {
+	Problems []string
+}
+
+func (e *JournalValidationError) Error() string {
+	return "journal entry is invalid:\n- " + strings.Join(e.Problems, "\n- ")
+}
+
+// toOre converts an amount in kronor to whole öre, reporting whether the
+// amount is a whole number of öre
+func toOre(amount float64) (int64, bool) {
+	ore := math.Round(amount * 100)
+	return int64(ore), math.Abs(amount*100-ore) < 1e-6
+}
+
+// validateJournalItems checks the double-entry rules that can be verified
+// without calling the API: every line books exactly one non-negative amount
+// in whole öre on a 4-digit BAS account, and debits equal credits.
+func validateJournalItems(items []company.JournalEntryItem) []string {
+	var problems []string
+	var debits, credits int64
+
+	if len(items) < 2 {
+		problems = append(problems, "a journal entry needs at least two items")
+	}
+
+	for i, item := range items {
+		line := i + 1
+
+		switch {
+		case item.Account == nil:
+			problems = append(problems, fmt.Sprintf("item %d: account is required", line))
+		case *item.Account < minBASAccount || *item.Account > maxBASAccount:
+			problems = append(problems, fmt.Sprintf("item %d: account %d is not a 4-digit BAS account (%d-%d)", line, *item.Account, minBASAccount, maxBASAccount))
+		}
+
+		var debit, credit float64
+		if item.Debit != nil {
+			debit = *item.Debit
+		}
+		if item.Credit != nil {
+			credit = *item.Credit
+		}
+
+		if debit < 0 || credit < 0 {
+			problems = append(problems, fmt.Sprintf("item %d: amounts must not be negative; book a negative debit as a credit instead", line))
+			continue
+		}
+		if (debit == 0) == (credit == 0) {
+			problems = append(problems, fmt.Sprintf("item %d: exactly one of debit or credit must be set", line))
+			continue
+		}
+
+		debitOre, debitExact := toOre(debit)
+		creditOre, creditExact := toOre(credit)
+		if !debitExact || !creditExact {
+			problems = append(problems, fmt.Sprintf("item %d: amounts must be given in whole öre (at most two decimals)", line))
+		}
+		debits += debitOre
+		credits += creditOre
+	}
+
+	if debits != credits {
+		problems = append(problems, fmt.Sprintf("debits %.2f do not equal credits %.2f (difference %.2f)",
+			float64(debits)/100, float64(credits)/100, float64(debits-credits)/100))
+	}
+
+	return problems
+}
+
+// checkFiscalYearOpen reports a problem when the fiscal year covering the
+// booking date is closed
+func checkFiscalYearOpen(year *company.FiscalYear, date time.Time) string {
+	if year.Status != nil && *year.Status != company.Open {
+		return fmt.Sprintf("date %s is in fiscal year %s – %s which is %s",
+			date.Format(bokio.DateLayout), year.StartDate.Format(bokio.DateLayout), year.EndDate.Format(bokio.DateLayout), *year.Status)
+	}
+	return ""
+}
+
+// validateJournalEntry runs all local checks on a journal entry before it is
+// posted, including looking up the fiscal year of the booking date. It
+// returns a *JournalValidationError when the entry breaks the rules.
+func validateJournalEntry(ctx context.Context, client *bokio.AuthClient, companyID uuid.UUID, date time.Time, items []company.JournalEntryItem) error {
+	problems := validateJournalItems(items)
+
+	year, err := client.FiscalYearContaining(ctx, companyID, date)
+	switch {
+	case errors.Is(err, bokio.ErrFiscalYearNotFound):
+		problems = append(problems, fmt.Sprintf("date %s is not inside any fiscal year", date.Format(bokio.DateLayout)))
+	case err != nil:
+		return fmt.Errorf("failed to look up fiscal year: %w", err)
+	default:
+		if problem := checkFiscalYearOpen(year, date); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &JournalValidationError{Problems: problems}
+	}
+	return nil
+}
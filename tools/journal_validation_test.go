@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/stretchr/testify/assert"
+)
+
+func journalItem(account int32, debit, credit float64) company.JournalEntryItem {
+	item := company.JournalEntryItem{Account: &account}
+	if debit != 0 {
+		item.Debit = &debit
+	}
+	if credit != 0 {
+		item.Credit = &credit
+	}
+	return item
+}
+
+func TestValidateJournalItems(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []company.JournalEntryItem
+		want  []string
+	}{
+		{
+			name: "balanced entry",
+			items: []company.JournalEntryItem{
+				journalItem(1930, 200, 0),
+				journalItem(3011, 0, 160),
+				journalItem(2611, 0, 40),
+			},
+		},
+		{
+			name: "balanced with floating point noise",
+			items: []company.JournalEntryItem{
+				journalItem(1930, 0.1, 0),
+				journalItem(1930, 0.2, 0),
+				journalItem(3011, 0, 0.3),
+			},
+		},
+		{
+			name: "off by one öre",
+			items: []company.JournalEntryItem{
+				journalItem(1930, 100.01, 0),
+				journalItem(3011, 0, 100),
+			},
+			want: []string{"debits 100.01 do not equal credits 100.00 (difference 0.01)"},
+		},
+		{
+			name: "both debit and credit",
+			items: []company.JournalEntryItem{
+				journalItem(1930, 100, 100),
+				journalItem(3011, 0, 0),
+			},
+			want: []string{
+				"item 1: exactly one of debit or credit must be set",
+				"item 2: exactly one of debit or credit must be set",
+			},
+		},
+		{
+			name: "negative amount",
+			items: []company.JournalEntryItem{
+				journalItem(1930, -50, 0),
+				journalItem(3011, 0, 50),
+			},
+			want: []string{
+				"item 1: amounts must not be negative; book a negative debit as a credit instead",
+				"debits 0.00 do not equal credits 50.00 (difference -50.00)",
+			},
+		},
+		{
+			name: "invalid accounts and fractional öre",
+			items: []company.JournalEntryItem{
+				journalItem(193, 10.005, 0),
+				{Credit: float64Ptr(10.005)},
+			},
+			want: []string{
+				"item 1: account 193 is not a 4-digit BAS account (1000-8999)",
+				"item 1: amounts must be given in whole öre (at most two decimals)",
+				"item 2: account is required",
+				"item 2: amounts must be given in whole öre (at most two decimals)",
+			},
+		},
+		{
+			name:  "single item",
+			items: []company.JournalEntryItem{journalItem(1930, 0, 0)},
+			want: []string{
+				"a journal entry needs at least two items",
+				"item 1: exactly one of debit or credit must be set",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, validateJournalItems(tt.items))
+		})
+	}
+}
+
+func TestCheckFiscalYearOpen(t *testing.T) {
+	closed := company.Closed
+	open := company.Open
+	date := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	year := company.FiscalYear{}
+	year.StartDate.Time = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	year.EndDate.Time = time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	year.Status = &open
+	assert.Empty(t, checkFiscalYearOpen(&year, date))
+
+	year.Status = &closed
+	assert.Equal(t, "date 2023-06-01 is in fiscal year 2023-01-01 – 2023-12-31 which is closed", checkFiscalYearOpen(&year, date))
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,245 @@
+package tools
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// ToolResult is the structured content returned by tools. Data holds the
+// typed API response on success.
+type ToolResult[T any] struct {
+	Success bool   `json:"success"`
+	Data    *T     `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// APIError details the failure when it was reported by the Bokio API
+	APIError *bokio.APIError `json:"api_error,omitempty"`
+}
+
+// Page is one page of a paginated list endpoint
+type Page[T any] struct {
+	Items       []T   `json:"items"`
+	CurrentPage int32 `json:"current_page"`
+	// LastPage is the last page included when several pages were fetched
+	LastPage   int32 `json:"last_page,omitempty"`
+	TotalItems int32 `json:"total_items"`
+	TotalPages int32 `json:"total_pages"`
+}
+
+// listResponse is the envelope of Bokio's paginated list endpoints
+type listResponse[T any] struct {
+	CurrentPage *int32 `json:"currentPage,omitempty"`
+	Items       *[]T   `json:"items,omitempty"`
+	TotalItems  *int32 `json:"totalItems,omitempty"`
+	TotalPages  *int32 `json:"totalPages,omitempty"`
+}
+
+// page converts the optional fields of a list response into a Page
+func (r *listResponse[T]) page() *Page[T] {
+	page := &Page[T]{Items: []T{}}
+	if r.Items != nil {
+		page.Items = *r.Items
+	}
+	if r.CurrentPage != nil {
+		page.CurrentPage = *r.CurrentPage
+	}
+	if r.TotalItems != nil {
+		page.TotalItems = *r.TotalItems
+	}
+	if r.TotalPages != nil {
+		page.TotalPages = *r.TotalPages
+	}
+	return page
+}
+
+// pageSummary renders the first line of a list tool's summary
+func pageSummary[T any](noun string, page *Page[T]) string {
+	if page.LastPage > page.CurrentPage {
+		return fmt.Sprintf("✅ Successfully retrieved %d of %d %s (pages %d-%d of %d)", len(page.Items), page.TotalItems, noun, page.CurrentPage, page.LastPage, page.TotalPages)
+	}
+	return fmt.Sprintf("✅ Successfully retrieved %d of %d %s (page %d of %d)", len(page.Items), page.TotalItems, noun, page.CurrentPage, page.TotalPages)
+}
+
+// FileDownload describes a file returned as an embedded resource
+type FileDownload struct {
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	URI         string `json:"uri"`
+}
+
+// downloadFileName extracts the file name from a Content-Disposition header
+func downloadFileName(header http.Header, fallback string) string {
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		return params["filename"]
+	}
+	return fallback
+}
+
+// readDownload reads a file download response. The resource URI is uriPrefix
+// followed by the escaped file name.
+func readDownload(resp *http.Response, err error, action, fallbackName, uriPrefix string) (*FileDownload, []byte, error) {
+	data, err := readResponse(resp, err, action)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	fileName := downloadFileName(resp.Header, fallbackName)
+
+	return &FileDownload{
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        len(data),
+		URI:         uriPrefix + "/" + url.PathEscape(fileName),
+	}, data, nil
+}
+
+// structuredResult returns data as structured content together with a
+// compact human-readable summary
+func structuredResult[T any](summary string, data *T) *mcp.CallToolResultFor[ToolResult[T]] {
+	return &mcp.CallToolResultFor[ToolResult[T]]{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: summary,
+			},
+		},
+		StructuredContent: ToolResult[T]{Success: true, Data: data},
+	}
+}
+
+// fileResult returns a downloaded file as an embedded binary resource, with
+// its metadata as structured content
+func fileResult(summary string, download *FileDownload, data []byte) *mcp.CallToolResultFor[ToolResult[FileDownload]] {
+	result := structuredResult(summary, download)
+	result.Content = append(result.Content, &mcp.EmbeddedResource{
+		Resource: &mcp.ResourceContents{
+			URI:      download.URI,
+			MIMEType: download.ContentType,
+			Blob:     data,
+		},
+	})
+	return result
+}
+
+// withOutputSchema sets the output schema of a tool from its result type
+func withOutputSchema[Out any](tool *mcp.ServerTool) *mcp.ServerTool {
+	tool.Tool.OutputSchema = schemaFor(reflect.TypeFor[Out](), map[reflect.Type]bool{})
+	return tool
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+	// stringTypes have custom JSON marshalers that produce strings
+	stringTypes = map[reflect.Type]bool{
+		reflect.TypeFor[openapi_types.Date](): true,
+		reflect.TypeFor[time.Time]():          true,
+	}
+)
+
+// schemaFor infers a JSON schema describing how t is marshaled by
+// encoding/json. Types with custom marshaling, such as the oneOf unions of
+// the generated client, are left unconstrained.
+func schemaFor(t reflect.Type, visiting map[reflect.Type]bool) *jsonschema.Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case stringTypes[t]:
+		return &jsonschema.Schema{Type: "string"}
+	case t.Implements(jsonMarshalerType), reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &jsonschema.Schema{}
+	case t.Implements(textMarshalerType), reflect.PointerTo(t).Implements(textMarshalerType):
+		return &jsonschema.Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonschema.Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonschema.Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonschema.Schema{Type: "number"}
+	case reflect.String:
+		return &jsonschema.Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are base64 encoded
+			return &jsonschema.Schema{Type: "string"}
+		}
+		return &jsonschema.Schema{Type: "array", Items: schemaFor(t.Elem(), visiting)}
+	case reflect.Map:
+		return &jsonschema.Schema{Type: "object"}
+	case reflect.Struct:
+		if visiting[t] {
+			return &jsonschema.Schema{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		schema := &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{}}
+		addStructFields(schema, t, visiting)
+		return schema
+	default:
+		return &jsonschema.Schema{}
+	}
+}
+
+// addStructFields adds the JSON fields of t to schema, flattening embedded structs
+func addStructFields(schema *jsonschema.Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		omitEmpty := strings.Contains(options, "omitempty")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addStructFields(schema, field.Type, visiting)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		switch {
+		case omitEmpty:
+			schema.Properties[name] = schemaFor(field.Type, visiting)
+		case field.Type.Kind() == reflect.Pointer:
+			// Nil pointers without omitempty are encoded as null
+			schema.Properties[name] = &jsonschema.Schema{}
+		default:
+			property := schemaFor(field.Type, visiting)
+			if kind := field.Type.Kind(); (kind == reflect.Slice || kind == reflect.Map) && property.Type != "" {
+				// Nil slices and maps without omitempty are encoded as null
+				property.Types = []string{property.Type, "null"}
+				property.Type = ""
+			}
+			schema.Properties[name] = property
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
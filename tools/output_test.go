@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/klowdo/bokio-mcp/bokio/sie"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFor(t *testing.T) {
+	schema := schemaFor(reflect.TypeFor[InvoiceListResult](), map[reflect.Type]bool{})
+
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"success"}, schema.Required)
+	assert.Equal(t, "boolean", schema.Properties["success"].Type)
+	assert.Equal(t, "string", schema.Properties["error"].Type)
+
+	page := schema.Properties["data"]
+	require.NotNil(t, page)
+	assert.ElementsMatch(t, []string{"items", "current_page", "total_items", "total_pages"}, page.Required)
+	assert.Equal(t, "integer", page.Properties["total_items"].Type)
+
+	items := page.Properties["items"]
+	assert.Equal(t, []string{"array", "null"}, items.Types)
+	invoice := items.Items
+	require.NotNil(t, invoice)
+	assert.Equal(t, "object", invoice.Type)
+
+	// UUIDs and dates are strings on the wire
+	assert.Equal(t, "string", invoice.Properties["id"].Type)
+	assert.Equal(t, "string", invoice.Properties["invoiceDate"].Type)
+	assert.Contains(t, invoice.Required, "invoiceDate")
+
+	// Nullable fields without omitempty accept null
+	assert.Empty(t, invoice.Properties["invoiceNumber"].Type)
+	assert.NotContains(t, invoice.Required, "invoiceNumber")
+
+	// oneOf unions are left unconstrained
+	assert.Equal(t, []string{"array", "null"}, invoice.Properties["lineItems"].Types)
+	assert.Empty(t, invoice.Properties["lineItems"].Items.Type)
+
+	assert.Equal(t, "object", invoice.Properties["metadata"].Type)
+	assert.Equal(t, "number", invoice.Properties["totalAmount"].Type)
+}
+
+func TestSchemaForNilSlices(t *testing.T) {
+	schema := schemaFor(reflect.TypeFor[sie.Summary](), map[reflect.Type]bool{})
+	resolved, err := schema.Resolve(nil)
+	require.NoError(t, err)
+
+	// A summary without non-zero balances has no accounts
+	data, err := json.Marshal(sie.Summary{})
+	require.NoError(t, err)
+	var instance map[string]any
+	require.NoError(t, json.Unmarshal(data, &instance))
+	require.Contains(t, instance, "accounts")
+	assert.Nil(t, instance["accounts"])
+	assert.NoError(t, resolved.Validate(instance))
+}
+
+func TestListResponsePage(t *testing.T) {
+	var list listResponse[company.Customer]
+	require.NoError(t, json.Unmarshal([]byte(`{"currentPage":2,"totalItems":3,"totalPages":2,"items":[{"name":"Acme AB","type":"company"}]}`), &list))
+
+	page := list.page()
+	assert.Equal(t, int32(2), page.CurrentPage)
+	assert.Equal(t, int32(3), page.TotalItems)
+	assert.Equal(t, int32(2), page.TotalPages)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "Acme AB", page.Items[0].Name)
+	assert.Equal(t, "✅ Successfully retrieved 1 of 3 customers (page 2 of 2)", pageSummary("customers", page))
+
+	// An empty response still yields an empty items array
+	empty := (&listResponse[company.Customer]{}).page()
+	data, err := json.Marshal(empty)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"items":[],"current_page":0,"total_items":0,"total_pages":0}`, string(data))
+}
+
+func TestStructuredResult(t *testing.T) {
+	customer := &company.Customer{Name: "Acme AB", Type: "company"}
+	result := structuredResult("summary", customer)
+
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "summary", result.Content[0].(*mcp.TextContent).Text)
+	assert.True(t, result.StructuredContent.Success)
+	assert.Same(t, customer, result.StructuredContent.Data)
+	assert.Empty(t, result.StructuredContent.Error)
+}
+
+func TestFileResult(t *testing.T) {
+	download := &FileDownload{FileName: "kvitto.pdf", ContentType: "application/pdf", Size: 8, URI: "bokio://companies/c/uploads/u/kvitto.pdf"}
+	result := fileResult("summary", download, []byte("%PDF-1.4"))
+
+	require.Len(t, result.Content, 2)
+	resource := result.Content[1].(*mcp.EmbeddedResource).Resource
+	assert.Equal(t, download.URI, resource.URI)
+	assert.Equal(t, "application/pdf", resource.MIMEType)
+	assert.Equal(t, []byte("%PDF-1.4"), resource.Blob)
+	assert.Same(t, download, result.StructuredContent.Data)
+}
+
+func TestDownloadFileName(t *testing.T) {
+	tests := []struct {
+		name        string
+		disposition string
+		want        string
+	}{
+		{name: "attachment", disposition: `attachment; filename="faktura underlag.pdf"`, want: "faktura underlag.pdf"},
+		{name: "missing header", disposition: "", want: "fallback"},
+		{name: "no filename", disposition: "inline", want: "fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.disposition != "" {
+				header.Set("Content-Disposition", tt.disposition)
+			}
+			assert.Equal(t, tt.want, downloadFileName(header, "fallback"))
+		})
+	}
+}
+
+func TestFormatItem(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "sales item",
+			body: `{"id":"33333333-3333-3333-3333-333333333333","description":"Consulting","itemType":"salesItem","unitPrice":950,"unitType":"hour"}`,
+			want: "33333333-3333-3333-3333-333333333333: Consulting (salesItem, 950.00 per hour)",
+		},
+		{
+			name: "invoice line item",
+			body: `{"description":"Consulting","itemType":"salesItem","quantity":2.5,"unitPrice":950,"unitType":"hour"}`,
+			want: "Consulting (salesItem, quantity 2.5, 950.00 per hour)",
+		},
+		{
+			name: "description only item",
+			body: `{"description":"Thanks for your business","itemType":"descriptionOnlyItem"}`,
+			want: "Thanks for your business (descriptionOnlyItem)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var item company.Item
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &item))
+			assert.Equal(t, tt.want, formatItem(item))
+		})
+	}
+}
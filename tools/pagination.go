@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// allPageSize is the page size used to fetch every page when the caller
+	// did not choose one
+	allPageSize int32 = 100
+	// maxConcurrentPages bounds the number of pages fetched in parallel
+	maxConcurrentPages = 4
+	// maxListItems caps the number of items collected by a single list call
+	maxListItems = 10000
+)
+
+// listOptions selects the pages a list tool returns. Without All or MaxItems
+// a single page is fetched.
+type listOptions struct {
+	Page     *int32
+	PageSize *int32
+	All      bool
+	MaxItems *int
+}
+
+// pageRequest requests one page of a list endpoint, building the endpoint's
+// Get*Params from page and pageSize
+type pageRequest func(ctx context.Context, page, pageSize *int32) (*http.Response, error)
+
+// listPages fetches the pages selected by opts. When more than one page is
+// needed the first page is fetched to learn the page count and the remaining
+// pages are fetched concurrently, reporting progress to the client.
+func listPages[T any](ctx context.Context, req *toolRequest, opts listOptions, action string, fetch pageRequest) (*Page[T], error) {
+	if !opts.All && opts.MaxItems == nil {
+		resp, err := fetch(ctx, opts.Page, opts.PageSize)
+		return decodePage[T](resp, err, action)
+	}
+
+	limit := maxListItems
+	if opts.MaxItems != nil {
+		if *opts.MaxItems < 1 {
+			return nil, errors.New("max_items must be at least 1")
+		}
+		limit = min(*opts.MaxItems, maxListItems)
+	}
+	pageSize := allPageSize
+	if opts.PageSize != nil {
+		if *opts.PageSize < 1 {
+			return nil, errors.New("page_size must be at least 1")
+		}
+		pageSize = *opts.PageSize
+	}
+	first := int32(1)
+	if opts.Page != nil {
+		if *opts.Page < 1 {
+			return nil, errors.New("page must be at least 1")
+		}
+		first = *opts.Page
+	}
+
+	fetchPage := func(ctx context.Context, number int32) (*Page[T], error) {
+		resp, err := fetch(ctx, &number, &pageSize)
+		return decodePage[T](resp, err, fmt.Sprintf("%s (page %d)", action, number))
+	}
+
+	firstPage, err := fetchPage(ctx, first)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stop at the last page or once enough pages are loaded to reach limit
+	last := min(firstPage.TotalPages, first+int32((limit-1)/int(pageSize)))
+	pages := []*Page[T]{firstPage}
+	if last > first {
+		pages = make([]*Page[T], last-first+1)
+		pages[0] = firstPage
+		req.notifyProgress(ctx, 1, len(pages), fmt.Sprintf("Loaded page 1 of %d", len(pages)))
+		if err := fetchRemainingPages(ctx, req, pages, first, fetchPage); err != nil {
+			return nil, err
+		}
+	}
+
+	combined := &Page[T]{
+		Items:       []T{},
+		CurrentPage: firstPage.CurrentPage,
+		LastPage:    first + int32(len(pages)-1),
+		TotalItems:  firstPage.TotalItems,
+		TotalPages:  firstPage.TotalPages,
+	}
+	for _, page := range pages {
+		combined.Items = append(combined.Items, page.Items...)
+	}
+	if len(combined.Items) > limit {
+		combined.Items = combined.Items[:limit]
+	}
+	return combined, nil
+}
+
+// fetchRemainingPages fills pages[1:] with the pages following first, at
+// most maxConcurrentPages at a time. The first error cancels the rest.
+func fetchRemainingPages[T any](ctx context.Context, req *toolRequest, pages []*Page[T], first int32, fetchPage func(context.Context, int32) (*Page[T], error)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type fetched struct {
+		index int
+		page  *Page[T]
+		err   error
+	}
+	results := make(chan fetched, len(pages)-1)
+	slots := make(chan struct{}, maxConcurrentPages)
+	for i := 1; i < len(pages); i++ {
+		go func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			page, err := fetchPage(ctx, first+int32(i))
+			results <- fetched{index: i, page: page, err: err}
+		}()
+	}
+
+	for loaded := 2; loaded <= len(pages); loaded++ {
+		result := <-results
+		if result.err != nil {
+			return result.err
+		}
+		pages[result.index] = result.page
+		req.notifyProgress(ctx, loaded, len(pages), fmt.Sprintf("Loaded page %d of %d", loaded, len(pages)))
+	}
+	return nil
+}
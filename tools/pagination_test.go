@@ -0,0 +1,141 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testItem struct {
+	N int `json:"n"`
+}
+
+// fakeList serves totalItems numbered items in pages and records the
+// requested pages
+type fakeList struct {
+	totalItems int
+	failPage   int32
+
+	mu        sync.Mutex
+	requested []int32
+	inFlight  atomic.Int32
+	maxFlight atomic.Int32
+}
+
+func (f *fakeList) fetch(ctx context.Context, page, pageSize *int32) (*http.Response, error) {
+	flight := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		peak := f.maxFlight.Load()
+		if flight <= peak || f.maxFlight.CompareAndSwap(peak, flight) {
+			break
+		}
+	}
+
+	number, size := int32(1), int32(25)
+	if page != nil {
+		number = *page
+	}
+	if pageSize != nil {
+		size = *pageSize
+	}
+	f.mu.Lock()
+	f.requested = append(f.requested, number)
+	f.mu.Unlock()
+
+	if number == f.failPage {
+		return nil, errors.New("connection reset")
+	}
+
+	var items []string
+	for n := int(number-1)*int(size) + 1; n <= min(int(number)*int(size), f.totalItems); n++ {
+		items = append(items, fmt.Sprintf(`{"n":%d}`, n))
+	}
+	totalPages := (f.totalItems + int(size) - 1) / int(size)
+	return testResponse(http.StatusOK, fmt.Sprintf(`{"currentPage":%d,"totalItems":%d,"totalPages":%d,"items":[%s]}`,
+		number, f.totalItems, totalPages, strings.Join(items, ","))), nil
+}
+
+func itemNumbers(page *Page[testItem]) []int {
+	numbers := make([]int, len(page.Items))
+	for i, item := range page.Items {
+		numbers[i] = item.N
+	}
+	return numbers
+}
+
+func TestListPages(t *testing.T) {
+	req := &toolRequest{Tool: "test_list"}
+
+	t.Run("single page", func(t *testing.T) {
+		list := &fakeList{totalItems: 60}
+		page, err := listPages[testItem](context.Background(), req, listOptions{Page: int32Ptr(2), PageSize: int32Ptr(25)}, "list things", list.fetch)
+		require.NoError(t, err)
+
+		assert.Equal(t, []int32{2}, list.requested)
+		assert.Len(t, page.Items, 25)
+		assert.Equal(t, 26, page.Items[0].N)
+		assert.Zero(t, page.LastPage)
+		assert.Equal(t, "✅ Successfully retrieved 25 of 60 things (page 2 of 3)", pageSummary("things", page))
+	})
+
+	t.Run("all pages", func(t *testing.T) {
+		list := &fakeList{totalItems: 950}
+		page, err := listPages[testItem](context.Background(), req, listOptions{PageSize: int32Ptr(50), All: true}, "list things", list.fetch)
+		require.NoError(t, err)
+
+		assert.Len(t, list.requested, 19)
+		assert.LessOrEqual(t, list.maxFlight.Load(), int32(maxConcurrentPages))
+		require.Len(t, page.Items, 950)
+		for i, n := range itemNumbers(page) {
+			require.Equal(t, i+1, n, "items must stay in page order")
+		}
+		assert.Equal(t, "✅ Successfully retrieved 950 of 950 things (pages 1-19 of 19)", pageSummary("things", page))
+	})
+
+	t.Run("max items", func(t *testing.T) {
+		list := &fakeList{totalItems: 950}
+		maxItems := 120
+		page, err := listPages[testItem](context.Background(), req, listOptions{MaxItems: &maxItems}, "list things", list.fetch)
+		require.NoError(t, err)
+
+		// Two pages of 100 cover 120 items, the rest is never requested
+		assert.ElementsMatch(t, []int32{1, 2}, list.requested)
+		assert.Len(t, page.Items, 120)
+		assert.Equal(t, int32(2), page.LastPage)
+	})
+
+	t.Run("fewer items than requested", func(t *testing.T) {
+		list := &fakeList{totalItems: 3}
+		page, err := listPages[testItem](context.Background(), req, listOptions{All: true}, "list things", list.fetch)
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{1, 2, 3}, itemNumbers(page))
+		assert.Equal(t, "✅ Successfully retrieved 3 of 3 things (page 1 of 1)", pageSummary("things", page))
+	})
+
+	t.Run("failed page", func(t *testing.T) {
+		list := &fakeList{totalItems: 950, failPage: 4}
+		_, err := listPages[testItem](context.Background(), req, listOptions{All: true}, "list things", list.fetch)
+		assert.EqualError(t, err, "failed to list things (page 4): connection reset")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		list := &fakeList{totalItems: 10}
+		maxItems := 0
+		_, err := listPages[testItem](context.Background(), req, listOptions{MaxItems: &maxItems}, "list things", list.fetch)
+		assert.EqualError(t, err, "max_items must be at least 1")
+
+		_, err = listPages[testItem](context.Background(), req, listOptions{All: true, PageSize: int32Ptr(0)}, "list things", list.fetch)
+		assert.EqualError(t, err, "page_size must be at least 1")
+		assert.Empty(t, list.requested)
+	})
+}
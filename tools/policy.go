@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+)
+
+// invoiceFacts reports the total including VAT and the invoice date of an
+// invoice for the policy. Totals in other currencies are reported in that
+// currency, which amount caps refuse: the currency rate is chosen by the
+// caller the caps guard against.
+func invoiceFacts(invoice *company.Invoice) (bokio.PolicyCall, error) {
+	total, err := invoiceTotal(invoice)
+	if err != nil {
+		return bokio.PolicyCall{}, err
+	}
+	return invoiceCall(invoice, total), nil
+}
+
+// invoiceTotal returns the total including VAT of an invoice in its own
+// currency
+func invoiceTotal(invoice *company.Invoice) (float64, error) {
+	var total float64
+	for i, item := range invoice.LineItems {
+		amount, err := lineItemAmount(item)
+		if err != nil {
+			return 0, fmt.Errorf("line item %d: %w", i+1, err)
+		}
+		total += amount
+	}
+	if len(invoice.LineItems) == 0 && invoice.TotalAmount != nil {
+		total = *invoice.TotalAmount
+	}
+	return total, nil
+}
+
+// invoiceCall reports total, in the invoice currency, and the invoice date
+// for the policy
+func invoiceCall(invoice *company.Invoice, total float64) bokio.PolicyCall {
+	call := bokio.PolicyCall{Amount: &total}
+	if invoice.Currency != nil {
+		if currency := strings.ToUpper(*invoice.Currency); currency != "" && currency != bokio.PolicyCurrency {
+			call.Currency = currency
+		}
+	}
+	if !invoice.InvoiceDate.Time.IsZero() {
+		call.Dates = []time.Time{invoice.InvoiceDate.Time}
+	}
+	return call
+}
+
+// lineItemAmount returns quantity × unit price plus VAT of an invoice line
+// item; description-only items count as 0. Items that cannot be priced are
+// an error, so amount caps do not pass them.
+func lineItemAmount(item json.Marshaler) (float64, error) {
+	data, err := item.MarshalJSON()
+	if err != nil {
+		return 0, fmt.Errorf("cannot price line item: %w", err)
+	}
+	var fields struct {
+		Quantity  float64 `json:"quantity"`
+		UnitPrice float64 `json:"unitPrice"`
+		TaxRate   float64 `json:"taxRate"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return 0, fmt.Errorf("cannot price line item: %w", err)
+	}
+	return fields.Quantity * fields.UnitPrice * (1 + fields.TaxRate/100), nil
+}
+
+// journalFacts reports the debit total and date of a journal entry for the
+// policy. An unparsable date is left to the tool's own validation.
+func journalFacts(date string, items []company.JournalEntryItem) bokio.PolicyCall {
+	var debit float64
+	for _, item := range items {
+		if item.Debit != nil {
+			debit += *item.Debit
+		}
+	}
+	call := bokio.PolicyCall{Amount: &debit}
+	if parsed, err := time.Parse(bokio.DateLayout, date); err == nil {
+		call.Dates = []time.Time{parsed}
+	}
+	return call
+}
+
+// existingInvoiceFacts reports the facts of an invoice stored in Bokio with
+// added, an amount in the invoice currency, counted on top of its total
+func existingInvoiceFacts(ctx context.Context, client *bokio.AuthClient, req *toolRequest, invoiceRef string, added float64) (bokio.PolicyCall, error) {
+	invoiceID, err := parseID("invoice_id", invoiceRef)
+	if err != nil {
+		return bokio.PolicyCall{}, err
+	}
+	resp, err := client.CompanyClient.GetInvoicesInvoiceId(ctx, req.CompanyID, invoiceID)
+	invoice, err := decodeResponse[company.Invoice](resp, err, "get invoice")
+	if err != nil {
+		return bokio.PolicyCall{}, err
+	}
+	total, err := invoiceTotal(invoice)
+	if err != nil {
+		return bokio.PolicyCall{}, err
+	}
+	return invoiceCall(invoice, total+added), nil
+}
+
+// existingJournalFacts reports the date of a journal entry stored in Bokio,
+// which a reversal books on
+func existingJournalFacts(ctx context.Context, client *bokio.AuthClient, req *toolRequest, journalRef string) (bokio.PolicyCall, error) {
+	journalID, err := parseID("journal_entry_id", journalRef)
+	if err != nil {
+		return bokio.PolicyCall{}, err
+	}
+	resp, err := client.CompanyClient.GetJournalentriesJournalId(ctx, req.CompanyID, journalID)
+	entry, err := decodeResponse[company.JournalEntry](resp, err, "get journal entry")
+	if err != nil {
+		return bokio.PolicyCall{}, err
+	}
+	var call bokio.PolicyCall
+	if entry.Date != nil {
+		call.Dates = []time.Time{entry.Date.Time}
+	}
+	return call, nil
+}
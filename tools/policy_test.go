@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceFacts(t *testing.T) {
+	invoice, err := convertArgument[company.Invoice]("invoice", map[string]any{
+		"invoiceDate": "2024-03-01",
+		"dueDate":     "2024-03-31",
+		"lineItems": []any{
+			map[string]any{"description": "Consulting", "itemType": "salesItem", "quantity": 10, "unitPrice": 1000, "taxRate": 25},
+			map[string]any{"description": "Thanks for your business", "itemType": "descriptionOnlyItem"},
+		},
+	})
+	require.NoError(t, err)
+
+	call, err := invoiceFacts(&invoice)
+	require.NoError(t, err)
+	require.NotNil(t, call.Amount)
+	assert.InDelta(t, 12500, *call.Amount, 0.001)
+	assert.Empty(t, call.Currency)
+	assert.Equal(t, []time.Time{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}, call.Dates)
+
+	// Foreign currencies are never converted with the caller's rate, so a
+	// tiny rate cannot slip an invoice under a cap
+	currency, rate := "usd", 0.0001
+	invoice.Currency, invoice.CurrencyRate = &currency, &rate
+	call, err = invoiceFacts(&invoice)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", call.Currency)
+	assert.InDelta(t, 12500, *call.Amount, 0.001)
+
+	policy, err := bokio.NewPolicy(bokio.PolicyRule{AmountCaps: map[string]float64{"bokio_invoices_*": 100}}, nil, nil)
+	require.NoError(t, err)
+	call.Tool = "bokio_invoices_create"
+	assert.ErrorIs(t, policy.Check(call), bokio.ErrPolicyDenied)
+
+	// Amounts added to the total are in the invoice currency as well
+	call = invoiceCall(&invoice, 12600)
+	assert.Equal(t, "USD", call.Currency)
+	assert.InDelta(t, 12600, *call.Amount, 0.001)
+}
+
+// failingMarshaler is a line item that cannot be encoded
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) { return nil, assert.AnError }
+
+func TestLineItemAmount(t *testing.T) {
+	amount, err := lineItemAmount(json.RawMessage(`{"itemType":"salesItem","quantity":2,"unitPrice":100,"taxRate":12}`))
+	require.NoError(t, err)
+	assert.InDelta(t, 224, amount, 0.001)
+	amount, err = lineItemAmount(json.RawMessage(`{"itemType":"descriptionOnlyItem","description":"Thanks"}`))
+	require.NoError(t, err)
+	assert.Zero(t, amount)
+
+	// Items that cannot be priced deny the call instead of counting as 0
+	_, err = lineItemAmount(json.RawMessage(`{"quantity":"ten","unitPrice":100}`))
+	assert.ErrorContains(t, err, "cannot price line item")
+	_, err = lineItemAmount(failingMarshaler{})
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestJournalFacts(t *testing.T) {
+	debit, credit := 1500.0, 1500.0
+	call := journalFacts("2024-02-29", []company.JournalEntryItem{{Debit: &debit}, {Credit: &credit}})
+	require.NotNil(t, call.Amount)
+	assert.Equal(t, 1500.0, *call.Amount)
+	assert.Equal(t, []time.Time{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)}, call.Dates)
+
+	// Invalid dates are reported by the tool itself
+	assert.Empty(t, journalFacts("29/02/2024", nil).Dates)
+}
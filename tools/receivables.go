@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/reports"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ReceivablesAgingParams defines parameters for the receivables aging tool
+type ReceivablesAgingParams struct {
+	CompanyID string `json:"company_id"`
+	// Date is the day days past due are counted to; defaults to today
+	Date string `json:"date,omitempty"`
+}
+
+// ReceivablesAgingResult defines the result for the receivables aging tool
+type ReceivablesAgingResult = ToolResult[reports.ReceivablesAging]
+
+// outstandingInvoices selects the invoices with a balance left to collect
+func outstandingInvoices() bokio.Filter {
+	var filter bokio.Filter
+	for _, status := range reports.OutstandingStatuses {
+		filter = bokio.Or(filter, bokio.And(bokio.Where("status", bokio.OpEqual, string(status))))
+	}
+	return filter
+}
+
+// formatAging renders the totals per currency and the chase digest of a
+// receivables aging as text; the customers are in the structured output
+func formatAging(report *reports.ReceivablesAging) string {
+	var b strings.Builder
+	b.WriteString("Currency | Not due | 0–30 | 31–60 | 61–90 | 90+ | Total")
+	for _, total := range report.Totals {
+		t := total.Buckets
+		fmt.Fprintf(&b, "\n%s | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f", total.Currency, t.NotDue, t.Days0To30, t.Days31To60, t.Days61To90, t.Over90, t.Total)
+	}
+
+	if len(report.Digest) == 0 {
+		b.WriteString("\n\nNo overdue invoices to chase.")
+		return b.String()
+	}
+	b.WriteString("\n\nChase first:")
+	for _, chase := range report.Digest {
+		fmt.Fprintf(&b, "\n%d. %s: %.2f %s overdue, oldest %d days (invoices %s)",
+			chase.Priority, chase.Customer, chase.Overdue, chase.Currency, chase.DaysPastDue, strings.Join(chase.Invoices, ", "))
+		if chase.PartlyPaid {
+			b.WriteString(", partly paid")
+		}
+	}
+	return b.String()
+}
+
+// receivablesAgingTool builds the receivables aging tool, which
+// RegisterInvoiceTools registers with the other invoice tools
+func receivablesAgingTool(client *bokio.AuthClient) *mcp.ServerTool {
+	// Tool to age the outstanding invoices and rank whom to chase
+	return newTool(client, toolSpec[ReceivablesAgingParams, reports.ReceivablesAging]{
+		Name:        "bokio_receivables_aging",
+		Description: "Accounts receivable aging: the outstanding balance of every published, overdue or underpaid invoice per customer, bucketed by days past due (not due, 0–30, 31–60, 61–90, 90+) with totals in each currency. Also returns a digest of customers to chase, oldest debt first and then largest balance in SEK.",
+		Handler: func(ctx context.Context, req *toolRequest, args ReceivablesAgingParams) (*mcp.CallToolResultFor[ReceivablesAgingResult], error) {
+			if err := validateDateParam("date", args.Date); err != nil {
+				return nil, err
+			}
+			date := time.Now()
+			if args.Date != "" {
+				date, _ = time.Parse(bokio.DateLayout, args.Date)
+			}
+
+			invoices, err := client.Invoices(ctx, req.CompanyID, outstandingInvoices())
+			if err != nil {
+				return nil, err
+			}
+			report := reports.Aging(invoices, date)
+
+			summary := fmt.Sprintf("✅ Receivables aging at %s\n\nCompany: %s\nCustomers with outstanding invoices: %d\n\n%s",
+				report.Date, req.CompanyID, len(report.Customers), formatAging(report))
+			return structuredResult(summary, report), nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("date",
+				mcp.Description("Date to count days past due to in YYYY-MM-DD format (defaults to today)"),
+			),
+		),
+	)
+}
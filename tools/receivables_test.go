@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/reports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutstandingInvoices(t *testing.T) {
+	query, err := outstandingInvoices().Query(bokio.InvoiceFilterFields)
+	require.NoError(t, err)
+	assert.Equal(t, "status==published||status==overdue||status==underpaid", query)
+}
+
+func TestFormatAging(t *testing.T) {
+	report := &reports.ReceivablesAging{
+		Totals: []reports.AgingTotal{
+			{Currency: "SEK", Buckets: reports.AgingBuckets{NotDue: 800, Days0To30: 3000, Over90: 12500, Total: 16300}},
+		},
+		Digest: []reports.ChaseSuggestion{
+			{Priority: 1, Customer: "Acme AB", Currency: "SEK", Overdue: 15500, DaysPastDue: 120, Invoices: []string{"1001", "1002"}, PartlyPaid: true},
+		},
+	}
+	assert.Equal(t, "Currency | Not due | 0–30 | 31–60 | 61–90 | 90+ | Total\n"+
+		"SEK | 800.00 | 3000.00 | 0.00 | 0.00 | 12500.00 | 16300.00\n\n"+
+		"Chase first:\n"+
+		"1. Acme AB: 15500.00 SEK overdue, oldest 120 days (invoices 1001, 1002), partly paid", formatAging(report))
+
+	report.Digest = nil
+	assert.Contains(t, formatAging(report), "No overdue invoices to chase.")
+}
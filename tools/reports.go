@@ -0,0 +1,280 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/klowdo/bokio-mcp/bokio/reports"
+	"github.com/klowdo/bokio-mcp/bokio/sie"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// csvMIMEType is the media type of CSV report exports
+const csvMIMEType = "text/csv; charset=utf-8"
+
+// LedgerReportParams defines parameters for the trial balance and general
+// ledger tools. The period defaults to the fiscal year.
+type LedgerReportParams struct {
+	CompanyID    string `json:"company_id"`
+	FiscalYearID string `json:"fiscal_year_id,omitempty"`
+	From         string `json:"from,omitempty"`
+	To           string `json:"to,omitempty"`
+	FromAccount  int32  `json:"from_account,omitempty"`
+	ToAccount    int32  `json:"to_account,omitempty"`
+	// Format is "json" (default) or "csv"
+	Format string `json:"format,omitempty"`
+}
+
+// Report is the structured output of the report tools. File
+// describes the CSV export when one was requested.
+type Report[T any] struct {
+	FiscalYearID string        `json:"fiscal_year_id"`
+	Report       *T            `json:"report"`
+	File         *FileDownload `json:"file,omitempty"`
+}
+
+// TrialBalanceResult defines the result for the trial balance tool
+type TrialBalanceResult = ToolResult[Report[reports.TrialBalance]]
+
+// GeneralLedgerResult defines the result for the general ledger tool
+type GeneralLedgerResult = ToolResult[Report[reports.GeneralLedger]]
+
+// reportPeriod resolves the fiscal year and period of a report. Without a
+// fiscal year ID the year containing from, or the current year, is used.
+func reportPeriod(ctx context.Context, client *bokio.AuthClient, companyID uuid.UUID, fiscalYearRef, fromRef, toRef string) (*company.FiscalYear, reports.Period, error) {
+	var period reports.Period
+	if err := validateDateParam("from", fromRef); err != nil {
+		return nil, period, err
+	}
+	if err := validateDateParam("to", toRef); err != nil {
+		return nil, period, err
+	}
+	from, _ := time.Parse(bokio.DateLayout, fromRef)
+	to, _ := time.Parse(bokio.DateLayout, toRef)
+
+	var year *company.FiscalYear
+	var err error
+	switch {
+	case fiscalYearRef != "":
+		var id uuid.UUID
+		if id, err = parseID("fiscal_year_id", fiscalYearRef); err != nil {
+			return nil, period, err
+		}
+		year, err = client.FiscalYear(ctx, companyID, id)
+	case fromRef != "":
+		year, err = client.FiscalYearContaining(ctx, companyID, from)
+	case toRef != "":
+		year, err = client.FiscalYearContaining(ctx, companyID, to)
+	default:
+		year, err = client.CurrentFiscalYear(ctx, companyID, time.Now())
+	}
+	if err != nil {
+		return nil, period, fmt.Errorf("failed to find the fiscal year: %w", err)
+	}
+
+	period = reports.Period{From: year.StartDate.Time, To: year.EndDate.Time}
+	if fromRef != "" {
+		period.From = from
+	}
+	if toRef != "" {
+		period.To = to
+	}
+	yearPeriod := reports.Period{From: year.StartDate.Time, To: year.EndDate.Time}
+	if !yearPeriod.Contains(period.From) || !yearPeriod.Contains(period.To) {
+		return nil, period, fmt.Errorf("the period %s must lie within the fiscal year %s", period, yearPeriod)
+	}
+	if period.To.Before(period.From) {
+		return nil, period, errors.New("to must not be before from")
+	}
+	return year, period, nil
+}
+
+// loadLedger fetches the opening balances and account names of the fiscal
+// year from its SIE export and its journal entries up to the end of period.
+// It reports its two steps as progress step and step+1 of steps.
+func loadLedger(ctx context.Context, client *bokio.AuthClient, req *toolRequest, year *company.FiscalYear, period reports.Period, step, steps int) (*reports.Ledger, error) {
+	req.notifyProgress(ctx, step, steps, "Loading opening balances")
+	data, err := client.DownloadSIE(ctx, req.CompanyID, year.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load opening balances: %w", err)
+	}
+	file, err := sie.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SIE file: %w", err)
+	}
+
+	ledger := &reports.Ledger{
+		Opening: make(map[int32]float64, len(file.OpeningBalances[0])),
+		Names:   make(map[int32]string, len(file.Accounts)),
+	}
+	for account, balance := range file.OpeningBalances[0] {
+		if number, err := strconv.ParseInt(account, 10, 32); err == nil {
+			ledger.Opening[int32(number)] = balance
+		}
+	}
+	for account, name := range file.Accounts {
+		if number, err := strconv.ParseInt(account, 10, 32); err == nil {
+			ledger.Names[int32(number)] = name
+		}
+	}
+
+	req.notifyProgress(ctx, step+1, steps, "Loading journal entries")
+	ledger.Entries, err = client.JournalEntries(ctx, req.CompanyID, year.StartDate.Time, period.To)
+	if err != nil {
+		return nil, err
+	}
+	return ledger, nil
+}
+
+// reportResult returns a report as JSON, or as an embedded CSV file
+// when format is "csv"
+func reportResult[T any](req *toolRequest, summary, name string, year *company.FiscalYear, report *T, writeCSV func(*bytes.Buffer) error, format string) (*mcp.CallToolResultFor[ToolResult[Report[T]]], error) {
+	output := &Report[T]{FiscalYearID: year.Id.String(), Report: report}
+	if format != "csv" {
+		return structuredResult(summary, output), nil
+	}
+
+	var buf bytes.Buffer
+	if err := writeCSV(&buf); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	output.File = &FileDownload{
+		FileName:    name + ".csv",
+		ContentType: csvMIMEType,
+		Size:        buf.Len(),
+		URI:         fmt.Sprintf("bokio://companies/%s/reports/%s.csv", req.CompanyID, name),
+	}
+	result := structuredResult(summary, output)
+	result.Content = append(result.Content, &mcp.EmbeddedResource{
+		Resource: &mcp.ResourceContents{
+			URI:      output.File.URI,
+			MIMEType: csvMIMEType,
+			Blob:     buf.Bytes(),
+		},
+	})
+	return result, nil
+}
+
+// validateReportFormat checks the format argument of the report tools
+func validateReportFormat(format string) error {
+	switch format {
+	case "", "json", "csv":
+		return nil
+	}
+	return fmt.Errorf("format must be json or csv, not %q", format)
+}
+
+// formatTrialBalance renders a trial balance as text
+func formatTrialBalance(report *reports.TrialBalance) string {
+	var b strings.Builder
+	b.WriteString("Account | Name | Opening | Debit | Credit | Closing")
+	for _, a := range report.Accounts {
+		fmt.Fprintf(&b, "\n%d | %s | %.2f | %.2f | %.2f | %.2f", a.Account, a.Name, a.Opening, a.Debit, a.Credit, a.Closing)
+	}
+	t := report.Totals
+	fmt.Fprintf(&b, "\nTotal | | %.2f | %.2f | %.2f | %.2f", t.Opening, t.Debit, t.Credit, t.Closing)
+	return b.String()
+}
+
+// formatGeneralLedger renders the account totals of a general ledger as text;
+// the transactions are in the structured output
+func formatGeneralLedger(report *reports.GeneralLedger) string {
+	var b strings.Builder
+	b.WriteString("Account | Name | Opening | Transactions | Debit | Credit | Closing")
+	for _, a := range report.Accounts {
+		fmt.Fprintf(&b, "\n%d | %s | %.2f | %d | %.2f | %.2f | %.2f", a.Account, a.Name, a.Opening, len(a.Lines), a.Debit, a.Credit, a.Closing)
+	}
+	return b.String()
+}
+
+// ledgerReportInput lists the arguments shared by the ledger report tools
+func ledgerReportInput() mcp.ToolOption {
+	return mcp.Input(
+		mcp.Property("company_id",
+			mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+		),
+		mcp.Property("fiscal_year_id",
+			mcp.Description("Fiscal year UUID (defaults to the year containing from, or the current fiscal year)"),
+		),
+		mcp.Property("from",
+			mcp.Description("First day of the period in YYYY-MM-DD format (defaults to the start of the fiscal year)"),
+		),
+		mcp.Property("to",
+			mcp.Description("Last day of the period in YYYY-MM-DD format (defaults to the end of the fiscal year)"),
+		),
+		mcp.Property("from_account",
+			mcp.Description("Lowest BAS account to include, e.g. 1000 (optional)"),
+		),
+		mcp.Property("to_account",
+			mcp.Description("Highest BAS account to include, e.g. 1999 (optional)"),
+		),
+		mcp.Property("format",
+			mcp.Description("Output format: json (default) or csv, which adds the report as an embedded CSV file"),
+			mcp.Enum("json", "csv"),
+		),
+	)
+}
+
+// RegisterReportTools registers accounting report tools computed from
+// journal entries
+func RegisterReportTools(server *mcp.Server, client *bokio.AuthClient) error {
+	// Tool to compute a trial balance
+	trialBalanceTool := newTool(client, toolSpec[LedgerReportParams, Report[reports.TrialBalance]]{
+		Name:        "bokio_trial_balance",
+		Description: "Trial balance (råbalans) computed from journal entries: opening balance, debits, credits and closing balance per BAS account for a period within one fiscal year.",
+		Handler: func(ctx context.Context, req *toolRequest, args LedgerReportParams) (*mcp.CallToolResultFor[TrialBalanceResult], error) {
+			if err := validateReportFormat(args.Format); err != nil {
+				return nil, err
+			}
+			year, period, err := reportPeriod(ctx, client, req.CompanyID, args.FiscalYearID, args.From, args.To)
+			if err != nil {
+				return nil, err
+			}
+			ledger, err := loadLedger(ctx, client, req, year, period, 0, 2)
+			if err != nil {
+				return nil, err
+			}
+
+			report := ledger.TrialBalance(period, reports.AccountFilter{From: args.FromAccount, To: args.ToAccount})
+			summary := fmt.Sprintf("✅ Trial balance %s\n\nCompany: %s\nAccounts: %d\n\n%s", period, req.CompanyID, len(report.Accounts), formatTrialBalance(report))
+			return reportResult(req, summary, fmt.Sprintf("trial-balance-%s-%s", report.From, report.To), year, report,
+				func(buf *bytes.Buffer) error { return report.WriteCSV(buf) }, args.Format)
+		},
+	}, ledgerReportInput())
+
+	// Tool to compute a general ledger
+	generalLedgerTool := newTool(client, toolSpec[LedgerReportParams, Report[reports.GeneralLedger]]{
+		Name:        "bokio_general_ledger",
+		Description: "General ledger (huvudbok) computed from journal entries: every transaction of the period per BAS account with a running balance, for a period within one fiscal year.",
+		Handler: func(ctx context.Context, req *toolRequest, args LedgerReportParams) (*mcp.CallToolResultFor[GeneralLedgerResult], error) {
+			if err := validateReportFormat(args.Format); err != nil {
+				return nil, err
+			}
+			year, period, err := reportPeriod(ctx, client, req.CompanyID, args.FiscalYearID, args.From, args.To)
+			if err != nil {
+				return nil, err
+			}
+			ledger, err := loadLedger(ctx, client, req, year, period, 0, 2)
+			if err != nil {
+				return nil, err
+			}
+
+			report := ledger.GeneralLedger(period, reports.AccountFilter{From: args.FromAccount, To: args.ToAccount})
+			summary := fmt.Sprintf("✅ General ledger %s\n\nCompany: %s\nAccounts: %d\n\n%s", period, req.CompanyID, len(report.Accounts), formatGeneralLedger(report))
+			return reportResult(req, summary, fmt.Sprintf("general-ledger-%s-%s", report.From, report.To), year, report,
+				func(buf *bytes.Buffer) error { return report.WriteCSV(buf) }, args.Format)
+		},
+	}, ledgerReportInput())
+
+	tools := append([]*mcp.ServerTool{trialBalanceTool, generalLedgerTool}, statementTools(client)...)
+	addTools(server, client, append(tools, vatReturnTool(client))...)
+	return nil
+}
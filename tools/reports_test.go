@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio/reports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateReportFormat(t *testing.T) {
+	assert.NoError(t, validateReportFormat(""))
+	assert.NoError(t, validateReportFormat("csv"))
+	assert.EqualError(t, validateReportFormat("xlsx"), `format must be json or csv, not "xlsx"`)
+}
+
+func TestReportPeriodValidation(t *testing.T) {
+	client := newTestClient(t, false)
+	companyID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	_, _, err := reportPeriod(context.Background(), client, companyID, "", "2024-13-01", "")
+	assert.EqualError(t, err, "from must be a date in YYYY-MM-DD format")
+	_, _, err = reportPeriod(context.Background(), client, companyID, "", "", "31/12/2024")
+	assert.EqualError(t, err, "to must be a date in YYYY-MM-DD format")
+	_, _, err = reportPeriod(context.Background(), client, companyID, "not-a-uuid", "", "")
+	assert.ErrorContains(t, err, `invalid fiscal_year_id "not-a-uuid"`)
+}
+
+func TestStatementNames(t *testing.T) {
+	ledger := &reports.Ledger{Names: map[int32]string{1930: "Företagskonto", 3001: "Försäljning"}}
+	previous := &reports.Ledger{Names: map[int32]string{1930: "Bankkonto", 1220: "Inventarier"}}
+
+	// Accounts only used in the previous year keep their names
+	assert.Equal(t, map[int32]string{1930: "Företagskonto", 3001: "Försäljning", 1220: "Inventarier"}, statementNames(ledger, previous))
+	assert.Equal(t, ledger.Names, statementNames(ledger, nil))
+	assert.Len(t, ledger.Names, 2)
+}
@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/sie"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SIEDownloadParams defines parameters for downloading a SIE file
+type SIEDownloadParams struct {
+	CompanyID    string `json:"company_id"`
+	FiscalYearID string `json:"fiscal_year_id,omitempty"`
+	SummaryOnly  bool   `json:"summary_only,omitempty"`
+}
+
+// SIEDownload is the structured output of the SIE download tool. File is
+// omitted when only the summary was requested.
+type SIEDownload struct {
+	FiscalYearID string        `json:"fiscal_year_id"`
+	Summary      *sie.Summary  `json:"summary"`
+	File         *FileDownload `json:"file,omitempty"`
+}
+
+// SIEDownloadResult defines the result for downloading a SIE file
+type SIEDownloadResult = ToolResult[SIEDownload]
+
+// sieMIMEType is the media type of SIE files, which are CP437 encoded text
+const sieMIMEType = "text/plain; charset=IBM437"
+
+// formatSIESummary renders a SIE summary as text
+func formatSIESummary(summary *sie.Summary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Company: %s", summary.CompanyName)
+	if summary.OrgNumber != "" {
+		fmt.Fprintf(&b, " (%s)", summary.OrgNumber)
+	}
+	fmt.Fprintf(&b, "\nFiscal year: %s – %s", summary.FiscalYear.Start, summary.FiscalYear.End)
+	fmt.Fprintf(&b, "\nAccounts: %d\nVouchers: %d\nTransactions: %d\n", summary.AccountCount, summary.VoucherCount, summary.TransactionCount)
+
+	if len(summary.Accounts) > 0 {
+		b.WriteString("\nAccount | Name | Opening | Closing | Result")
+		for _, account := range summary.Accounts {
+			fmt.Fprintf(&b, "\n%s | %s | %.2f | %.2f | %.2f", account.Number, account.Name, account.Opening, account.Closing, account.Result)
+		}
+	}
+	return b.String()
+}
+
+// RegisterSIETools registers SIE export tools using generated API clients
+func RegisterSIETools(server *mcp.Server, client *bokio.AuthClient) error {
+	// Tool to download and summarize the SIE file for a fiscal year
+	downloadSIETool := newTool(client, toolSpec[SIEDownloadParams, SIEDownload]{
+		Name:        "bokio_sie_download",
+		Description: "Download the SIE 4 file for a fiscal year. Returns a summary with account balances and voucher counts, plus the raw file as an embedded resource.",
+		Handler: func(ctx context.Context, req *toolRequest, args SIEDownloadParams) (*mcp.CallToolResultFor[SIEDownloadResult], error) {
+			// Default to the current open fiscal year
+			var fiscalYearID uuid.UUID
+			if args.FiscalYearID != "" {
+				id, err := parseID("fiscal_year_id", args.FiscalYearID)
+				if err != nil {
+					return nil, err
+				}
+				fiscalYearID = id
+			} else {
+				year, err := client.CurrentFiscalYear(ctx, req.CompanyID, time.Now())
+				if err != nil {
+					return nil, fmt.Errorf("failed to find the current fiscal year: %w", err)
+				}
+				fiscalYearID = year.Id
+			}
+
+			data, err := client.DownloadSIE(ctx, req.CompanyID, fiscalYearID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to download SIE file: %w", err)
+			}
+			file, err := sie.Parse(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse SIE file: %w", err)
+			}
+
+			output := &SIEDownload{FiscalYearID: fiscalYearID.String(), Summary: file.Summarize()}
+			summary := fmt.Sprintf("✅ Successfully downloaded SIE file\n\nFiscal year ID: %s\nFile size: %d bytes\n%s",
+				fiscalYearID, len(data), formatSIESummary(output.Summary))
+			if args.SummaryOnly {
+				return structuredResult(summary, output), nil
+			}
+
+			output.File = &FileDownload{
+				FileName:    fiscalYearID.String() + ".se",
+				ContentType: sieMIMEType,
+				Size:        len(data),
+				URI:         fmt.Sprintf("bokio://companies/%s/sie/%s.se", req.CompanyID, fiscalYearID),
+			}
+			result := structuredResult(summary, output)
+			result.Content = append(result.Content, &mcp.EmbeddedResource{
+				Resource: &mcp.ResourceContents{
+					URI:      output.File.URI,
+					MIMEType: sieMIMEType,
+					Blob:     data,
+				},
+			})
+			return result, nil
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("fiscal_year_id",
+				mcp.Description("Fiscal year UUID (defaults to the current open fiscal year)"),
+			),
+			mcp.Property("summary_only",
+				mcp.Description("Return only the parsed summary without the raw file"),
+			),
+		),
+	)
+
+	addTools(server, client, downloadSIETool)
+	return nil
+}
@@ -0,0 +1,249 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/klowdo/bokio-mcp/bokio/reports"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// IncomeStatementParams defines parameters for the income statement tool.
+// The period defaults to the fiscal year.
+type IncomeStatementParams struct {
+	CompanyID    string `json:"company_id"`
+	FiscalYearID string `json:"fiscal_year_id,omitempty"`
+	From         string `json:"from,omitempty"`
+	To           string `json:"to,omitempty"`
+	// SkipComparison leaves out the previous fiscal year
+	SkipComparison bool `json:"skip_comparison,omitempty"`
+	// Format is "json" (default) or "csv"
+	Format string `json:"format,omitempty"`
+}
+
+// BalanceSheetParams defines parameters for the balance sheet tool. The
+// date defaults to the end of the fiscal year.
+type BalanceSheetParams struct {
+	CompanyID    string `json:"company_id"`
+	FiscalYearID string `json:"fiscal_year_id,omitempty"`
+	Date         string `json:"date,omitempty"`
+	// SkipComparison leaves out the previous fiscal year
+	SkipComparison bool `json:"skip_comparison,omitempty"`
+	// Format is "json" (default) or "csv"
+	Format string `json:"format,omitempty"`
+}
+
+// StatementResult defines the result for the income statement and balance
+// sheet tools
+type StatementResult = ToolResult[Report[reports.Statement]]
+
+// comparisonLedger loads the ledger of the previous fiscal year for the
+// period matching period, reporting progress after the current year's two
+// steps. It returns a nil ledger and a note for the summary
+// when the company has no previous fiscal year.
+func comparisonLedger(ctx context.Context, client *bokio.AuthClient, req *toolRequest, year *company.FiscalYear, period reports.Period) (*reports.Ledger, reports.Period, string, error) {
+	previousYear, err := client.PreviousFiscalYear(ctx, req.CompanyID, year)
+	if errors.Is(err, bokio.ErrFiscalYearNotFound) {
+		return nil, reports.Period{}, "No previous fiscal year to compare with", nil
+	}
+	if err != nil {
+		return nil, reports.Period{}, "", fmt.Errorf("failed to find the previous fiscal year: %w", err)
+	}
+
+	previous := reports.PreviousPeriod(period,
+		reports.Period{From: year.StartDate.Time, To: year.EndDate.Time},
+		reports.Period{From: previousYear.StartDate.Time, To: previousYear.EndDate.Time})
+	ledger, err := loadLedger(ctx, client, req, previousYear, previous, 2, 4)
+	if err != nil {
+		return nil, previous, "", fmt.Errorf("failed to load the previous fiscal year: %w", err)
+	}
+	return ledger, previous, "Compared with " + previous.String(), nil
+}
+
+// statementSteps is the number of progress steps of a statement, which
+// loads the previous fiscal year as well unless the comparison is skipped
+func statementSteps(skipComparison bool) int {
+	if skipComparison {
+		return 2
+	}
+	return 4
+}
+
+// statementNames returns the account names of ledger, completed with those
+// of the previous year's ledger for accounts only used in that year
+func statementNames(ledger, previous *reports.Ledger) map[int32]string {
+	if previous == nil {
+		return ledger.Names
+	}
+	names := maps.Clone(previous.Names)
+	if names == nil {
+		names = make(map[int32]string, len(ledger.Names))
+	}
+	maps.Copy(names, ledger.Names)
+	return names
+}
+
+// formatStatement renders a statement as text, one line per row with the
+// comparison amount when there is one
+func formatStatement(statement *reports.Statement) string {
+	var b strings.Builder
+	b.WriteString("Line | Amount")
+	if statement.PreviousTo != "" {
+		b.WriteString(" | Previous")
+	}
+	for _, line := range statement.Lines {
+		switch line.Kind {
+		case reports.LineHeading:
+			fmt.Fprintf(&b, "\n%s", strings.ToUpper(line.Label))
+			continue
+		case reports.LineAccounts:
+			if line.Amount == 0 && (line.Previous == nil || *line.Previous == 0) {
+				continue
+			}
+			fmt.Fprintf(&b, "\n  %s | %.2f", line.Label, line.Amount)
+		default:
+			fmt.Fprintf(&b, "\n%s | %.2f", line.Label, line.Amount)
+		}
+		if line.Previous != nil {
+			fmt.Fprintf(&b, " | %.2f", *line.Previous)
+		}
+	}
+	return b.String()
+}
+
+// statementInput lists the arguments shared by the statement tools; period
+// holds the properties selecting the period
+func statementInput(period ...mcp.SchemaOption) mcp.ToolOption {
+	properties := []mcp.SchemaOption{
+		mcp.Property("company_id",
+			mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+		),
+		mcp.Property("fiscal_year_id",
+			mcp.Description("Fiscal year UUID (defaults to the year containing the period, or the current fiscal year)"),
+		),
+	}
+	properties = append(properties, period...)
+	properties = append(properties,
+		mcp.Property("skip_comparison",
+			mcp.Description("Leave out the comparison with the previous fiscal year (default false)"),
+		),
+		mcp.Property("format",
+			mcp.Description("Output format: json (default) or csv, which adds the report as an embedded CSV file"),
+			mcp.Enum("json", "csv"),
+		),
+	)
+	return mcp.Input(properties...)
+}
+
+// statementTools builds the income statement and balance sheet tools, which
+// RegisterReportTools registers with the other reports
+func statementTools(client *bokio.AuthClient) []*mcp.ServerTool {
+	// Tool to compute an income statement
+	incomeStatementTool := newTool(client, toolSpec[IncomeStatementParams, Report[reports.Statement]]{
+		Name:        "bokio_report_income_statement",
+		Description: "Income statement (resultaträkning) in the K2/K3 layout computed from journal entries: BAS classes 3–8 grouped into nettoomsättning, rörelsekostnader, rörelseresultat, finansiella poster, resultat efter finansiella poster and årets resultat, compared with the same period of the previous fiscal year.",
+		Handler: func(ctx context.Context, req *toolRequest, args IncomeStatementParams) (*mcp.CallToolResultFor[StatementResult], error) {
+			if err := validateReportFormat(args.Format); err != nil {
+				return nil, err
+			}
+			year, period, err := reportPeriod(ctx, client, req.CompanyID, args.FiscalYearID, args.From, args.To)
+			if err != nil {
+				return nil, err
+			}
+			ledger, err := loadLedger(ctx, client, req, year, period, 0, statementSteps(args.SkipComparison))
+			if err != nil {
+				return nil, err
+			}
+
+			var previous map[int32]float64
+			var previousLedger *reports.Ledger
+			var previousPeriod reports.Period
+			note := "Comparison skipped"
+			if !args.SkipComparison {
+				previousLedger, previousPeriod, note, err = comparisonLedger(ctx, client, req, year, period)
+				if err != nil {
+					return nil, err
+				}
+				if previousLedger != nil {
+					previous = previousLedger.Movements(previousPeriod)
+				}
+			}
+
+			report := reports.IncomeStatement(ledger.Movements(period), previous, statementNames(ledger, previousLedger))
+			report.From, report.To = period.From.Format(bokio.DateLayout), period.To.Format(bokio.DateLayout)
+			if previous != nil {
+				report.PreviousFrom, report.PreviousTo = previousPeriod.From.Format(bokio.DateLayout), previousPeriod.To.Format(bokio.DateLayout)
+			}
+
+			summary := fmt.Sprintf("✅ Income statement %s\n\nCompany: %s\n%s\n\n%s", period, req.CompanyID, note, formatStatement(report))
+			return reportResult(req, summary, fmt.Sprintf("income-statement-%s-%s", report.From, report.To), year, report,
+				func(buf *bytes.Buffer) error { return report.WriteCSV(buf) }, args.Format)
+		},
+	}, statementInput(
+		mcp.Property("from",
+			mcp.Description("First day of the period in YYYY-MM-DD format (defaults to the start of the fiscal year)"),
+		),
+		mcp.Property("to",
+			mcp.Description("Last day of the period in YYYY-MM-DD format (defaults to the end of the fiscal year)"),
+		),
+	))
+
+	// Tool to compute a balance sheet
+	balanceSheetTool := newTool(client, toolSpec[BalanceSheetParams, Report[reports.Statement]]{
+		Name:        "bokio_report_balance_sheet",
+		Description: "Balance sheet (balansräkning) in the K2/K3 layout computed from journal entries: BAS classes 1–2 grouped into anläggningstillgångar, omsättningstillgångar, eget kapital, obeskattade reserver, avsättningar and skulder at a date, compared with the same date of the previous fiscal year. Årets resultat is included until it is booked to equity.",
+		Handler: func(ctx context.Context, req *toolRequest, args BalanceSheetParams) (*mcp.CallToolResultFor[StatementResult], error) {
+			if err := validateReportFormat(args.Format); err != nil {
+				return nil, err
+			}
+			year, period, err := reportPeriod(ctx, client, req.CompanyID, args.FiscalYearID, "", args.Date)
+			if err != nil {
+				return nil, err
+			}
+			ledger, err := loadLedger(ctx, client, req, year, period, 0, statementSteps(args.SkipComparison))
+			if err != nil {
+				return nil, err
+			}
+
+			var previous map[int32]float64
+			var previousLedger *reports.Ledger
+			var previousPeriod reports.Period
+			note := "Comparison skipped"
+			if !args.SkipComparison {
+				previousLedger, previousPeriod, note, err = comparisonLedger(ctx, client, req, year, period)
+				if err != nil {
+					return nil, err
+				}
+				if previousLedger != nil {
+					previous = previousLedger.Balances(previousPeriod.To)
+				}
+			}
+
+			report := reports.BalanceSheet(ledger.Balances(period.To), previous, statementNames(ledger, previousLedger))
+			report.To = period.To.Format(bokio.DateLayout)
+			if previous != nil {
+				report.PreviousTo = previousPeriod.To.Format(bokio.DateLayout)
+			}
+
+			summary := fmt.Sprintf("✅ Balance sheet %s\n\nCompany: %s\n%s", report.To, req.CompanyID, note)
+			if difference := report.Difference(); difference != 0 {
+				summary += fmt.Sprintf("\n⚠️ Assets differ from equity and liabilities by %.2f", difference)
+			}
+			summary += "\n\n" + formatStatement(report)
+			return reportResult(req, summary, "balance-sheet-"+report.To, year, report,
+				func(buf *bytes.Buffer) error { return report.WriteCSV(buf) }, args.Format)
+		},
+	}, statementInput(
+		mcp.Property("date",
+			mcp.Description("Balance sheet date in YYYY-MM-DD format (defaults to the end of the fiscal year)"),
+		),
+	))
+
+	return []*mcp.ServerTool{incomeStatementTool, balanceSheetTool}
+}
@@ -4,33 +4,30 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"mime/multipart"
 	"net/http"
-	"os"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/klowdo/bokio-mcp/bokio"
 	"github.com/klowdo/bokio-mcp/bokio/generated/company"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 // UploadListParams defines parameters for listing uploads
 type UploadListParams struct {
-	CompanyID string `json:"company_id"`
-	Page      *int32 `json:"page,omitempty"`
-	PageSize  *int32 `json:"page_size,omitempty"`
+	CompanyID string      `json:"company_id"`
+	Page      *int32      `json:"page,omitempty"`
+	PageSize  *int32      `json:"page_size,omitempty"`
+	All       bool        `json:"all,omitempty"`
+	MaxItems  *int        `json:"max_items,omitempty"`
+	Filter    *FilterArgs `json:"filter,omitempty"`
 }
 
 // UploadListResult defines the result for listing uploads
-type UploadListResult struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-}
+type UploadListResult = ToolResult[Page[company.Upload]]
 
 // UploadCreateParams defines parameters for creating an upload
 type UploadCreateParams struct {
@@ -43,11 +40,7 @@ type UploadCreateParams struct {
 }
 
 // UploadCreateResult defines the result for creating an upload
-type UploadCreateResult struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-}
+type UploadCreateResult = ToolResult[company.Upload]
 
 // UploadGetParams defines parameters for getting an upload
 type UploadGetParams struct {
@@ -56,11 +49,7 @@ type UploadGetParams struct {
 }
 
 // UploadGetResult defines the result for getting an upload
-type UploadGetResult struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-}
+type UploadGetResult = ToolResult[company.Upload]
 
 // UploadDownloadParams defines parameters for downloading an upload
 type UploadDownloadParams struct {
@@ -69,103 +58,108 @@ type UploadDownloadParams struct {
 }
 
 // UploadDownloadResult defines the result for downloading an upload
-type UploadDownloadResult struct {
-	Success     bool   `json:"success"`
-	FileContent string `json:"file_content,omitempty"` // Base64 encoded file content
-	ContentType string `json:"content_type,omitempty"`
-	FileName    string `json:"file_name,omitempty"`
-	Error       string `json:"error,omitempty"`
+type UploadDownloadResult = ToolResult[FileDownload]
+
+// formatUpload renders an upload as a single line
+func formatUpload(upload *company.Upload) string {
+	var id, description, contentType string
+	if upload.Id != nil {
+		id = upload.Id.String()
+	}
+	if upload.Description != nil {
+		description = *upload.Description
+	}
+	if upload.ContentType != nil {
+		contentType = *upload.ContentType
+	}
+	text := fmt.Sprintf("%s: %s (%s)", id, description, contentType)
+	if upload.JournalEntryId != nil {
+		text += fmt.Sprintf(", journal entry %s", upload.JournalEntryId)
+	}
+	return text
+}
+
+// decodeFileContent decodes the base64 file_content argument of upload tools
+func decodeFileContent(content, fileName string) ([]byte, error) {
+	if content == "" {
+		return nil, errors.New("file_content is required (base64 encoded file)")
+	}
+	if fileName == "" {
+		return nil, errors.New("file_name is required")
+	}
+	data, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 file content: %w", err)
+	}
+	return data, nil
+}
+
+// newUploadForm builds the multipart body of an upload with its optional
+// description and journal entry fields
+func newUploadForm(fileName string, data []byte, description *string, journalEntryID *uuid.UUID) (*bytes.Buffer, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fileWriter, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := fileWriter.Write(data); err != nil {
+		return nil, "", fmt.Errorf("failed to write file data: %w", err)
+	}
+	if description != nil {
+		if err := writer.WriteField("description", *description); err != nil {
+			return nil, "", fmt.Errorf("failed to write description field: %w", err)
+		}
+	}
+	if journalEntryID != nil {
+		if err := writer.WriteField("journalEntryId", journalEntryID.String()); err != nil {
+			return nil, "", fmt.Errorf("failed to write journal entry ID field: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	return &buf, writer.FormDataContentType(), nil
 }
 
 // RegisterUploadTools registers upload tools using ONLY generated API clients
 func RegisterUploadTools(server *mcp.Server, client *bokio.AuthClient) error {
 	// Tool to list uploads using generated client
-	listUploadsTool := mcp.NewServerTool[UploadListParams, UploadListResult](
-		"bokio_uploads_list",
-		"List uploads for a company with optional pagination",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[UploadListParams]) (*mcp.CallToolResultFor[UploadListResult], error) {
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[UploadListResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	listUploadsTool := newTool(client, toolSpec[UploadListParams, Page[company.Upload]]{
+		Name:        "bokio_uploads_list",
+		Description: "List uploads for a company with optional pagination and filtering",
+		Handler: func(ctx context.Context, req *toolRequest, args UploadListParams) (*mcp.CallToolResultFor[UploadListResult], error) {
+			query, err := listQuery(nil, args.Filter, bokio.UploadFilterFields)
 			if err != nil {
-				return &mcp.CallToolResultFor[UploadListResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Create parameters for the generated client
-			genParams := &company.GetUploadsParams{
-				Page:     params.Arguments.Page,
-				PageSize: params.Arguments.PageSize,
+				return nil, err
 			}
 
-			// Call the generated client method
-			resp, err := client.CompanyClient.GetUploads(ctx, companyUUID, genParams)
+			page, err := listPages[company.Upload](ctx, req, listOptions{Page: args.Page, PageSize: args.PageSize, All: args.All, MaxItems: args.MaxItems}, "list uploads",
+				func(ctx context.Context, page, pageSize *int32) (*http.Response, error) {
+					return client.CompanyClient.GetUploads(ctx, req.CompanyID, &company.GetUploadsParams{
+						Page:     page,
+						PageSize: pageSize,
+						Query:    query,
+					})
+				})
 			if err != nil {
-				return &mcp.CallToolResultFor[UploadListResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to list uploads: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[UploadListResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[UploadListResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s\n\nCompany: %s\n", pageSummary("uploads", page), req.CompanyID)
+			for i := range page.Items {
+				b.WriteString("\n" + formatUpload(&page.Items[i]))
 			}
 
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[UploadListResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully retrieved uploads list\n\nCompany: %s\nStatus: %d\nResponse: %v", companyIDStr, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(b.String(), page), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("page",
 				mcp.Description("Page number (optional)"),
@@ -173,228 +167,58 @@ func RegisterUploadTools(server *mcp.Server, client *bokio.AuthClient) error {
 			mcp.Property("page_size",
 				mcp.Description("Items per page (optional)"),
 			),
+			mcp.Property("all",
+				mcp.Description("Fetch all pages instead of a single page, up to 10000 items (optional)"),
+			),
+			mcp.Property("max_items",
+				mcp.Description("Fetch pages until this many items are loaded (optional)"),
+			),
+			mcp.Property("filter",
+				mcp.Description(filterDescription+strings.Join(bokio.UploadFilterFields.Names(), ", ")),
+			),
 		),
 	)
 
 	// Tool to create upload using generated client
-	createUploadTool := mcp.NewServerTool[UploadCreateParams, UploadCreateResult](
-		"bokio_uploads_create",
-		"Upload a file to Bokio",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[UploadCreateParams]) (*mcp.CallToolResultFor[UploadCreateResult], error) {
-			// Check read-only mode
-			if client.GetConfig().ReadOnly {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Upload creation not allowed in read-only mode",
-						},
-					},
-				}, nil
-			}
-
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
+	createUploadTool := newTool(client, toolSpec[UploadCreateParams, company.Upload]{
+		Name:        "bokio_uploads_create",
+		Description: "Upload a file to Bokio",
+		Write:       true,
+		Handler: func(ctx context.Context, req *toolRequest, args UploadCreateParams) (*mcp.CallToolResultFor[UploadCreateResult], error) {
+			if args.ContentType == "" {
+				return nil, errors.New("content_type is required")
+			}
+			fileData, err := decodeFileContent(args.FileContent, args.FileName)
 			if err != nil {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Validate required fields
-			if params.Arguments.FileContent == "" {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "file_content is required (base64 encoded file)",
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			if params.Arguments.FileName == "" {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "file_name is required",
-						},
-					},
-				}, nil
-			}
-
-			if params.Arguments.ContentType == "" {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "content_type is required",
-						},
-					},
-				}, nil
-			}
-
-			// Decode base64 file content
-			fileData, err := base64.StdEncoding.DecodeString(params.Arguments.FileContent)
-			if err != nil {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid base64 file content: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Parse journal entry ID if provided
-			var journalEntryUUID *openapi_types.UUID
-			if params.Arguments.JournalEntryID != nil && *params.Arguments.JournalEntryID != "" {
-				journalUUID, err := uuid.Parse(*params.Arguments.JournalEntryID)
+			var journalEntryID *uuid.UUID
+			if args.JournalEntryID != nil && *args.JournalEntryID != "" {
+				id, err := parseID("journal_entry_id", *args.JournalEntryID)
 				if err != nil {
-					return &mcp.CallToolResultFor[UploadCreateResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: fmt.Sprintf("Invalid journal entry ID format: %v", err),
-							},
-						},
-					}, nil
+					return nil, err
 				}
-				journalEntryUUID = &journalUUID
+				journalEntryID = &id
 			}
 
-			// Create multipart form data
-			var buf bytes.Buffer
-			writer := multipart.NewWriter(&buf)
-
-			// Add file field
-			fileWriter, err := writer.CreateFormFile("file", params.Arguments.FileName)
-			if err != nil {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to create form file: %v", err),
-						},
-					},
-				}, nil
-			}
-			_, err = fileWriter.Write(fileData)
+			body, formContentType, err := newUploadForm(args.FileName, fileData, args.Description, journalEntryID)
 			if err != nil {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to write file data: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Add description field if provided
-			if params.Arguments.Description != nil {
-				err = writer.WriteField("description", *params.Arguments.Description)
-				if err != nil {
-					return &mcp.CallToolResultFor[UploadCreateResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: fmt.Sprintf("Failed to write description field: %v", err),
-							},
-						},
-					}, nil
-				}
-			}
-
-			// Add journal entry ID field if provided
-			if journalEntryUUID != nil {
-				err = writer.WriteField("journalEntryId", journalEntryUUID.String())
-				if err != nil {
-					return &mcp.CallToolResultFor[UploadCreateResult]{
-						Content: []mcp.Content{
-							&mcp.TextContent{
-								Text: fmt.Sprintf("Failed to write journal entry ID field: %v", err),
-							},
-						},
-					}, nil
-				}
-			}
-
-			err = writer.Close()
+			resp, err := client.CompanyClient.AddUploadWithBody(ctx, req.CompanyID, &company.AddUploadParams{}, formContentType, body)
+			upload, err := decodeResponse[company.Upload](resp, err, "upload file")
 			if err != nil {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to close multipart writer: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Create parameters for the generated client
-			genParams := &company.AddUploadParams{}
-
-			// Call the generated client method
-			resp, err := client.CompanyClient.AddUploadWithBody(ctx, companyUUID, genParams, writer.FormDataContentType(), &buf)
-			if err != nil {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to upload file: %v", err),
-						},
-					},
-				}, nil
-			}
-			defer resp.Body.Close()
-
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
-			}
-
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[UploadCreateResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[UploadCreateResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully uploaded file\n\nCompany: %s\nFile: %s\nStatus: %d\nResponse: %v", companyIDStr, params.Arguments.FileName, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("✅ Successfully uploaded file\n\nCompany: %s\nFile: %s\nUpload: %s", req.CompanyID, args.FileName, formatUpload(upload)), upload), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("file_content",
 				mcp.Description("Base64 encoded file content"),
@@ -418,109 +242,27 @@ func RegisterUploadTools(server *mcp.Server, client *bokio.AuthClient) error {
 	)
 
 	// Tool to get upload using generated client
-	getUploadTool := mcp.NewServerTool[UploadGetParams, UploadGetResult](
-		"bokio_uploads_get",
-		"Get upload information by ID",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[UploadGetParams]) (*mcp.CallToolResultFor[UploadGetResult], error) {
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[UploadGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
-			if err != nil {
-				return &mcp.CallToolResultFor[UploadGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Validate upload ID
-			if params.Arguments.UploadID == "" {
-				return &mcp.CallToolResultFor[UploadGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "upload_id is required",
-						},
-					},
-				}, nil
-			}
-
-			// Parse upload UUID
-			uploadUUID, err := uuid.Parse(params.Arguments.UploadID)
+	getUploadTool := newTool(client, toolSpec[UploadGetParams, company.Upload]{
+		Name:        "bokio_uploads_get",
+		Description: "Get upload information by ID",
+		Handler: func(ctx context.Context, req *toolRequest, args UploadGetParams) (*mcp.CallToolResultFor[UploadGetResult], error) {
+			uploadID, err := parseID("upload_id", args.UploadID)
 			if err != nil {
-				return &mcp.CallToolResultFor[UploadGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid upload ID format: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Call the generated client method
-			resp, err := client.CompanyClient.GetUpload(ctx, companyUUID, uploadUUID)
+			resp, err := client.CompanyClient.GetUpload(ctx, req.CompanyID, uploadID)
+			upload, err := decodeResponse[company.Upload](resp, err, "get upload")
 			if err != nil {
-				return &mcp.CallToolResultFor[UploadGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to get upload: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
-			defer resp.Body.Close()
 
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[UploadGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
-			}
-
-			// Parse response body as generic interface
-			var responseData interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&responseData); err != nil {
-				return &mcp.CallToolResultFor[UploadGetResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to decode response: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Return success with the actual API response
-			return &mcp.CallToolResultFor[UploadGetResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully retrieved upload information\n\nCompany: %s\nUpload ID: %s\nStatus: %d\nResponse: %v", companyIDStr, params.Arguments.UploadID, resp.StatusCode, responseData),
-					},
-				},
-			}, nil
+			return structuredResult(fmt.Sprintf("✅ Successfully retrieved upload information\n\nCompany: %s\nUpload: %s", req.CompanyID, formatUpload(upload)), upload), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("upload_id",
 				mcp.Description("Upload UUID"),
@@ -530,119 +272,30 @@ func RegisterUploadTools(server *mcp.Server, client *bokio.AuthClient) error {
 	)
 
 	// Tool to download upload using generated client
-	downloadUploadTool := mcp.NewServerTool[UploadDownloadParams, UploadDownloadResult](
-		"bokio_uploads_download",
-		"Download an uploaded file",
-		func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[UploadDownloadParams]) (*mcp.CallToolResultFor[UploadDownloadResult], error) {
-			// Get company ID from params or environment
-			companyIDStr := params.Arguments.CompanyID
-			if companyIDStr == "" {
-				companyIDStr = os.Getenv("BOKIO_COMPANY_ID")
-			}
-
-			if companyIDStr == "" {
-				return &mcp.CallToolResultFor[UploadDownloadResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "Company ID is required (provide in company_id parameter or BOKIO_COMPANY_ID env var)",
-						},
-					},
-				}, nil
-			}
-
-			// Parse company UUID
-			companyUUID, err := uuid.Parse(companyIDStr)
-			if err != nil {
-				return &mcp.CallToolResultFor[UploadDownloadResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid company ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Validate upload ID
-			if params.Arguments.UploadID == "" {
-				return &mcp.CallToolResultFor[UploadDownloadResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: "upload_id is required",
-						},
-					},
-				}, nil
-			}
-
-			// Parse upload UUID
-			uploadUUID, err := uuid.Parse(params.Arguments.UploadID)
-			if err != nil {
-				return &mcp.CallToolResultFor[UploadDownloadResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Invalid upload ID format: %v", err),
-						},
-					},
-				}, nil
-			}
-
-			// Call the generated client method
-			resp, err := client.CompanyClient.DownloadUpload(ctx, companyUUID, uploadUUID)
+	downloadUploadTool := newTool(client, toolSpec[UploadDownloadParams, FileDownload]{
+		Name:        "bokio_uploads_download",
+		Description: "Download an uploaded file",
+		Handler: func(ctx context.Context, req *toolRequest, args UploadDownloadParams) (*mcp.CallToolResultFor[UploadDownloadResult], error) {
+			uploadID, err := parseID("upload_id", args.UploadID)
 			if err != nil {
-				return &mcp.CallToolResultFor[UploadDownloadResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to download upload: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
-			defer resp.Body.Close()
 
-			// Handle different response codes
-			if resp.StatusCode != http.StatusOK {
-				return &mcp.CallToolResultFor[UploadDownloadResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("API returned status %d", resp.StatusCode),
-						},
-					},
-				}, nil
-			}
-
-			// Read the file content
-			fileContent, err := io.ReadAll(resp.Body)
+			resp, err := client.CompanyClient.DownloadUpload(ctx, req.CompanyID, uploadID)
+			download, data, err := readDownload(resp, err, "download upload",
+				fmt.Sprintf("upload_%s", uploadID), fmt.Sprintf("bokio://companies/%s/uploads/%s", req.CompanyID, uploadID))
 			if err != nil {
-				return &mcp.CallToolResultFor[UploadDownloadResult]{
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Failed to read file content: %v", err),
-						},
-					},
-				}, nil
+				return nil, err
 			}
 
-			// Get content type and filename from response headers
-			contentType := resp.Header.Get("Content-Type")
-			fileName := resp.Header.Get("Content-Disposition")
-			if fileName == "" {
-				fileName = fmt.Sprintf("upload_%s", params.Arguments.UploadID)
-			}
-
-			// Encode file content as base64
-			base64Content := base64.StdEncoding.EncodeToString(fileContent)
-
-			// Return success with the file data
-			return &mcp.CallToolResultFor[UploadDownloadResult]{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: fmt.Sprintf("✅ Successfully downloaded file\n\nCompany: %s\nUpload ID: %s\nContent-Type: %s\nFile Name: %s\nFile Size: %d bytes\nStatus: %d\n\nBase64 Content: %s", companyIDStr, params.Arguments.UploadID, contentType, fileName, len(fileContent), resp.StatusCode, base64Content),
-					},
-				},
-			}, nil
+			// Return the file as an embedded resource
+			return fileResult(fmt.Sprintf("✅ Successfully downloaded file\n\nCompany: %s\nUpload ID: %s\nContent-Type: %s\nFile Name: %s\nFile Size: %d bytes",
+				req.CompanyID, uploadID, download.ContentType, download.FileName, download.Size), download, data), nil
 		},
+	},
 		mcp.Input(
 			mcp.Property("company_id",
-				mcp.Description("Company UUID (or use BOKIO_COMPANY_ID env var)"),
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
 			),
 			mcp.Property("upload_id",
 				mcp.Description("Upload UUID"),
@@ -652,6 +305,6 @@ func RegisterUploadTools(server *mcp.Server, client *bokio.AuthClient) error {
 	)
 
 	// Add all tools to the server
-	server.AddTools(listUploadsTool, createUploadTool, getUploadTool, downloadUploadTool)
+	addTools(server, client, listUploadsTool, createUploadTool, getUploadTool, downloadUploadTool)
 	return nil
 }
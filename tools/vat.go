@@ -0,0 +1,232 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/klowdo/bokio-mcp/bokio/reports"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// VATReturnParams defines parameters for the VAT return tool. Either period
+// or from and to select the period; without them the last VAT period that
+// has ended is used.
+type VATReturnParams struct {
+	CompanyID string `json:"company_id"`
+	Period    string `json:"period,omitempty"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+	// Format is "json" (default) or "csv"
+	Format string `json:"format,omitempty"`
+}
+
+// VATReturnResult defines the result for the VAT return tool
+type VATReturnResult = ToolResult[Report[reports.VATReturn]]
+
+// vatPeriodPattern matches a month (2024-03), a quarter (2024-Q1) or a year
+var vatPeriodPattern = regexp.MustCompile(`^(\d{4})(?:-(\d{2})|-[Qq]([1-4]))?$`)
+
+// parseVATPeriod parses a VAT period reference: a month such as 2024-03, a
+// quarter such as 2024-Q1 or a calendar year such as 2024
+func parseVATPeriod(ref string) (reports.Period, error) {
+	match := vatPeriodPattern.FindStringSubmatch(ref)
+	if match == nil {
+		return reports.Period{}, fmt.Errorf("period must be a month (2024-03), a quarter (2024-Q1) or a year (2024), not %q", ref)
+	}
+	year, _ := strconv.Atoi(match[1])
+	switch {
+	case match[2] != "":
+		month, _ := strconv.Atoi(match[2])
+		if month < 1 || month > 12 {
+			return reports.Period{}, fmt.Errorf("period %q has no month %d", ref, month)
+		}
+		from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		return reports.Period{From: from, To: from.AddDate(0, 1, -1)}, nil
+	case match[3] != "":
+		quarter, _ := strconv.Atoi(match[3])
+		from := time.Date(year, time.Month(3*quarter-2), 1, 0, 0, 0, 0, time.UTC)
+		return reports.Period{From: from, To: from.AddDate(0, 3, -1)}, nil
+	default:
+		from := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		return reports.Period{From: from, To: from.AddDate(1, 0, -1)}, nil
+	}
+}
+
+// previousVATPeriod returns the last calendar month, or with quarterly
+// reporting the last calendar quarter, that ended before now
+func previousVATPeriod(setting company.FiscalYearVatSetting, now time.Time) reports.Period {
+	months := 1
+	if setting == company.Quarterly {
+		months = 3
+	}
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start = start.AddDate(0, -(int(now.Month())-1)%months, 0)
+	from := start.AddDate(0, -months, 0)
+	return reports.Period{From: from, To: start.AddDate(0, 0, -1)}
+}
+
+// vatReturnPeriod resolves the period of a VAT return and the fiscal year it
+// starts in. Without a period, companies reporting yearly get their previous
+// fiscal year and others the last month or quarter that has ended.
+func vatReturnPeriod(ctx context.Context, client *bokio.AuthClient, companyID uuid.UUID, args VATReturnParams, now time.Time) (*company.FiscalYear, reports.Period, error) {
+	var period reports.Period
+	switch {
+	case args.From != "" || args.To != "":
+		if args.Period != "" {
+			return nil, period, errors.New("pass either period or from and to, not both")
+		}
+		if args.From == "" || args.To == "" {
+			return nil, period, errors.New("from and to must be given together")
+		}
+		if err := validateDateParam("from", args.From); err != nil {
+			return nil, period, err
+		}
+		if err := validateDateParam("to", args.To); err != nil {
+			return nil, period, err
+		}
+		period.From, _ = time.Parse(bokio.DateLayout, args.From)
+		period.To, _ = time.Parse(bokio.DateLayout, args.To)
+		if period.To.Before(period.From) {
+			return nil, period, errors.New("to must not be before from")
+		}
+	case args.Period != "":
+		var err error
+		if period, err = parseVATPeriod(args.Period); err != nil {
+			return nil, period, err
+		}
+	default:
+		current, err := client.CurrentFiscalYear(ctx, companyID, now)
+		if err != nil {
+			return nil, period, fmt.Errorf("failed to find the fiscal year: %w", err)
+		}
+		if current.VatSetting != company.Yearly {
+			period = previousVATPeriod(current.VatSetting, now)
+			break
+		}
+		previous, err := client.PreviousFiscalYear(ctx, companyID, current)
+		if err != nil {
+			return nil, period, fmt.Errorf("failed to find the previous fiscal year: %w", err)
+		}
+		return previous, reports.Period{From: previous.StartDate.Time, To: previous.EndDate.Time}, nil
+	}
+
+	year, err := client.FiscalYearContaining(ctx, companyID, period.From)
+	if err != nil {
+		return nil, period, fmt.Errorf("failed to find the fiscal year: %w", err)
+	}
+	return year, period, nil
+}
+
+// voucherNumbers lists the distinct journal entry numbers of sources
+func voucherNumbers(sources []reports.VATSource) string {
+	var numbers []string
+	for _, source := range sources {
+		if source.Number != "" && !slices.Contains(numbers, source.Number) {
+			numbers = append(numbers, source.Number)
+		}
+	}
+	return strings.Join(numbers, ", ")
+}
+
+// formatVATReturn renders the boxes of a VAT return as text with the
+// vouchers behind each box, followed by the points to review
+func formatVATReturn(report *reports.VATReturn) string {
+	var b strings.Builder
+	b.WriteString("Box | Label | Kronor | Vouchers")
+	for _, box := range report.Boxes {
+		fmt.Fprintf(&b, "\n%s | %s | %d | %s", box.Box, box.Label, box.Kronor, voucherNumbers(box.Sources))
+	}
+	fmt.Fprintf(&b, "\n49 | %s | %d |", reports.VATPayableLabel, report.Payable)
+
+	if len(report.Settlements) > 0 {
+		fmt.Fprintf(&b, "\n\nLeft out VAT settlement entries: %s", voucherNumbers(report.Settlements))
+	}
+	if len(report.Unreported) > 0 {
+		b.WriteString("\n\n⚠️ Rows on VAT accounts that report to no box:")
+		for _, s := range report.Unreported {
+			fmt.Fprintf(&b, "\n%s %s account %d: %.2f", s.Date, s.Number, s.Account, s.Amount)
+		}
+	}
+	if check := report.InvoiceCheck; check != nil {
+		fmt.Fprintf(&b, "\n\nInvoices dated in the period: %d with VAT %.2f; output VAT booked in boxes 10–12: %.2f",
+			len(check.Invoices), check.InvoicedVAT, check.BookedVAT)
+		if check.Difference != 0 {
+			fmt.Fprintf(&b, "\n⚠️ Booked output VAT differs from the invoices by %.2f; check for unbooked invoices or sales booked without an invoice", check.Difference)
+		}
+	}
+	return b.String()
+}
+
+// vatReturnTool builds the VAT return tool, which RegisterReportTools
+// registers with the other reports
+func vatReturnTool(client *bokio.AuthClient) *mcp.ServerTool {
+	// Tool to compute the momsdeklaration of a period
+	return newTool(client, toolSpec[VATReturnParams, Report[reports.VATReturn]]{
+		Name:        "bokio_report_vat_return",
+		Description: "Compute the Skatteverket VAT return (momsdeklaration) of a period from journal entries: every box from 05 to 62 with the vouchers behind it, and box 49 with the VAT to pay or get back. Output VAT is read from accounts 2610–2639, input VAT from 2640–2649, and EU and export sales and purchases from their BAS accounts. VAT settlement entries on 2650 are left out and the output VAT is compared with the invoices of the period. For review before filing; it does not file anything.",
+		Handler: func(ctx context.Context, req *toolRequest, args VATReturnParams) (*mcp.CallToolResultFor[VATReturnResult], error) {
+			if err := validateReportFormat(args.Format); err != nil {
+				return nil, err
+			}
+			year, period, err := vatReturnPeriod(ctx, client, req.CompanyID, args, time.Now())
+			if err != nil {
+				return nil, err
+			}
+
+			req.notifyProgress(ctx, 0, 2, "Loading journal entries")
+			entries, err := client.JournalEntries(ctx, req.CompanyID, period.From, period.To)
+			if err != nil {
+				return nil, err
+			}
+			report := (&reports.Ledger{Entries: entries}).VATReturn(period)
+
+			note := fmt.Sprintf("VAT reporting: %s", year.VatSetting)
+			if year.AccountingMethod == company.Cash {
+				note += "\nCash method: VAT is booked when invoices are paid, so invoices are not compared"
+			} else {
+				req.notifyProgress(ctx, 1, 2, "Loading invoices")
+				invoices, err := client.Invoices(ctx, req.CompanyID, bokio.And(
+					bokio.Where("invoiceDate", bokio.OpGreaterOrEqual, period.From),
+					bokio.Where("invoiceDate", bokio.OpLessOrEqual, period.To),
+				))
+				if err != nil {
+					return nil, err
+				}
+				report.CompareInvoices(invoices)
+			}
+
+			summary := fmt.Sprintf("✅ VAT return (momsdeklaration) %s\n\nCompany: %s\n%s\n\n%s", period, req.CompanyID, note, formatVATReturn(report))
+			return reportResult(req, summary, fmt.Sprintf("vat-return-%s-%s", report.From, report.To), year, report,
+				func(buf *bytes.Buffer) error { return report.WriteCSV(buf) }, args.Format)
+		},
+	},
+		mcp.Input(
+			mcp.Property("company_id",
+				mcp.Description("Company UUID or alias (defaults to the selected company or BOKIO_COMPANY_ID env var)"),
+			),
+			mcp.Property("period",
+				mcp.Description("VAT period: a month (2024-03), a quarter (2024-Q1) or a year (2024). Defaults to the last period that has ended according to the fiscal year's VAT setting"),
+			),
+			mcp.Property("from",
+				mcp.Description("First day of a custom period in YYYY-MM-DD format, instead of period"),
+			),
+			mcp.Property("to",
+				mcp.Description("Last day of a custom period in YYYY-MM-DD format, instead of period"),
+			),
+			mcp.Property("format",
+				mcp.Description("Output format: json (default) or csv, which adds the boxes and their vouchers as an embedded CSV file"),
+				mcp.Enum("json", "csv"),
+			),
+		),
+	)
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klowdo/bokio-mcp/bokio/generated/company"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVATPeriod(t *testing.T) {
+	tests := map[string]string{
+		"2024-02": "2024-02-01 – 2024-02-29",
+		"2024-Q1": "2024-01-01 – 2024-03-31",
+		"2024-q4": "2024-10-01 – 2024-12-31",
+		"2024":    "2024-01-01 – 2024-12-31",
+	}
+	for ref, want := range tests {
+		period, err := parseVATPeriod(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, period.String(), ref)
+	}
+
+	for _, ref := range []string{"2024-13", "2024-Q5", "Q1 2024", "24-01", ""} {
+		_, err := parseVATPeriod(ref)
+		assert.Error(t, err, ref)
+	}
+}
+
+func TestPreviousVATPeriod(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 12, 0, 0, 0, time.UTC)
+	}
+
+	assert.Equal(t, "2025-02-01 – 2025-02-28", previousVATPeriod(company.Monthly, date(3, 12)).String())
+	assert.Equal(t, "2024-12-01 – 2024-12-31", previousVATPeriod(company.Monthly, date(1, 1)).String())
+	assert.Equal(t, "2024-10-01 – 2024-12-31", previousVATPeriod(company.Quarterly, date(3, 31)).String())
+	assert.Equal(t, "2025-01-01 – 2025-03-31", previousVATPeriod(company.Quarterly, date(4, 1)).String())
+	assert.Equal(t, "2025-07-01 – 2025-09-30", previousVATPeriod(company.Quarterly, date(12, 15)).String())
+}
+
+func TestVATReturnPeriodValidation(t *testing.T) {
+	client := newTestClient(t, false)
+	companyID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	now := time.Now()
+
+	_, _, err := vatReturnPeriod(context.Background(), client, companyID, VATReturnParams{Period: "2024-Q1", From: "2024-01-01", To: "2024-03-31"}, now)
+	assert.EqualError(t, err, "pass either period or from and to, not both")
+	_, _, err = vatReturnPeriod(context.Background(), client, companyID, VATReturnParams{From: "2024-01-01"}, now)
+	assert.EqualError(t, err, "from and to must be given together")
+	_, _, err = vatReturnPeriod(context.Background(), client, companyID, VATReturnParams{From: "2024-03-01", To: "2024-01-31"}, now)
+	assert.EqualError(t, err, "to must not be before from")
+	_, _, err = vatReturnPeriod(context.Background(), client, companyID, VATReturnParams{Period: "March"}, now)
+	assert.ErrorContains(t, err, "period must be a month")
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/klowdo/bokio-mcp/httpserver"
+)
+
+// serveOptions holds the command line: the config file and profile, settings
+// overriding them, and the MCP transport
+type serveOptions struct {
+	// ConfigFile and Profile select the configuration, see bokio.ConfigOptions
+	ConfigFile string
+	Profile    string
+	// ConfigFlags are the config settings given on the command line, by name
+	ConfigFlags map[string]string
+
+	// Transport is "stdio" or "http"
+	Transport string
+	Addr      string
+
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// PrincipalsFile lists the remote clients allowed to use the http transport
+	PrincipalsFile string
+	// JWTSecret verifies HS256 tokens; it is only read from the environment
+	// so it does not show up in process listings
+	JWTSecret    string
+	JWTPublicKey string
+	JWTIssuer    string
+	JWTAudience  string
+}
+
+// configFlags maps command line flags to the config settings they override
+var configFlags = map[string]string{
+	"base-url":    "base_url",
+	"company":     "company",
+	"read-only":   "read_only",
+	"tool-groups": "tool_groups",
+}
+
+// parseFlags parses the command line into serve options. Transport flags
+// default to environment variables so container deployments need no
+// arguments; config flags only count when given.
+func parseFlags(fs *flag.FlagSet, args []string) (*serveOptions, error) {
+	opts := &serveOptions{JWTSecret: os.Getenv("BOKIO_MCP_JWT_SECRET")}
+	fs.StringVar(&opts.ConfigFile, "config", "", "config file (default $BOKIO_CONFIG or ~/.config/bokio-mcp/config.yaml)")
+	fs.StringVar(&opts.Profile, "profile", "", "config file profile (default $BOKIO_PROFILE or the file's default_profile)")
+	fs.String("base-url", "", "Bokio API base URL")
+	fs.String("company", "", "default company ID or alias")
+	fs.Bool("read-only", false, "refuse tools that change data")
+	fs.String("tool-groups", "", "comma separated tool groups to enable (default all)")
+	fs.StringVar(&opts.Transport, "transport", getEnvWithDefault("BOKIO_MCP_TRANSPORT", "stdio"), "MCP transport: stdio or http")
+	fs.StringVar(&opts.Addr, "addr", getEnvWithDefault("BOKIO_MCP_ADDR", httpserver.DefaultAddr), "listen address of the http transport")
+	fs.StringVar(&opts.TLSCertFile, "tls-cert", os.Getenv("BOKIO_MCP_TLS_CERT"), "TLS certificate file; serves HTTPS together with -tls-key")
+	fs.StringVar(&opts.TLSKeyFile, "tls-key", os.Getenv("BOKIO_MCP_TLS_KEY"), "TLS private key file")
+	fs.StringVar(&opts.PrincipalsFile, "principals", os.Getenv("BOKIO_MCP_PRINCIPALS_FILE"), "JSON file mapping API keys and JWT subjects to companies and roles")
+	fs.StringVar(&opts.JWTPublicKey, "jwt-public-key", os.Getenv("BOKIO_MCP_JWT_PUBLIC_KEY"), "PEM file with the RSA key verifying RS256 client tokens")
+	fs.StringVar(&opts.JWTIssuer, "jwt-issuer", os.Getenv("BOKIO_MCP_JWT_ISSUER"), "required iss claim of client tokens")
+	fs.StringVar(&opts.JWTAudience, "jwt-audience", os.Getenv("BOKIO_MCP_JWT_AUDIENCE"), "required aud claim of client tokens")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	opts.ConfigFlags = make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		if setting, ok := configFlags[f.Name]; ok {
+			opts.ConfigFlags[setting] = f.Value.String()
+		}
+	})
+	return opts, nil
+}
+
+// authenticator builds the client authentication of the http transport
+func (o *serveOptions) authenticator() (*httpserver.Authenticator, error) {
+	auth := &httpserver.Authenticator{}
+
+	if o.PrincipalsFile != "" {
+		principals, err := httpserver.LoadPrincipalRegistry(o.PrincipalsFile)
+		if err != nil {
+			return nil, err
+		}
+		auth.Principals = principals
+	}
+
+	if o.JWTSecret != "" || o.JWTPublicKey != "" {
+		verifier := &httpserver.JWTVerifier{
+			Secret:   []byte(o.JWTSecret),
+			Issuer:   o.JWTIssuer,
+			Audience: o.JWTAudience,
+		}
+		if o.JWTPublicKey != "" {
+			key, err := httpserver.LoadRSAPublicKey(o.JWTPublicKey)
+			if err != nil {
+				return nil, err
+			}
+			verifier.PublicKey = key
+		}
+		auth.JWT = verifier
+	}
+
+	return auth, nil
+}
+
+// getEnvWithDefault returns an environment variable or fallback when it is unset
+func getEnvWithDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// String describes the transport for logs
+func (o *serveOptions) String() string {
+	if o.Transport != "http" {
+		return o.Transport
+	}
+	scheme := "http"
+	if o.TLSCertFile != "" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s on %s", scheme, o.Addr)
+}